@@ -0,0 +1,58 @@
+package authops
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrToken     = errors.New("invalid token")
+	ErrNoToken   = errors.New("no token found")
+	ErrTokenName = errors.New("invalid token name")
+	ErrSession   = errors.New("invalid session")
+)
+
+// secretPrefix starts every token secret, so that leaked secrets are easy
+// to recognise
+const secretPrefix = "ebz_"
+
+// Token is an API token. Only the SHA-256 hash of its secret is stored; the
+// secret itself is shown once, when the token is created.
+type Token struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+// newSecret returns a random token secret
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashSecret returns the hash a token secret is stored as
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// newID returns a random ID of a token or session of n bytes
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// checkName checks that a token name is not blank
+func checkName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrTokenName
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package authops stores hashed API tokens and the browser sessions signed in with them.
+package authops
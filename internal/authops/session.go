@@ -0,0 +1,71 @@
+package authops
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTTL is how long a browser session lasts unless NewSessions
+// is given another
+const DefaultSessionTTL = 12 * time.Hour
+
+// session is a browser session signed in with a token
+type session struct {
+	tokenID string
+	expires time.Time
+}
+
+// Sessions holds the browser sessions signed in with tokens, in memory, so
+// sessions end when the server stops. It is safe for concurrent use.
+type Sessions struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]session
+	now      func() time.Time
+}
+
+// NewSessions returns an empty set of sessions lasting ttl
+func NewSessions(ttl time.Duration) *Sessions {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Sessions{ttl: ttl, sessions: map[string]session{}, now: time.Now}
+}
+
+// Create starts a session signed in with the token, and returns the ID of
+// the session and when it expires
+func (s *Sessions) Create(tokenID string) (string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, sess := range s.sessions {
+		if !now.Before(sess.expires) {
+			delete(s.sessions, id)
+		}
+	}
+	id := newID(32)
+	expires := now.Add(s.ttl)
+	s.sessions[id] = session{tokenID: tokenID, expires: expires}
+	return id, expires
+}
+
+// Lookup returns the ID of the token the session is signed in with, or
+// ErrSession if the session is unknown or expired
+func (s *Sessions) Lookup(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok || !s.now().Before(sess.expires) {
+		delete(s.sessions, id)
+		return "", fmt.Errorf("%w: unknown or expired", ErrSession)
+	}
+	return sess.tokenID, nil
+}
+
+// Delete ends the session
+func (s *Sessions) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
@@ -0,0 +1,48 @@
+package authops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	now := time.Date(2026, time.February, 20, 12, 0, 0, 0, time.UTC)
+	s := NewSessions(time.Hour)
+	s.now = func() time.Time { return now }
+
+	id, expires := s.Create("token")
+	assert.Equal(t, now.Add(time.Hour), expires)
+	other, _ := s.Create("token")
+	assert.NotEqual(t, id, other)
+
+	testcases := []struct {
+		name    string
+		id      string
+		elapsed time.Duration
+		want    string
+		wantErr error
+	}{
+		{name: "signed in", id: id, want: "token"},
+		{name: "unknown", id: "unknown", wantErr: ErrSession},
+		{name: "expired", id: id, elapsed: time.Hour, wantErr: ErrSession},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.elapsed)
+			got, err := s.Lookup(tc.id)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	s.Delete(other)
+	if _, err := s.Lookup(other); !errors.Is(err, ErrSession) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrSession, err)
+	}
+}
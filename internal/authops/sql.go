@@ -0,0 +1,123 @@
+package authops
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+)
+
+const (
+	tblName = "api_tokens"
+
+	timeLayout = time.RFC3339
+)
+
+var (
+	createTableSQL = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+        id TEXT PRIMARY KEY, name TEXT NOT NULL, hash TEXT NOT NULL UNIQUE, created_at TEXT NOT NULL)`, tblName)
+
+	// CreateTableFn creates the table of tokens at the latest version
+	CreateTableFn sqlops.TblCreator = func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, createTableSQL)
+		return err
+	}
+)
+
+// Schema lists the migrations of the token table in order of version
+var Schema = sqlops.Schema{
+	Name: tblName,
+	Migrations: []sqlops.Migration{
+		{Version: 1, Description: "create api_tokens table", Up: CreateTableFn},
+	},
+}
+
+// storedToken is a token with the hash of its secret
+type storedToken struct {
+	Token
+	hash string
+}
+
+var (
+	insertTokenSQL = fmt.Sprintf(`INSERT INTO %s (id, name, hash, created_at) VALUES ($1, $2, $3, $4)`, tblName)
+
+	writeTokenRowFn sqlops.RowWriter[storedToken] = func(ctx context.Context, stmt *sql.Stmt, t storedToken) error {
+		if _, err := stmt.ExecContext(ctx, t.ID, t.Name, t.hash, t.Created.Format(timeLayout)); err != nil {
+			return fmt.Errorf("%w:%w", sqlops.ErrExecuteWriter, err)
+		}
+		return nil
+	}
+)
+
+// CreateToken stores a new token with the name, and returns it with its
+// secret
+func CreateToken(ctx context.Context, db sqlops.DB, name string) (Token, string, error) {
+	if err := checkName(name); err != nil {
+		return Token{}, "", err
+	}
+	secret := newSecret()
+	t := Token{ID: newID(4), Name: name, Created: time.Now().UTC().Truncate(time.Second)}
+	if err := sqlops.Writer(ctx, db, insertTokenSQL, []storedToken{{Token: t, hash: hashSecret(secret)}}, writeTokenRowFn); err != nil {
+		return Token{}, "", err
+	}
+	return t, secret, nil
+}
+
+var deleteTokenSQL = fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, tblName)
+
+// RevokeToken removes the token with the ID, or returns ErrNoToken if none
+// is stored
+func RevokeToken(ctx context.Context, db sqlops.DB, id string) error {
+	n, err := sqlops.Exec(ctx, db, deleteTokenSQL, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrNoToken, id)
+	}
+	return nil
+}
+
+var (
+	selectTokensSQL      = fmt.Sprintf(`SELECT id, name, created_at FROM %s ORDER BY created_at, id`, tblName)
+	selectTokenSQL       = fmt.Sprintf(`SELECT id, name, created_at FROM %s WHERE id = $1`, tblName)
+	selectTokenByHashSQL = fmt.Sprintf(`SELECT id, name, created_at FROM %s WHERE hash = $1`, tblName)
+
+	scanToken sqlops.QueryScanner[Token] = func(rows *sql.Rows) (Token, error) {
+		var t Token
+		var created string
+		if err := rows.Scan(&t.ID, &t.Name, &created); err != nil {
+			return Token{}, err
+		}
+		var err error
+		t.Created, err = time.Parse(timeLayout, created)
+		return t, err
+	}
+)
+
+// ListTokens returns every stored token in order of creation
+func ListTokens(ctx context.Context, db sqlops.DB) ([]Token, error) {
+	return sqlops.Query(ctx, db, scanToken, selectTokensSQL)
+}
+
+// GetToken returns the token with the ID, or ErrNoToken if none is stored
+func GetToken(ctx context.Context, db sqlops.DB, id string) (Token, error) {
+	t, err := sqlops.QueryOne(ctx, db, scanToken, selectTokenSQL, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Token{}, fmt.Errorf("%w: %s", ErrNoToken, id)
+	}
+	return t, err
+}
+
+// VerifyToken returns the token with the secret, or ErrToken if no stored
+// token has the secret
+func VerifyToken(ctx context.Context, db sqlops.DB, secret string) (Token, error) {
+	t, err := sqlops.QueryOne(ctx, db, scanToken, selectTokenByHashSQL, hashSecret(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Token{}, ErrToken
+	}
+	return t, err
+}
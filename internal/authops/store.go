@@ -0,0 +1,132 @@
+package authops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+)
+
+// TokenStore stores API tokens. SQLiteStore stores tokens in a database and
+// MemStore in memory.
+type TokenStore interface {
+	// CreateToken stores a new token with the name, and returns it with
+	// its secret
+	CreateToken(ctx context.Context, name string) (Token, string, error)
+	// RevokeToken removes the token with the ID, or returns ErrNoToken if
+	// none is stored
+	RevokeToken(ctx context.Context, id string) error
+	// ListTokens returns every stored token in order of creation
+	ListTokens(ctx context.Context) ([]Token, error)
+	// GetToken returns the token with the ID, or ErrNoToken if none is
+	// stored
+	GetToken(ctx context.Context, id string) (Token, error)
+	// VerifyToken returns the token with the secret, or ErrToken if no
+	// stored token has the secret
+	VerifyToken(ctx context.Context, secret string) (Token, error)
+}
+
+// SQLiteStore stores tokens in the table of Schema in a SQLite database
+type SQLiteStore struct {
+	db sqlops.DB
+}
+
+// NewSQLiteStore returns a store of the tokens in the database
+func NewSQLiteStore(db sqlops.DB) SQLiteStore {
+	return SQLiteStore{db: db}
+}
+
+func (s SQLiteStore) CreateToken(ctx context.Context, name string) (Token, string, error) {
+	return CreateToken(ctx, s.db, name)
+}
+
+func (s SQLiteStore) RevokeToken(ctx context.Context, id string) error {
+	return RevokeToken(ctx, s.db, id)
+}
+
+func (s SQLiteStore) ListTokens(ctx context.Context) ([]Token, error) {
+	return ListTokens(ctx, s.db)
+}
+
+func (s SQLiteStore) GetToken(ctx context.Context, id string) (Token, error) {
+	return GetToken(ctx, s.db, id)
+}
+
+func (s SQLiteStore) VerifyToken(ctx context.Context, secret string) (Token, error) {
+	return VerifyToken(ctx, s.db, secret)
+}
+
+// MemStore stores tokens in memory
+type MemStore struct {
+	mu     sync.RWMutex
+	tokens map[string]storedToken
+}
+
+// NewMemStore returns an empty store of tokens in memory
+func NewMemStore() *MemStore {
+	return &MemStore{tokens: map[string]storedToken{}}
+}
+
+func (m *MemStore) CreateToken(ctx context.Context, name string) (Token, string, error) {
+	if err := checkName(name); err != nil {
+		return Token{}, "", err
+	}
+	secret := newSecret()
+	t := Token{ID: newID(4), Name: name, Created: time.Now().UTC().Truncate(time.Second)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[t.ID] = storedToken{Token: t, hash: hashSecret(secret)}
+	return t, secret, nil
+}
+
+func (m *MemStore) RevokeToken(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tokens[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNoToken, id)
+	}
+	delete(m.tokens, id)
+	return nil
+}
+
+func (m *MemStore) ListTokens(ctx context.Context) ([]Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tokens := []Token{}
+	for _, t := range m.tokens {
+		tokens = append(tokens, t.Token)
+	}
+	slices.SortFunc(tokens, func(a, b Token) int {
+		if c := a.Created.Compare(b.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return tokens, nil
+}
+
+func (m *MemStore) GetToken(ctx context.Context, id string) (Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.tokens[id]
+	if !ok {
+		return Token{}, fmt.Errorf("%w: %s", ErrNoToken, id)
+	}
+	return t.Token, nil
+}
+
+func (m *MemStore) VerifyToken(ctx context.Context, secret string) (Token, error) {
+	hash := hashSecret(secret)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tokens {
+		if t.hash == hash {
+			return t.Token, nil
+		}
+	}
+	return Token{}, ErrToken
+}
@@ -0,0 +1,94 @@
+package authops_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenStore(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, authops.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name  string
+		store authops.TokenStore
+	}{
+		{name: "sqlite", store: authops.NewSQLiteStore(db)},
+		{name: "memory", store: authops.NewMemStore()},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := tc.store.CreateToken(ctx, " "); !errors.Is(err, authops.ErrTokenName) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", authops.ErrTokenName, err)
+			}
+
+			ci, ciSecret, err := tc.store.CreateToken(ctx, "ci")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(ciSecret, "ebz_"))
+			laptop, laptopSecret, err := tc.store.CreateToken(ctx, "laptop")
+			assert.NoError(t, err)
+			assert.NotEqual(t, ciSecret, laptopSecret)
+
+			tokens, err := tc.store.ListTokens(ctx)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []authops.Token{ci, laptop}, tokens)
+
+			got, err := tc.store.VerifyToken(ctx, ciSecret)
+			assert.NoError(t, err)
+			assert.Equal(t, ci, got)
+			if _, err := tc.store.VerifyToken(ctx, "ebz_wrong"); !errors.Is(err, authops.ErrToken) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", authops.ErrToken, err)
+			}
+
+			got, err = tc.store.GetToken(ctx, laptop.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, laptop, got)
+
+			assert.NoError(t, tc.store.RevokeToken(ctx, ci.ID))
+			if err := tc.store.RevokeToken(ctx, ci.ID); !errors.Is(err, authops.ErrNoToken) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", authops.ErrNoToken, err)
+			}
+			if _, err := tc.store.VerifyToken(ctx, ciSecret); !errors.Is(err, authops.ErrToken) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", authops.ErrToken, err)
+			}
+			if _, err := tc.store.GetToken(ctx, ci.ID); !errors.Is(err, authops.ErrNoToken) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", authops.ErrNoToken, err)
+			}
+			assert.NoError(t, tc.store.RevokeToken(ctx, laptop.ID))
+		})
+	}
+}
+
+func TestTokenHashed(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, authops.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	token, secret, err := authops.CreateToken(ctx, db, "ci")
+	assert.NoError(t, err)
+	defer authops.RevokeToken(ctx, db, token.ID)
+
+	var n int
+	assert.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_tokens WHERE hash = $1`, secret).Scan(&n))
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,109 @@
+package cacheops
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a value is cached unless New is given another.
+	// It bounds how long changes made by other processes go unseen.
+	DefaultTTL = time.Minute
+	// MaxEntries is the number of values cached per game. The oldest value
+	// is dropped to cache another.
+	MaxEntries = 256
+)
+
+// Entry is a cached value and when it was computed
+type Entry struct {
+	Value    any
+	Computed time.Time
+}
+
+// Cache holds the values computed from the draws of each game by key,
+// until the game is invalidated or the values are older than its TTL
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]Entry
+	// generations counts the invalidations of each game, so that values
+	// computed before an invalidation are not cached after it
+	generations map[string]uint64
+	now         func() time.Time
+}
+
+// New returns a cache keeping values for the ttl. A cache with a ttl that
+// is not positive computes every value.
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:         ttl,
+		entries:     map[string]map[string]Entry{},
+		generations: map[string]uint64{},
+		now:         time.Now,
+	}
+}
+
+// Get returns the value of the game cached by the key, or computes and
+// caches it. Errors are returned without being cached.
+func (c *Cache) Get(game, key string, compute func() (any, error)) (Entry, error) {
+	c.mu.Lock()
+	e, ok := c.entries[game][key]
+	generation := c.generations[game]
+	c.mu.Unlock()
+	if ok && c.now().Sub(e.Computed) < c.ttl {
+		return e, nil
+	}
+
+	computed := c.now()
+	v, err := compute()
+	if err != nil {
+		return Entry{}, err
+	}
+	e = Entry{Value: v, Computed: computed}
+	if c.ttl <= 0 {
+		return e, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[game] != generation {
+		return e, nil
+	}
+	entries, ok := c.entries[game]
+	if !ok {
+		entries = map[string]Entry{}
+		c.entries[game] = entries
+	}
+	if _, ok := entries[key]; !ok && len(entries) >= MaxEntries {
+		evictOldest(entries)
+	}
+	entries[key] = e
+	return e, nil
+}
+
+// Invalidate drops the values of the game, to be called whenever its draws
+// are persisted, updated or deleted
+func (c *Cache) Invalidate(game string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, game)
+	c.generations[game]++
+}
+
+// Len returns the number of values cached for the game
+func (c *Cache) Len(game string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries[game])
+}
+
+// evictOldest drops the value computed first
+func evictOldest(entries map[string]Entry) {
+	oldest := ""
+	for key, e := range entries {
+		if oldest == "" || e.Computed.Before(entries[oldest].Computed) {
+			oldest = key
+		}
+	}
+	delete(entries, oldest)
+}
@@ -0,0 +1,106 @@
+package cacheops
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errCompute = errors.New("compute error")
+
+func TestCacheGet(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	computed := 0
+	compute := func() (any, error) {
+		computed++
+		return computed, nil
+	}
+
+	testcases := []struct {
+		name      string
+		advance   time.Duration
+		game      string
+		key       string
+		before    func()
+		wantValue any
+	}{
+		{name: "computed", game: "tball", key: "freq", wantValue: 1},
+		{name: "cached", game: "tball", key: "freq", wantValue: 1},
+		{name: "other key", game: "tball", key: "draws?limit=10", wantValue: 2},
+		{name: "other game", game: "euro", key: "freq", wantValue: 3},
+		{name: "invalidated", game: "tball", key: "freq", before: func() { c.Invalidate("tball") }, wantValue: 4},
+		{name: "other game kept", game: "euro", key: "freq", wantValue: 3},
+		{name: "expired", advance: time.Minute, game: "euro", key: "freq", wantValue: 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.advance)
+			if tc.before != nil {
+				tc.before()
+			}
+			got, err := c.Get(tc.game, tc.key, compute)
+			if err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			assert.Equal(t, tc.wantValue, got.Value)
+		})
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	c := New(time.Minute)
+	_, err := c.Get("tball", "freq", func() (any, error) {
+		return nil, errCompute
+	})
+	if !errors.Is(err, errCompute) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", errCompute, err)
+	}
+	assert.Equal(t, 0, c.Len("tball"))
+}
+
+func TestCacheInvalidateDuringCompute(t *testing.T) {
+	c := New(time.Minute)
+	c.Get("tball", "freq", func() (any, error) {
+		c.Invalidate("tball")
+		return "stale", nil
+	})
+	got, _ := c.Get("tball", "freq", func() (any, error) {
+		return "fresh", nil
+	})
+	assert.Equal(t, "fresh", got.Value)
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := New(0)
+	for i := range 2 {
+		got, _ := c.Get("tball", "freq", func() (any, error) {
+			return i, nil
+		})
+		assert.Equal(t, i, got.Value)
+	}
+	assert.Equal(t, 0, c.Len("tball"))
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	for i := range MaxEntries + 1 {
+		now = now.Add(time.Millisecond)
+		c.Get("tball", fmt.Sprint(i), func() (any, error) {
+			return i, nil
+		})
+	}
+	assert.Equal(t, MaxEntries, c.Len("tball"))
+
+	got, _ := c.Get("tball", "0", func() (any, error) {
+		return "recomputed", nil
+	})
+	assert.Equal(t, "recomputed", got.Value)
+}
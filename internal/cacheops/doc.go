@@ -0,0 +1,2 @@
+// Package cacheops caches statistics computed from the draws of each game until the draws change.
+package cacheops
@@ -0,0 +1,135 @@
+package chartops
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// BarChart writes bars as a horizontal bar chart scaled to width columns.
+// Expected values are marked with ┊ beyond the end of a bar and │ within it.
+func BarChart(w io.Writer, bars []Bar, width int) error {
+	labelWidth, valueWidth, top := 0, 0, 0.0
+	for _, b := range bars {
+		if b.Value < 0 || b.Expected < 0 || math.IsNaN(b.Value) || math.IsNaN(b.Expected) {
+			return fmt.Errorf("%w: %s", ErrValues, b.Label)
+		}
+		labelWidth = max(labelWidth, utf8.RuneCountInString(b.Label))
+		valueWidth = max(valueWidth, len(formatValue(b.Value)))
+		top = max(top, b.Value, b.Expected)
+	}
+
+	barWidth := width - labelWidth - valueWidth - 2
+	if width < MinWidth || barWidth < 1 {
+		return fmt.Errorf("%w: %d columns", ErrWidth, width)
+	}
+
+	for _, b := range bars {
+		cells := []rune(strings.Repeat(" ", barWidth))
+		if top > 0 {
+			eighth := int(math.Round(b.Value / top * float64(barWidth*8)))
+			for i := 0; i < eighth/8; i++ {
+				cells[i] = eighths[7]
+			}
+			if r := eighth % 8; r > 0 {
+				cells[eighth/8] = eighths[r-1]
+			}
+			if b.Expected > 0 {
+				i := min(int(b.Expected/top*float64(barWidth)), barWidth-1)
+				if cells[i] == ' ' {
+					cells[i] = expectedMark
+				} else {
+					cells[i] = expectedOnBar
+				}
+			}
+		}
+		line := fmt.Sprintf("%*s %s %*s", labelWidth, b.Label, string(cells), valueWidth, formatValue(b.Value))
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sparkline returns values as a line of blocks, one per value, scaled between
+// the lowest and highest value
+func Sparkline(values []float64) string {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		low, high = min(low, v), max(high, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if high > low {
+			i = int(math.Round((v - low) / (high - low) * float64(len(levels)-1)))
+		}
+		sb.WriteRune(levels[i])
+	}
+	return sb.String()
+}
+
+// Heatmap writes values as a grid of shaded cells, one row per row label and
+// one column per column label, scaled between 0 and the highest value. When
+// the columns exceed width, only the last columns that fit are drawn.
+func Heatmap(w io.Writer, rows []string, cols []string, values [][]float64, width int) error {
+	if len(values) != len(rows) {
+		return fmt.Errorf("%w: %d rows of values for %d labels", ErrValues, len(values), len(rows))
+	}
+	labelWidth, top := 0, 0.0
+	for i, r := range rows {
+		if len(values[i]) != len(cols) {
+			return fmt.Errorf("%w: %d columns of values for %d labels", ErrValues, len(values[i]), len(cols))
+		}
+		labelWidth = max(labelWidth, utf8.RuneCountInString(r))
+		for _, v := range values[i] {
+			top = max(top, v)
+		}
+	}
+
+	visible := min(len(cols), width-labelWidth-1)
+	if width < MinWidth || visible < 1 {
+		return fmt.Errorf("%w: %d columns", ErrWidth, width)
+	}
+	skip := len(cols) - visible
+
+	if _, err := fmt.Fprintf(w, "%*s %s\n", labelWidth, "", axis(cols[skip], cols[len(cols)-1], visible)); err != nil {
+		return err
+	}
+	for i, r := range rows {
+		var sb strings.Builder
+		for _, v := range values[i][skip:] {
+			shade := 0
+			if top > 0 {
+				shade = int(math.Round(v / top * float64(len(shades)-1)))
+			}
+			sb.WriteRune(shades[shade])
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("%*s %s", labelWidth, r, sb.String()), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// axis returns the first and last labels spread across width columns
+func axis(first, last string, width int) string {
+	if first == last {
+		return first
+	}
+	gap := width - utf8.RuneCountInString(first) - utf8.RuneCountInString(last)
+	if gap < 1 {
+		return first + " " + last
+	}
+	return first + strings.Repeat(" ", gap) + last
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
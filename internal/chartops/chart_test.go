@@ -0,0 +1,125 @@
+package chartops
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarChart(t *testing.T) {
+	testcases := []struct {
+		name  string
+		bars  []Bar
+		width int
+		want  string
+		err   error
+	}{
+		{
+			name:  "bars with expected marker",
+			bars:  []Bar{{Label: "1", Value: 10, Expected: 5}, {Label: "10", Value: 2, Expected: 5}},
+			width: 24,
+			want:  " 1 █████████│████████ 10\n10 ███▋     ┊          2\n",
+		},
+		{
+			name:  "no expected value",
+			bars:  []Bar{{Label: "a", Value: 8}, {Label: "b", Value: 1}},
+			width: 20,
+			want:  "a ████████████████ 8\nb ██               1\n",
+		},
+		{
+			name:  "too narrow",
+			bars:  []Bar{{Label: "1", Value: 1}},
+			width: 10,
+			err:   ErrWidth,
+		},
+		{
+			name:  "negative value",
+			bars:  []Bar{{Label: "1", Value: -1}},
+			width: 80,
+			err:   ErrValues,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := BarChart(&buf, tc.bars, tc.width)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.err, err)
+			}
+			if tc.err == nil {
+				assert.Equal(t, tc.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	testcases := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{name: "rising", values: []float64{0, 1, 2, 3, 4, 5, 6, 7}, want: "▁▂▃▄▅▆▇█"},
+		{name: "flat", values: []float64{3, 3, 3}, want: "▁▁▁"},
+		{name: "empty", values: nil, want: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Sparkline(tc.values))
+		})
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	testcases := []struct {
+		name   string
+		rows   []string
+		cols   []string
+		values [][]float64
+		width  int
+		want   string
+		err    error
+	}{
+		{
+			name:   "all columns",
+			rows:   []string{"1", "2"},
+			cols:   []string{"2025", "2026"},
+			values: [][]float64{{0, 4}, {2, 1}},
+			width:  20,
+			want:   "  2025 2026\n1  █\n2 ▒░\n",
+		},
+		{
+			name:   "last columns within width",
+			rows:   []string{"1"},
+			cols:   []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u"},
+			values: [][]float64{{4, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4}},
+			width:  20,
+			want:   "  d                u\n1                  █\n",
+		},
+		{
+			name:   "mismatched values",
+			rows:   []string{"1", "2"},
+			cols:   []string{"2026"},
+			values: [][]float64{{1}},
+			width:  20,
+			err:    ErrValues,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Heatmap(&buf, tc.rows, tc.cols, tc.values, tc.width)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.err, err)
+			}
+			if tc.err == nil {
+				assert.Equal(t, tc.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package chartops
+
+import (
+	"errors"
+)
+
+var (
+	ErrWidth  = errors.New("chart too narrow")
+	ErrValues = errors.New("invalid chart values")
+)
+
+// MinWidth is the fewest columns a chart is drawn in
+const MinWidth = 20
+
+// Bar is a bar in a horizontal bar chart
+type Bar struct {
+	Label    string
+	Value    float64
+	Expected float64 // Marked on the bar when greater than 0
+}
+
+var (
+	// eighths are the blocks filling 1/8 to 8/8 of a column
+	eighths = []rune("▏▎▍▌▋▊▉█")
+	// levels are the blocks of a sparkline from lowest to highest
+	levels = []rune("▁▂▃▄▅▆▇█")
+	// shades are the cells of a heatmap from lowest to highest
+	shades = []rune(" ░▒▓█")
+)
+
+const (
+	// expectedMark marks an expected value beyond the end of a bar
+	expectedMark = '┊'
+	// expectedOnBar marks an expected value within a bar
+	expectedOnBar = '│'
+)
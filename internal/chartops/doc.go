@@ -0,0 +1,2 @@
+// Package chartops contains operations to draw charts in a terminal with Unicode block characters.
+package chartops
@@ -18,6 +18,7 @@ var (
 	// CSV File
 	ErrDownloadFromURL = errors.New("unable to download from url")
 	ErrInvalidURL      = errors.New("invalid url")
+	ErrSource          = errors.New("unable to open source")
 	// Date
 	ErrInvalidDateFmt     = errors.New("invalid date format")
 	ErrInvalidDayFmt      = errors.New("invalid day format")
@@ -32,6 +33,9 @@ var (
 	ErrInvalidDrawSeq   = errors.New("invalid draw seq")
 )
 
+// DateLayout is the layout of draw dates in the National Lottery CSV
+const DateLayout = "02-Jan-2006"
+
 // ParseDate converts date in string to
 func ParseDate(date string) (time.Time, error) {
 	regex := regexp.MustCompile(`(?i)^\d{1,2}-(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)-\d{4}$`)
@@ -160,17 +164,22 @@ func ParseDrawSeq(value string) (uint64, error) {
 func DownloadFrom(url string) (io.Reader, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrDownloadFromURL, err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", ErrDownloadFromURL, url, resp.Status)
+	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrDownloadFromURL, err)
 	}
 	return bytes.NewReader(b), nil
 }
 
 type CSVRec struct {
+	// Header is the National Lottery CSV header of Record, nil for records
+	// converted from JSON, NDJSON and XLSX draws named by their fields
 	Header []string
 	Record []string
 	Line   uint
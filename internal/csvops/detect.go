@@ -0,0 +1,77 @@
+package csvops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrGame = errors.New("no game matches")
+)
+
+// detectSample is the number of records inspected to detect the game of a
+// source
+const detectSample = 10
+
+// Game describes how records of a game are read and recognised
+type Game struct {
+	Name string
+	// RecordOf converts JSON, NDJSON and XLSX draws of the game
+	RecordOf RecordFunc
+	// Match reports whether a record is a draw of the game
+	Match func(CSVRec) bool
+}
+
+// DetectGame returns the name of the game whose draws are in content. Up to
+// the first detectSample records are extracted as records of every game, and
+// the game matching most of them is returned, provided it matches more than
+// half of them.
+func DetectGame(ctx context.Context, content []byte, format Format, games []Game) (string, error) {
+	best, bestScore, tied := "", 0, false
+	sampled := 0
+	for _, g := range games {
+		score, n := matchSample(ctx, content, format, g)
+		sampled = max(sampled, n)
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = g.Name, score, false
+		case score == bestScore && score > 0:
+			tied = true
+		}
+	}
+	if sampled == 0 {
+		return "", fmt.Errorf("%w: no records", ErrGame)
+	}
+	if tied {
+		return "", fmt.Errorf("%w: more than one game matches", ErrGame)
+	}
+	if bestScore*2 <= sampled {
+		return "", fmt.Errorf("%w: %d of %d records match", ErrGame, bestScore, sampled)
+	}
+	return best, nil
+}
+
+// matchSample returns the number of records of the sample of content
+// matching the game, and the size of the sample
+func matchSample(ctx context.Context, content []byte, format Format, g Game) (int, int) {
+	ctx, cancel := context.WithCancel(ctx)
+	recs := Extract(ctx, bytes.NewReader(content), format, g.RecordOf)
+
+	score, n := 0, 0
+	for rec := range recs {
+		if g.Match(rec) {
+			score++
+		}
+		n++
+		if n == detectSample {
+			break
+		}
+	}
+	// Records sent before the cancellation is seen are discarded
+	cancel()
+	for range recs {
+	}
+	return score, n
+}
@@ -0,0 +1,77 @@
+package csvops
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testGame matches records whose header, if any, names column and whose
+// second value is at most maxValue
+func testGame(name string, column string, maxValue int) Game {
+	return Game{
+		Name: name,
+		RecordOf: func(fields map[string]string) []string {
+			return []string{fields["draw_no"], fields[column]}
+		},
+		Match: func(rec CSVRec) bool {
+			if rec.Err != nil || len(rec.Record) < 2 {
+				return false
+			}
+			if rec.Header != nil && !slices.Contains(rec.Header, column) {
+				return false
+			}
+			v, err := strconv.Atoi(rec.Record[1])
+			return err == nil && v >= 1 && v <= maxValue
+		},
+	}
+}
+
+func TestDetectGame(t *testing.T) {
+	games := []Game{testGame("small", "Small", 10), testGame("large", "Large", 50), testGame("wide", "Wide", 50)}
+
+	testcases := []struct {
+		name    string
+		content string
+		format  Format
+		want    string
+		wantErr error
+	}{
+		{name: "header", content: "DrawNumber,Large\n1,5\n2,45\n", format: CSV, want: "large"},
+		{name: "value ranges", content: `[{"draw_no":1,"Small":3},{"draw_no":2,"Small":9}]`, format: JSON, want: "small"},
+		{name: "majority", content: "DrawNumber,Small\n1,5\n2,45\n3,7\n", format: CSV, want: "small"},
+		{name: "out of range", content: "DrawNumber,Small\n1,15\n2,45\n", format: CSV, wantErr: ErrGame},
+		{name: "unknown header", content: "DrawNumber,Other\n1,5\n", format: CSV, wantErr: ErrGame},
+		{name: "ambiguous", content: "{\"draw_no\":1,\"Large\":20,\"Wide\":20}\n", format: NDJSON, wantErr: ErrGame},
+		{name: "no records", content: "DrawNumber,Small\n", format: CSV, wantErr: ErrGame},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DetectGame(context.TODO(), []byte(tc.content), tc.format, games)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDetectGameSample(t *testing.T) {
+	// Only the sample is inspected, later records out of range are ignored
+	content := "DrawNumber,Small\n"
+	for i := range detectSample + 5 {
+		value := "5"
+		if i >= detectSample {
+			value = "99"
+		}
+		content += strconv.Itoa(i+1) + "," + value + "\n"
+	}
+	got, err := DetectGame(context.TODO(), []byte(content), CSV, []Game{testGame("small", "Small", 10)})
+	assert.NoError(t, err)
+	assert.Equal(t, "small", got)
+}
@@ -1,2 +1,2 @@
-// Package csvops contains library routines to support operations for the processing of national lottery csv files.
+// Package csvops contains library routines to support operations for the processing of national lottery csv files, and of JSON, NDJSON and XLSX files of draws.
 package csvops
@@ -0,0 +1,140 @@
+package csvops
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Stdin is the name of the source read from standard input
+const Stdin = "-"
+
+// Source is a file of draws opened by Open
+type Source struct {
+	Name    string // Name of the file, the entry of an archive or the URL
+	Archive string // Name of the archive holding the entry, otherwise empty
+	io.ReadCloser
+}
+
+// Open opens the sources of draws named by name, which is Stdin, an http or
+// https URL or a local path. Gzip files, named .gz or detected from their
+// content, are decompressed and every file of a .zip archive is a source.
+func Open(name string) ([]Source, error) {
+	switch {
+	case name == Stdin:
+		return unpack(name, name, io.NopCloser(os.Stdin))
+	case strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://"):
+		u, err := url.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidURL, name)
+		}
+		r, err := DownloadFrom(name)
+		if err != nil {
+			return nil, err
+		}
+		return unpack(name, path.Base(u.Path), io.NopCloser(r))
+	default:
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		return unpack(name, name, f)
+	}
+}
+
+// unpack returns the sources of a file. ext is the name whose extension
+// selects an archive or compression, the name of a URL without its query.
+func unpack(name string, ext string, rc io.ReadCloser) ([]Source, error) {
+	switch strings.ToLower(filepath.Ext(ext)) {
+	case ".zip":
+		return unzip(name, rc)
+	case ".gz":
+		return gunzip(name, strings.TrimSuffix(ext, filepath.Ext(ext)), rc)
+	}
+
+	br := bufio.NewReader(rc)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return gunzip(name, ext, readCloser{Reader: br, Closer: rc})
+	}
+	return []Source{{Name: name, ReadCloser: readCloser{Reader: br, Closer: rc}}}, nil
+}
+
+func gunzip(name string, ext string, rc io.ReadCloser) ([]Source, error) {
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("%w: %s: %w", ErrSource, name, err)
+	}
+	return unpack(strings.TrimSuffix(name, ".gz"), ext, readCloser{Reader: gz, Closer: rc})
+}
+
+func unzip(name string, rc io.ReadCloser) ([]Source, error) {
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrSource, name, err)
+	}
+
+	srcs := []Source{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		entry, err := f.Open()
+		if err != nil {
+			closeSources(srcs)
+			return nil, fmt.Errorf("%w: %s: %w", ErrSource, f.Name, err)
+		}
+		entrySrcs, err := unpack(f.Name, f.Name, entry)
+		if err != nil {
+			closeSources(srcs)
+			return nil, err
+		}
+		for _, s := range entrySrcs {
+			s.Archive = name
+			srcs = append(srcs, s)
+		}
+	}
+	return srcs, nil
+}
+
+// PeekHeader returns the first CSV record of r as a header. The returned
+// reader reads the whole of r, including the header.
+func PeekHeader(r io.Reader) ([]string, io.Reader) {
+	br := bufio.NewReader(r)
+	line, _ := br.Peek(4096)
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	header, err := csv.NewReader(bytes.NewReader(line)).Read()
+	if err != nil {
+		return []string{}, br
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	return header, br
+}
+
+func closeSources(srcs []Source) {
+	for _, s := range srcs {
+		s.Close()
+	}
+}
+
+// readCloser reads from a reader wrapping the closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
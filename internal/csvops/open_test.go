@@ -0,0 +1,165 @@
+package csvops
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = "DrawDate,Ball 1,Thunderball,DrawNumber\n20-Feb-2026,1,3,3856\n"
+
+func gzipBytes(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gz, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readSources returns the content of each source by name
+func readSources(t *testing.T, srcs []Source) map[string]string {
+	got := map[string]string{}
+	for _, s := range srcs {
+		b, err := io.ReadAll(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+		got[s.Archive+"|"+s.Name] = string(b)
+	}
+	return got
+}
+
+func TestOpenFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"draws.csv":        []byte(testCSV),
+		"draws.csv.gz":     gzipBytes(t, testCSV),
+		"draws.bin":        gzipBytes(t, testCSV),
+		"draws.zip":        zipBytes(t, map[string][]byte{"a.csv": []byte(testCSV), "b.csv.gz": gzipBytes(t, testCSV), "dir/": nil}),
+		"broken.csv.gz":    []byte("not gzip"),
+		"broken-entry.zip": []byte("not zip"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name    string
+		file    string
+		want    map[string]string
+		wantErr error
+	}{
+		{name: "csv", file: "draws.csv", want: map[string]string{"|draws.csv": testCSV}},
+		{name: "gzip by extension", file: "draws.csv.gz", want: map[string]string{"|draws.csv": testCSV}},
+		{name: "gzip by content", file: "draws.bin", want: map[string]string{"|draws.bin": testCSV}},
+		{name: "zip", file: "draws.zip", want: map[string]string{"draws.zip|a.csv": testCSV, "draws.zip|b.csv": testCSV}},
+		{name: "invalid gzip", file: "broken.csv.gz", wantErr: ErrSource},
+		{name: "invalid zip", file: "broken-entry.zip", wantErr: ErrSource},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srcs, err := Open(filepath.Join(dir, tc.file))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			got := map[string]string{}
+			for k, v := range readSources(t, srcs) {
+				archive, name, _ := bytes.Cut([]byte(k), []byte("|"))
+				key := "|" + filepath.Base(string(name))
+				if len(archive) > 0 {
+					key = filepath.Base(string(archive)) + "|" + string(name)
+				}
+				got[key] = v
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOpenURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/draws.csv":
+			io.WriteString(rw, testCSV)
+		case "/draws.csv.gz":
+			rw.Write(gzipBytes(t, testCSV))
+		case "/draws.zip":
+			rw.Write(zipBytes(t, map[string][]byte{"a.csv": []byte(testCSV)}))
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	defer srv.Close()
+
+	testcases := []struct {
+		name    string
+		path    string
+		want    map[string]string
+		wantErr error
+	}{
+		{name: "csv", path: "/draws.csv", want: map[string]string{"|" + srv.URL + "/draws.csv": testCSV}},
+		{name: "gzip", path: "/draws.csv.gz?v=1", want: map[string]string{"|" + srv.URL + "/draws.csv.gz?v=1": testCSV}},
+		{name: "zip", path: "/draws.zip", want: map[string]string{srv.URL + "/draws.zip|a.csv": testCSV}},
+		{name: "not found", path: "/missing.csv", wantErr: ErrDownloadFromURL},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srcs, err := Open(srv.URL + tc.path)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.want, readSources(t, srcs))
+			}
+		})
+	}
+}
+
+func TestPeekHeader(t *testing.T) {
+	header, r := PeekHeader(bytes.NewReader([]byte("\ufeff" + testCSV)))
+	assert.Equal(t, []string{"DrawDate", "Ball 1", "Thunderball", "DrawNumber"}, header)
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "\ufeff"+testCSV, string(content))
+}
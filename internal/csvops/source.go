@@ -0,0 +1,260 @@
+package csvops
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrFormat = errors.New("unsupported record format")
+)
+
+// Format is the format of a source of draw records
+type Format string
+
+const (
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// Formats lists the supported formats of record sources
+var Formats = []Format{CSV, JSON, NDJSON, XLSX}
+
+// xlsxSheet is the sheet read from a workbook when present, otherwise the
+// first sheet is read
+const xlsxSheet = "draws"
+
+// RecordFunc converts the fields of a draw, named by the JSON tags of the
+// game's Draw, to a record of the National Lottery CSV layout of the game
+type RecordFunc func(fields map[string]string) []string
+
+// ParseFormat converts the name of a format to Format
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s, expected one of %v", ErrFormat, name, Formats)
+}
+
+// FormatOfContentType returns the format of a media type. ok is false for
+// media types that do not identify a format, such as
+// application/octet-stream.
+func FormatOfContentType(contentType string) (format Format, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return CSV, true
+	case "application/json":
+		return JSON, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return NDJSON, true
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return XLSX, true
+	}
+	return "", false
+}
+
+// DetectFormat returns the format of a source from the extension of its
+// name or, failing that, from its first bytes. The returned reader reads
+// the whole source, including the bytes inspected.
+func DetectFormat(name string, r io.Reader) (Format, io.Reader) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV, r
+	case ".json":
+		return JSON, r
+	case ".ndjson", ".jsonl":
+		return NDJSON, r
+	case ".xlsx":
+		return XLSX, r
+	}
+
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return XLSX, br
+	}
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("[")):
+		return JSON, br
+	case bytes.HasPrefix(head, []byte("{")):
+		return NDJSON, br
+	}
+	return CSV, br
+}
+
+// Extract converts a source in the format to a channel of CSVRec in the
+// National Lottery CSV layout, using toRecord for formats of named fields
+func Extract(ctx context.Context, r io.Reader, format Format, toRecord RecordFunc) chan CSVRec {
+	switch format {
+	case JSON:
+		return ExtractJSON(ctx, r, toRecord)
+	case NDJSON:
+		return ExtractNDJSON(ctx, r, toRecord)
+	case XLSX:
+		return ExtractXLSX(ctx, r, toRecord)
+	default:
+		return ExtractRec(ctx, r)
+	}
+}
+
+// ExtractJSON converts a JSON array of draws to a channel of CSVRec
+func ExtractJSON(ctx context.Context, r io.Reader, toRecord RecordFunc) chan CSVRec {
+	c := make(chan CSVRec)
+	go func(ch chan CSVRec) {
+		defer close(ch)
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			sendRec(ctx, ch, CSVRec{Line: 1, Err: fmt.Errorf("%w-expected a JSON array", ErrLine)})
+			return
+		}
+		for ln := uint(1); dec.More(); ln++ {
+			rec := decodeRec(dec, ln, toRecord)
+			if !sendRec(ctx, ch, rec) {
+				return
+			}
+			// Values other than objects are skipped, invalid JSON ends the array
+			var typeErr *json.UnmarshalTypeError
+			if rec.Err != nil && !errors.As(rec.Err, &typeErr) {
+				return
+			}
+		}
+	}(c)
+	return c
+}
+
+// ExtractNDJSON converts a stream of JSON draws, one per line, to a channel
+// of CSVRec
+func ExtractNDJSON(ctx context.Context, r io.Reader, toRecord RecordFunc) chan CSVRec {
+	c := make(chan CSVRec)
+	go func(ch chan CSVRec) {
+		defer close(ch)
+		sc := bufio.NewScanner(r)
+		for ln := uint(1); sc.Scan(); ln++ {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			if !sendRec(ctx, ch, decodeRec(dec, ln, toRecord)) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			sendRec(ctx, ch, CSVRec{Err: fmt.Errorf("%w-%s", ErrLine, err.Error())})
+		}
+	}(c)
+	return c
+}
+
+// ExtractXLSX converts the rows of a workbook to a channel of CSVRec. Rows
+// are read from the sheet named draws, or the first sheet, whose header is
+// either the National Lottery CSV header or the JSON tags of the game's Draw.
+func ExtractXLSX(ctx context.Context, r io.Reader, toRecord RecordFunc) chan CSVRec {
+	c := make(chan CSVRec)
+	go func(ch chan CSVRec) {
+		defer close(ch)
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			sendRec(ctx, ch, CSVRec{Err: fmt.Errorf("%w-%s", ErrLine, err.Error())})
+			return
+		}
+		defer f.Close()
+
+		sheet := f.GetSheetName(0)
+		if idx, err := f.GetSheetIndex(xlsxSheet); err == nil && idx >= 0 {
+			sheet = xlsxSheet
+		}
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			sendRec(ctx, ch, CSVRec{Err: fmt.Errorf("%w-%s", ErrLine, err.Error())})
+			return
+		}
+		if len(rows) == 0 {
+			return
+		}
+
+		header := rows[0]
+		national := len(header) > 0 && header[0] == "DrawDate"
+		for i, row := range rows[1:] {
+			rec := CSVRec{Record: row, Line: uint(i + 1)}
+			if national {
+				rec.Header = header
+			} else {
+				fields := map[string]string{}
+				for j, name := range header {
+					if j < len(row) {
+						fields[name] = row[j]
+					}
+				}
+				rec.Record = toRecord(fields)
+			}
+			if !sendRec(ctx, ch, rec) {
+				return
+			}
+		}
+	}(c)
+	return c
+}
+
+// CSVDate converts a draw date formatted RFC 3339 or YYYY-MM-DD to the
+// layout of the National Lottery CSV. Other values are returned unchanged.
+func CSVDate(value string) string {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(DateLayout)
+		}
+	}
+	return value
+}
+
+// decodeRec decodes the next JSON object of dec to a CSVRec
+func decodeRec(dec *json.Decoder, ln uint, toRecord RecordFunc) CSVRec {
+	obj := map[string]any{}
+	if err := dec.Decode(&obj); err != nil {
+		return CSVRec{Line: ln, Err: fmt.Errorf("%w-%w", ErrLine, err)}
+	}
+	fields := map[string]string{}
+	for k, v := range obj {
+		switch v := v.(type) {
+		case nil:
+			fields[k] = ""
+		case string:
+			fields[k] = v
+		default:
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	return CSVRec{Record: toRecord(fields), Line: ln}
+}
+
+// sendRec sends rec unless ctx is done, reporting whether it was sent
+func sendRec(ctx context.Context, ch chan CSVRec, rec CSVRec) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- rec:
+		return true
+	}
+}
@@ -0,0 +1,196 @@
+package csvops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func testRecord(fields map[string]string) []string {
+	return []string{CSVDate(fields["draw_date"]), fields["ball1"], fields["draw_no"]}
+}
+
+func collectRecs(ch chan CSVRec) ([][]string, []error) {
+	recs := [][]string{}
+	errs := []error{}
+	for r := range ch {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+		recs = append(recs, r.Record)
+	}
+	return recs, errs
+}
+
+func TestDetectFormat(t *testing.T) {
+	testcases := []struct {
+		name    string
+		file    string
+		content string
+		want    Format
+	}{
+		{name: "extension", file: "draws.NDJSON", content: "[", want: NDJSON},
+		{name: "json array", file: "upload", content: "\xef\xbb\xbf \n[{}]", want: JSON},
+		{name: "ndjson", file: "", content: `{"draw_no":1}`, want: NDJSON},
+		{name: "xlsx", file: "", content: "PK\x03\x04rest", want: XLSX},
+		{name: "csv", file: "", content: "DrawDate,Ball 1", want: CSV},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, r := DetectFormat(tc.file, strings.NewReader(tc.content))
+			assert.Equal(t, tc.want, got)
+			content, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.content, string(content))
+		})
+	}
+}
+
+func TestFormatOfContentType(t *testing.T) {
+	testcases := []struct {
+		input  string
+		want   Format
+		wantOK bool
+	}{
+		{input: "text/csv; charset=utf-8", want: CSV, wantOK: true},
+		{input: "application/json", want: JSON, wantOK: true},
+		{input: "application/x-ndjson", want: NDJSON, wantOK: true},
+		{input: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", want: XLSX, wantOK: true},
+		{input: "application/octet-stream"},
+		{input: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, ok := FormatOfContentType(tc.input)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantOK, ok)
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		wantRecs [][]string
+		wantErrs int
+	}{
+		{
+			name:     "array of draws",
+			input:    `[{"draw_date":"2026-02-20T00:00:00Z","ball1":13,"draw_no":1922},{"draw_date":"2026-02-24","ball1":7,"draw_no":1923}]`,
+			wantRecs: [][]string{{"20-Feb-2026", "13", "1922"}, {"24-Feb-2026", "7", "1923"}},
+		},
+		{
+			name:     "value not an object",
+			input:    `[1,{"draw_date":"2026-02-24","ball1":7,"draw_no":1923}]`,
+			wantRecs: [][]string{{"24-Feb-2026", "7", "1923"}},
+			wantErrs: 1,
+		},
+		{
+			name:     "invalid json",
+			input:    `[{"draw_no":1923`,
+			wantRecs: [][]string{},
+			wantErrs: 1,
+		},
+		{
+			name:     "not an array",
+			input:    `{"draw_no":1923}`,
+			wantRecs: [][]string{},
+			wantErrs: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recs, errs := collectRecs(ExtractJSON(context.TODO(), strings.NewReader(tc.input), testRecord))
+			assert.Equal(t, tc.wantRecs, recs)
+			assert.Len(t, errs, tc.wantErrs)
+			for _, err := range errs {
+				if !errors.Is(err, ErrLine) {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", ErrLine, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractNDJSON(t *testing.T) {
+	input := `{"draw_date":"2026-02-20","ball1":13,"draw_no":1922}
+
+not json
+{"draw_date":"2026-02-24","ball1":"7","draw_no":1923}
+`
+	recs, errs := collectRecs(ExtractNDJSON(context.TODO(), strings.NewReader(input), testRecord))
+	assert.Equal(t, [][]string{{"20-Feb-2026", "13", "1922"}, {"24-Feb-2026", "7", "1923"}}, recs)
+	assert.Len(t, errs, 1)
+}
+
+func TestExtractXLSX(t *testing.T) {
+	testcases := []struct {
+		name   string
+		sheets map[string][][]any
+		want   [][]string
+	}{
+		{
+			name: "national lottery layout",
+			sheets: map[string][][]any{"Sheet1": {
+				{"DrawDate", "Ball 1", "DrawNumber"},
+				{"20-Feb-2026", 13, 1922},
+			}},
+			want: [][]string{{"20-Feb-2026", "13", "1922"}},
+		},
+		{
+			name: "draws sheet of export",
+			sheets: map[string][][]any{
+				"Sheet1": {{"other"}},
+				"draws": {
+					{"draw_no", "draw_date", "ball1"},
+					{1922, "2026-02-20", 13},
+				},
+			},
+			want: [][]string{{"20-Feb-2026", "13", "1922"}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := excelize.NewFile()
+			for name, rows := range tc.sheets {
+				if _, err := f.NewSheet(name); err != nil {
+					t.Fatal(err)
+				}
+				for i, row := range rows {
+					cell, _ := excelize.CoordinatesToCellName(1, i+1)
+					if err := f.SetSheetRow(name, cell, &row); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			var buf bytes.Buffer
+			if _, err := f.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			recs, errs := collectRecs(ExtractXLSX(context.TODO(), &buf, testRecord))
+			assert.Empty(t, errs)
+			assert.Equal(t, tc.want, recs)
+		})
+	}
+}
+
+func TestCSVDate(t *testing.T) {
+	assert.Equal(t, "20-Feb-2026", CSVDate("2026-02-20T00:00:00Z"))
+	assert.Equal(t, "20-Feb-2026", CSVDate("2026-02-20"))
+	assert.Equal(t, "20-Feb-2026", CSVDate("20-Feb-2026"))
+}
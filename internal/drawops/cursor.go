@@ -0,0 +1,46 @@
+package drawops
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsZero reports whether the cursor is the position before the first draw
+func (c Cursor) IsZero() bool {
+	return c.DrawNo == 0 && c.Date.IsZero()
+}
+
+// String encodes the cursor as an opaque token
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s/%d", c.Date.UTC().Format(time.DateOnly), c.DrawNo))
+}
+
+// ParseCursor decodes a token encoded by Cursor.String. An empty token is
+// the zero cursor.
+func ParseCursor(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrCursor, token)
+	}
+	date, no, ok := strings.Cut(string(b), "/")
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrCursor, token)
+	}
+	var c Cursor
+	if c.Date, err = time.Parse(time.DateOnly, date); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrCursor, token)
+	}
+	if c.DrawNo, err = strconv.ParseUint(no, 10, 64); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrCursor, token)
+	}
+	return c, nil
+}
@@ -0,0 +1,38 @@
+package drawops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCursor(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		want    Cursor
+		wantErr error
+	}{
+		{name: "empty", input: "", want: Cursor{}, wantErr: nil},
+		{
+			name:    "round trip",
+			input:   Cursor{Date: time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC), DrawNo: 1234}.String(),
+			want:    Cursor{Date: time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC), DrawNo: 1234},
+			wantErr: nil,
+		},
+		{name: "not base64", input: "!!", want: Cursor{}, wantErr: ErrCursor},
+		{name: "no separator", input: "MjAyNg", want: Cursor{}, wantErr: ErrCursor},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCursor(tc.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package drawops contains operations common to the draws of all games.
+package drawops
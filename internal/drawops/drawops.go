@@ -0,0 +1,80 @@
+package drawops
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoDraw    = errors.New("no draw found")
+	ErrSortField = errors.New("invalid sort field")
+	ErrDateRange = errors.New("invalid date range")
+	ErrLastDraws = errors.New("invalid number of last draws")
+	ErrPeriod    = errors.New("invalid period")
+	ErrBall      = errors.New("ball not in pool")
+	ErrStored    = errors.New("draw number already stored")
+	ErrRefused   = errors.New("draw refused by store")
+	ErrDrawRange = errors.New("invalid draw number range")
+	ErrPage      = errors.New("invalid page")
+	ErrCursor    = errors.New("invalid cursor")
+)
+
+// SortField identifies the field draws are ordered by
+type SortField string
+
+const (
+	SortByDate   SortField = "date"
+	SortByDrawNo SortField = "draw_no"
+)
+
+// Filter selects and orders stored draws
+type Filter struct {
+	From       time.Time // Earliest draw date, inclusive. Zero for no lower bound
+	To         time.Time // Latest draw date, inclusive. Zero for no upper bound
+	FromDrawNo uint64    // Lowest draw number, inclusive. 0 for no lower bound
+	ToDrawNo   uint64    // Highest draw number, inclusive. 0 for no upper bound
+	Contains   []uint8   // Main balls every selected draw contains
+	Last       int       // Most recent number of draws selected. 0 for all
+	Sort       SortField // Field draws are ordered by. Empty for draw date
+	Desc       bool      // Order draws descending
+	After      Cursor    // Draw the selected draws follow in order. Zero for the first draw
+	Offset     int       // Number of ordered draws skipped
+	Limit      int       // Most draws returned after the offset. 0 for all
+}
+
+// Cursor is the position of a draw in the order of a filter. The draws of
+// the next page of a filter follow the cursor of the last draw of a page.
+type Cursor struct {
+	Date   time.Time
+	DrawNo uint64
+}
+
+// Gap reports the number of draws between appearances of a ball
+type Gap struct {
+	Ball     uint    `json:"ball" parquet:"ball"`
+	Current  uint    `json:"current" parquet:"current"`   // Draws since the ball last appeared
+	Longest  uint    `json:"longest" parquet:"longest"`   // Most draws between appearances
+	Average  float64 `json:"average" parquet:"average"`   // Mean draws between appearances
+	Expected float64 `json:"expected" parquet:"expected"` // Mean draws between appearances expected from the rules of the latest draw
+}
+
+// Period is the length of time draws are grouped by in a trend
+type Period string
+
+const (
+	Month Period = "month"
+	Year  Period = "year"
+)
+
+// Trend reports the frequency of balls in each period
+type Trend struct {
+	Balls   []uint            `json:"balls"`
+	Periods []PeriodFrequency `json:"periods"`
+}
+
+// PeriodFrequency reports the frequency of balls in a period
+type PeriodFrequency struct {
+	Period    string `json:"period"`
+	Draws     uint   `json:"draws"`     // Number of draws in the period
+	Frequency []uint `json:"frequency"` // Frequency of each ball in Trend.Balls
+}
@@ -0,0 +1,50 @@
+package drawops
+
+import "time"
+
+// EraIndex returns the index of the era in effect on the date, which is the
+// last of the eras, in chronological order, starting on or before it, or -1
+// if the date precedes every era. from returns the first draw date of an era.
+func EraIndex[E any](eras []E, from func(E) time.Time, date time.Time) int {
+	for i := len(eras) - 1; i >= 0; i-- {
+		if !date.Before(from(eras[i])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// CountByEra returns the number of dates in each of the eras. Dates
+// preceding every era are not counted.
+func CountByEra[E any](eras []E, from func(E) time.Time, dates []time.Time) []int {
+	counts := make([]int, len(eras))
+	for _, date := range dates {
+		if i := EraIndex(eras, from, date); i >= 0 {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// ExpectedFreq returns the expected number of times each ball from 1 to
+// maxBall is drawn, given the number of draws in each of the eras. pool and
+// count select the pool size and number of balls drawn of an era.
+func ExpectedFreq[E any](eras []E, drawsPerEra []int, maxBall int, pool, count func(E) int) []float64 {
+	expected := make([]float64, maxBall)
+	for i, n := range drawsPerEra {
+		p := pool(eras[i])
+		for b := 0; b < p && b < maxBall; b++ {
+			expected[b] += float64(n) * float64(count(eras[i])) / float64(p)
+		}
+	}
+	return expected
+}
+
+// MaxPool returns the largest pool of the eras
+func MaxPool[E any](eras []E, pool func(E) int) int {
+	largest := 0
+	for _, e := range eras {
+		largest = max(largest, pool(e))
+	}
+	return largest
+}
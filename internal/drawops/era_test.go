@@ -0,0 +1,59 @@
+package drawops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEra struct {
+	from  time.Time
+	pool  int
+	count int
+}
+
+var testEras = []testEra{
+	{from: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), pool: 4, count: 2},
+	{from: time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC), pool: 5, count: 1},
+}
+
+func testEraFrom(e testEra) time.Time { return e.from }
+
+func TestEraIndex(t *testing.T) {
+	testcases := []struct {
+		name string
+		date time.Time
+		want int
+	}{
+		{name: "before every era", date: time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), want: -1},
+		{name: "first day of first era", date: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), want: 0},
+		{name: "last day of first era", date: time.Date(2009, time.December, 31, 0, 0, 0, 0, time.UTC), want: 0},
+		{name: "first day of last era", date: time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC), want: 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, EraIndex(testEras, testEraFrom, tc.date))
+		})
+	}
+}
+
+func TestCountByEra(t *testing.T) {
+	dates := []time.Time{
+		time.Date(1999, time.June, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2005, time.June, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2010, time.June, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, []int{1, 2}, CountByEra(testEras, testEraFrom, dates))
+}
+
+func TestExpectedFreq(t *testing.T) {
+	got := ExpectedFreq(testEras, []int{2, 5}, MaxPool(testEras, func(e testEra) int { return e.pool }),
+		func(e testEra) int { return e.pool },
+		func(e testEra) int { return e.count })
+
+	// Balls 1 to 4 are expected 2*2/4 + 5*1/5 times, ball 5 only 5*1/5 times
+	assert.Equal(t, []float64{2, 2, 2, 2, 1}, got)
+}
@@ -0,0 +1,106 @@
+package drawops
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validate verifies the sort field, ranges and page of the filter
+func (f Filter) Validate() error {
+	switch f.Sort {
+	case "", SortByDate, SortByDrawNo:
+	default:
+		return fmt.Errorf("%w: %s", ErrSortField, f.Sort)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return fmt.Errorf("%w: %s after %s", ErrDateRange, f.From.Format(time.DateOnly), f.To.Format(time.DateOnly))
+	}
+	if f.FromDrawNo > 0 && f.ToDrawNo > 0 && f.ToDrawNo < f.FromDrawNo {
+		return fmt.Errorf("%w: %d after %d", ErrDrawRange, f.FromDrawNo, f.ToDrawNo)
+	}
+	if slices.Contains(f.Contains, 0) {
+		return fmt.Errorf("%w: 0", ErrBall)
+	}
+	if f.Last < 0 {
+		return fmt.Errorf("%w: %d", ErrLastDraws, f.Last)
+	}
+	if f.Offset < 0 || f.Limit < 0 {
+		return fmt.Errorf("%w: offset %d limit %d", ErrPage, f.Offset, f.Limit)
+	}
+	return nil
+}
+
+// SelectSQL returns a query of the draws in tbl selected by the filter,
+// with the arguments of its placeholders. dateCol and noCol name the draw
+// date and draw number columns, and ballCols the main ball columns the
+// balls of Contains are looked for in.
+func (f Filter) SelectSQL(tbl, dateCol, noCol string, ballCols ...string) (string, []any) {
+	where := []string{}
+	args := []any{}
+	if !f.From.IsZero() {
+		args = append(args, f.From.UTC().Format(time.DateOnly))
+		where = append(where, fmt.Sprintf("%s >= $%d", dateCol, len(args)))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To.UTC().AddDate(0, 0, 1).Format(time.DateOnly))
+		where = append(where, fmt.Sprintf("%s < $%d", dateCol, len(args)))
+	}
+	if f.FromDrawNo > 0 {
+		args = append(args, f.FromDrawNo)
+		where = append(where, fmt.Sprintf("%s >= $%d", noCol, len(args)))
+	}
+	if f.ToDrawNo > 0 {
+		args = append(args, f.ToDrawNo)
+		where = append(where, fmt.Sprintf("%s <= $%d", noCol, len(args)))
+	}
+	if len(ballCols) > 0 {
+		for _, b := range f.Contains {
+			args = append(args, b)
+			where = append(where, fmt.Sprintf("$%d IN (%s)", len(args), strings.Join(ballCols, ", ")))
+		}
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s", tbl)
+	if f.Last > 0 {
+		if len(where) > 0 {
+			query = fmt.Sprintf("%s WHERE %s", query, strings.Join(where, " AND "))
+		}
+		args = append(args, f.Last)
+		query = fmt.Sprintf("SELECT * FROM (%s ORDER BY %s DESC LIMIT $%d)", query, noCol, len(args))
+		where = []string{}
+	}
+
+	orderCol := dateCol
+	if f.Sort == SortByDrawNo {
+		orderCol = noCol
+	}
+	order, after := "ASC", ">"
+	if f.Desc {
+		order, after = "DESC", "<"
+	}
+	if !f.After.IsZero() {
+		if f.Sort == SortByDrawNo {
+			args = append(args, f.After.DrawNo)
+			where = append(where, fmt.Sprintf("%s %s $%d", noCol, after, len(args)))
+		} else {
+			args = append(args, f.After.Date.UTC().Format(time.DateOnly), f.After.DrawNo)
+			where = append(where, fmt.Sprintf("(%[1]s %[3]s $%[4]d OR (%[1]s = $%[4]d AND %[2]s %[3]s $%[5]d))", dateCol, noCol, after, len(args)-1, len(args)))
+		}
+	}
+	if len(where) > 0 {
+		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(where, " AND "))
+	}
+
+	query = fmt.Sprintf("%s ORDER BY %s %s, %s %s", query, orderCol, order, noCol, order)
+	if f.Limit > 0 || f.Offset > 0 {
+		limit := -1
+		if f.Limit > 0 {
+			limit = f.Limit
+		}
+		args = append(args, limit, f.Offset)
+		query = fmt.Sprintf("%s LIMIT $%d OFFSET $%d", query, len(args)-1, len(args))
+	}
+	return query, args
+}
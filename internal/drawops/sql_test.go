@@ -0,0 +1,108 @@
+package drawops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectSQL(t *testing.T) {
+	testcases := []struct {
+		name      string
+		input     Filter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "all draws",
+			input:     Filter{},
+			wantQuery: "SELECT * FROM euro ORDER BY draw_date ASC, draw_no ASC",
+			wantArgs:  []any{},
+		},
+		{
+			name: "date range",
+			input: Filter{
+				From: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+			},
+			wantQuery: "SELECT * FROM euro WHERE draw_date >= $1 AND draw_date < $2 ORDER BY draw_date ASC, draw_no ASC",
+			wantArgs:  []any{"2026-01-01", "2026-02-01"},
+		},
+		{
+			name:      "last draws by draw number descending",
+			input:     Filter{Last: 5, Sort: SortByDrawNo, Desc: true},
+			wantQuery: "SELECT * FROM (SELECT * FROM euro ORDER BY draw_no DESC LIMIT $1) ORDER BY draw_no DESC, draw_no DESC",
+			wantArgs:  []any{5},
+		},
+		{
+			name:      "draw number range",
+			input:     Filter{FromDrawNo: 10, ToDrawNo: 20},
+			wantQuery: "SELECT * FROM euro WHERE draw_no >= $1 AND draw_no <= $2 ORDER BY draw_date ASC, draw_no ASC",
+			wantArgs:  []any{uint64(10), uint64(20)},
+		},
+		{
+			name:      "contains",
+			input:     Filter{Contains: []uint8{7, 9}},
+			wantQuery: "SELECT * FROM euro WHERE $1 IN (ball1, ball2) AND $2 IN (ball1, ball2) ORDER BY draw_date ASC, draw_no ASC",
+			wantArgs:  []any{uint8(7), uint8(9)},
+		},
+		{
+			name: "after cursor descending",
+			input: Filter{
+				After: Cursor{Date: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC), DrawNo: 3},
+				Desc:  true,
+				Limit: 10,
+			},
+			wantQuery: "SELECT * FROM euro WHERE (draw_date < $1 OR (draw_date = $1 AND draw_no < $2)) ORDER BY draw_date DESC, draw_no DESC LIMIT $3 OFFSET $4",
+			wantArgs:  []any{"2026-01-02", uint64(3), 10, 0},
+		},
+		{
+			name:      "offset without limit",
+			input:     Filter{Offset: 20},
+			wantQuery: "SELECT * FROM euro ORDER BY draw_date ASC, draw_no ASC LIMIT $1 OFFSET $2",
+			wantArgs:  []any{-1, 20},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotQuery, gotArgs := tc.input.SelectSQL("euro", "draw_date", "draw_no", "ball1", "ball2")
+			assert.Equal(t, tc.wantQuery, gotQuery)
+			assert.Equal(t, tc.wantArgs, gotArgs)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   Filter
+		wantErr error
+	}{
+		{name: "empty", input: Filter{}, wantErr: nil},
+		{name: "sort by draw number", input: Filter{Sort: SortByDrawNo}, wantErr: nil},
+		{name: "unknown sort", input: Filter{Sort: "ball"}, wantErr: ErrSortField},
+		{
+			name: "reversed range",
+			input: Filter{
+				From: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: ErrDateRange,
+		},
+		{name: "reversed draw range", input: Filter{FromDrawNo: 5, ToDrawNo: 4}, wantErr: ErrDrawRange},
+		{name: "zero ball", input: Filter{Contains: []uint8{0}}, wantErr: ErrBall},
+		{name: "negative offset", input: Filter{Offset: -1}, wantErr: ErrPage},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := tc.input.Validate()
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
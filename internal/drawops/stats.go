@@ -0,0 +1,114 @@
+package drawops
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Pool returns the size of the pool a draw was made from and the number of
+// balls drawn from it. A size of 0 excludes the draw.
+type Pool[D any] func(D) (size int, count int)
+
+// Gaps calculates the gaps between appearances of balls 1 to maxBall in
+// draws ordered by draw number. A draw only counts towards the gaps of the
+// balls in its pool.
+func Gaps[D any](draws []D, maxBall int, balls func(D) []uint8, pool Pool[D]) []Gap {
+	gaps := make([]Gap, maxBall)
+	appearances := make([]uint, maxBall)
+	totals := make([]uint, maxBall)
+	for i := range gaps {
+		gaps[i].Ball = uint(i + 1)
+	}
+
+	for _, d := range draws {
+		size, _ := pool(d)
+		drawn := balls(d)
+		for i := 0; i < size && i < maxBall; i++ {
+			if !slices.Contains(drawn, uint8(i+1)) {
+				gaps[i].Current++
+				continue
+			}
+			if appearances[i] > 0 {
+				totals[i] += gaps[i].Current
+				gaps[i].Longest = max(gaps[i].Longest, gaps[i].Current)
+			}
+			appearances[i]++
+			gaps[i].Current = 0
+		}
+	}
+
+	expected := 0.0
+	if len(draws) > 0 {
+		if size, count := pool(draws[len(draws)-1]); count > 0 {
+			expected = float64(size)/float64(count) - 1
+		}
+	}
+	for i := range gaps {
+		gaps[i].Longest = max(gaps[i].Longest, gaps[i].Current)
+		if appearances[i] > 1 {
+			gaps[i].Average = float64(totals[i]) / float64(appearances[i]-1)
+		}
+		gaps[i].Expected = expected
+	}
+	return gaps
+}
+
+// CalculateTrend groups draws ordered by date into periods and counts the
+// frequency of balls 1 to maxBall in each
+func CalculateTrend[D any](draws []D, maxBall int, period Period, date func(D) time.Time, balls func(D) []uint8) (Trend, error) {
+	layout, err := period.layout()
+	if err != nil {
+		return Trend{}, err
+	}
+
+	trend := Trend{Balls: make([]uint, maxBall), Periods: []PeriodFrequency{}}
+	for i := range trend.Balls {
+		trend.Balls[i] = uint(i + 1)
+	}
+	for _, d := range draws {
+		label := date(d).Format(layout)
+		if n := len(trend.Periods); n == 0 || trend.Periods[n-1].Period != label {
+			trend.Periods = append(trend.Periods, PeriodFrequency{Period: label, Frequency: make([]uint, maxBall)})
+		}
+		p := &trend.Periods[len(trend.Periods)-1]
+		p.Draws++
+		for _, b := range balls(d) {
+			if b >= 1 && int(b) <= maxBall {
+				p.Frequency[b-1]++
+			}
+		}
+	}
+	return trend, nil
+}
+
+// Select returns the trend of the given balls only
+func (t Trend) Select(balls ...uint) (Trend, error) {
+	index := make([]int, len(balls))
+	for i, b := range balls {
+		index[i] = slices.Index(t.Balls, b)
+		if index[i] < 0 {
+			return Trend{}, fmt.Errorf("%w: %d", ErrBall, b)
+		}
+	}
+
+	selected := Trend{Balls: slices.Clone(balls), Periods: make([]PeriodFrequency, len(t.Periods))}
+	for i, p := range t.Periods {
+		selected.Periods[i] = PeriodFrequency{Period: p.Period, Draws: p.Draws, Frequency: make([]uint, len(index))}
+		for j, k := range index {
+			selected.Periods[i].Frequency[j] = p.Frequency[k]
+		}
+	}
+	return selected, nil
+}
+
+func (p Period) layout() (string, error) {
+	switch p {
+	case Month:
+		return "2006-01", nil
+	case Year:
+		return "2006", nil
+	default:
+		return "", fmt.Errorf("%w: %s, expected %s or %s", ErrPeriod, p, Month, Year)
+	}
+}
@@ -0,0 +1,145 @@
+package drawops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDraw struct {
+	date  time.Time
+	balls []uint8
+	pool  int
+}
+
+func testBalls(d testDraw) []uint8 { return d.balls }
+
+func testDate(d testDraw) time.Time { return d.date }
+
+func testPool(d testDraw) (int, int) { return d.pool, 1 }
+
+func TestGaps(t *testing.T) {
+	testcases := []struct {
+		name  string
+		draws []testDraw
+		want  []Gap
+	}{
+		{
+			name: "gaps within a pool",
+			draws: []testDraw{
+				{balls: []uint8{1}, pool: 2},
+				{balls: []uint8{2}, pool: 2},
+				{balls: []uint8{2}, pool: 2},
+				{balls: []uint8{1}, pool: 2},
+				{balls: []uint8{2}, pool: 2},
+			},
+			want: []Gap{
+				{Ball: 1, Current: 1, Longest: 2, Average: 2, Expected: 1},
+				{Ball: 2, Current: 0, Longest: 1, Average: 0.5, Expected: 1},
+			},
+		},
+		{
+			name: "ball outside the pool of earlier draws",
+			draws: []testDraw{
+				{balls: []uint8{1}, pool: 1},
+				{balls: []uint8{1}, pool: 1},
+				{balls: []uint8{1}, pool: 2},
+			},
+			want: []Gap{
+				{Ball: 1, Current: 0, Longest: 0, Average: 0, Expected: 1},
+				{Ball: 2, Current: 1, Longest: 1, Average: 0, Expected: 1},
+			},
+		},
+		{
+			name:  "no draws",
+			draws: nil,
+			want: []Gap{
+				{Ball: 1},
+				{Ball: 2},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Gaps(tc.draws, 2, testBalls, testPool)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCalculateTrend(t *testing.T) {
+	draws := []testDraw{
+		{date: time.Date(2025, time.December, 3, 0, 0, 0, 0, time.UTC), balls: []uint8{1, 2}},
+		{date: time.Date(2026, time.January, 7, 0, 0, 0, 0, time.UTC), balls: []uint8{2, 3}},
+		{date: time.Date(2026, time.January, 14, 0, 0, 0, 0, time.UTC), balls: []uint8{3, 1}},
+	}
+
+	testcases := []struct {
+		name   string
+		period Period
+		want   Trend
+		err    error
+	}{
+		{
+			name:   "by month",
+			period: Month,
+			want: Trend{
+				Balls: []uint{1, 2, 3},
+				Periods: []PeriodFrequency{
+					{Period: "2025-12", Draws: 1, Frequency: []uint{1, 1, 0}},
+					{Period: "2026-01", Draws: 2, Frequency: []uint{1, 1, 2}},
+				},
+			},
+		},
+		{
+			name:   "by year",
+			period: Year,
+			want: Trend{
+				Balls: []uint{1, 2, 3},
+				Periods: []PeriodFrequency{
+					{Period: "2025", Draws: 1, Frequency: []uint{1, 1, 0}},
+					{Period: "2026", Draws: 2, Frequency: []uint{1, 1, 2}},
+				},
+			},
+		},
+		{
+			name:   "invalid period",
+			period: "week",
+			err:    ErrPeriod,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CalculateTrend(draws, 3, tc.period, testDate, testBalls)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.err, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTrendSelect(t *testing.T) {
+	trend := Trend{
+		Balls:   []uint{1, 2, 3},
+		Periods: []PeriodFrequency{{Period: "2026", Draws: 2, Frequency: []uint{1, 1, 2}}},
+	}
+
+	got, err := trend.Select(3, 1)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, Trend{
+		Balls:   []uint{3, 1},
+		Periods: []PeriodFrequency{{Period: "2026", Draws: 2, Frequency: []uint{2, 1}}},
+	}, got)
+
+	_, err = trend.Select(4)
+	if !errors.Is(err, ErrBall) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrBall, err)
+	}
+}
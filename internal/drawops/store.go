@@ -0,0 +1,114 @@
+package drawops
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// SelectDraws returns the draws selected and ordered by the filter, as
+// SelectSQL does for a table. date, drawNo and balls return the draw date,
+// draw number and main balls of a draw.
+func SelectDraws[D any](draws []D, f Filter, date func(D) time.Time, drawNo func(D) uint64, balls func(D) []uint8) []D {
+	from, to := "", ""
+	if !f.From.IsZero() {
+		from = f.From.UTC().Format(time.DateOnly)
+	}
+	if !f.To.IsZero() {
+		to = f.To.UTC().Format(time.DateOnly)
+	}
+	selected := []D{}
+	for _, d := range draws {
+		day := date(d).Format(time.DateOnly)
+		if (from != "" && day < from) || (to != "" && day > to) {
+			continue
+		}
+		if (f.FromDrawNo > 0 && drawNo(d) < f.FromDrawNo) || (f.ToDrawNo > 0 && drawNo(d) > f.ToDrawNo) {
+			continue
+		}
+		if !containsAll(balls(d), f.Contains) {
+			continue
+		}
+		selected = append(selected, d)
+	}
+
+	if f.Last > 0 && len(selected) > f.Last {
+		slices.SortFunc(selected, func(a, b D) int {
+			return cmp.Compare(drawNo(b), drawNo(a))
+		})
+		selected = selected[:f.Last]
+	}
+
+	compare := func(a, b D) int {
+		c := cmp.Compare(drawNo(a), drawNo(b))
+		if f.Sort != SortByDrawNo {
+			c = cmp.Or(cmp.Compare(date(a).Format(time.DateOnly), date(b).Format(time.DateOnly)), c)
+		}
+		if f.Desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(selected, compare)
+
+	if !f.After.IsZero() {
+		after := func(d D) bool {
+			c := cmp.Compare(drawNo(d), f.After.DrawNo)
+			if f.Sort != SortByDrawNo {
+				c = cmp.Or(cmp.Compare(date(d).Format(time.DateOnly), f.After.Date.UTC().Format(time.DateOnly)), c)
+			}
+			if f.Desc {
+				return c < 0
+			}
+			return c > 0
+		}
+		selected = slices.DeleteFunc(selected, func(d D) bool { return !after(d) })
+	}
+
+	selected = selected[min(f.Offset, len(selected)):]
+	if f.Limit > 0 && len(selected) > f.Limit {
+		selected = selected[:f.Limit]
+	}
+	return selected
+}
+
+// containsAll reports whether balls contains every ball of want
+func containsAll(balls []uint8, want []uint8) bool {
+	for _, b := range want {
+		if !slices.Contains(balls, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckBalls verifies the balls are distinct and from 1 to maxBall, as the
+// tables of draws require
+func CheckBalls(balls []uint8, maxBall int) error {
+	for i, b := range balls {
+		if b < 1 || int(b) > maxBall {
+			return fmt.Errorf("%w: ball %d not in 1 to %d", ErrRefused, b, maxBall)
+		}
+		if slices.Contains(balls[i+1:], b) {
+			return fmt.Errorf("%w: ball %d repeated", ErrRefused, b)
+		}
+	}
+	return nil
+}
+
+// CountBalls returns the number of draws containing each ball from 1 to
+// maxBall, indexed from 0 for ball 1, as the frequency queries of the tables
+// of draws count them
+func CountBalls[D any](draws []D, maxBall int, balls func(D) []uint8) []uint {
+	counts := make([]uint, maxBall)
+	for _, d := range draws {
+		drawn := balls(d)
+		for i, b := range drawn {
+			if b >= 1 && int(b) <= maxBall && !slices.Contains(drawn[:i], b) {
+				counts[b-1]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,86 @@
+package drawops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type storeDraw struct {
+	no    uint64
+	date  time.Time
+	balls []uint8
+}
+
+func storeDate(d storeDraw) time.Time { return d.date }
+
+func storeDrawNo(d storeDraw) uint64 { return d.no }
+
+func storeBalls(d storeDraw) []uint8 { return d.balls }
+
+func TestSelectDraws(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.January, d, 0, 0, 0, 0, time.UTC) }
+	draws := []storeDraw{
+		{no: 3, date: day(3), balls: []uint8{1, 2}},
+		{no: 1, date: day(5), balls: []uint8{2, 3}},
+		{no: 2, date: day(2), balls: []uint8{3, 4}},
+	}
+
+	testcases := []struct {
+		name  string
+		input Filter
+		want  []uint64
+	}{
+		{name: "all draws", input: Filter{}, want: []uint64{2, 3, 1}},
+		{name: "date range", input: Filter{From: day(3), To: day(5)}, want: []uint64{3, 1}},
+		{name: "sort by draw number descending", input: Filter{Sort: SortByDrawNo, Desc: true}, want: []uint64{3, 2, 1}},
+		{name: "last draws", input: Filter{Last: 2}, want: []uint64{2, 3}},
+		{name: "draw number range", input: Filter{FromDrawNo: 2, ToDrawNo: 3}, want: []uint64{2, 3}},
+		{name: "contains", input: Filter{Contains: []uint8{2}}, want: []uint64{3, 1}},
+		{name: "contains all", input: Filter{Contains: []uint8{2, 3}}, want: []uint64{1}},
+		{name: "after cursor", input: Filter{After: Cursor{Date: day(2), DrawNo: 2}}, want: []uint64{3, 1}},
+		{name: "after cursor by draw number", input: Filter{Sort: SortByDrawNo, After: Cursor{DrawNo: 1}}, want: []uint64{2, 3}},
+		{name: "offset and limit", input: Filter{Offset: 1, Limit: 1}, want: []uint64{3}},
+		{name: "offset only", input: Filter{Offset: 2}, want: []uint64{1}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []uint64{}
+			for _, d := range SelectDraws(draws, tc.input, storeDate, storeDrawNo, storeBalls) {
+				got = append(got, d.no)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCheckBalls(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   []uint8
+		wantErr error
+	}{
+		{name: "valid", input: []uint8{1, 2, 5}, wantErr: nil},
+		{name: "zero", input: []uint8{0, 2}, wantErr: ErrRefused},
+		{name: "above max", input: []uint8{1, 6}, wantErr: ErrRefused},
+		{name: "repeated", input: []uint8{2, 3, 2}, wantErr: ErrRefused},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckBalls(tc.input, 5)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCountBalls(t *testing.T) {
+	draws := [][]uint8{{1, 2}, {2, 3}, {3, 3}}
+	got := CountBalls(draws, 3, func(d []uint8) []uint8 { return d })
+	assert.Equal(t, []uint{1, 2, 2}, got)
+}
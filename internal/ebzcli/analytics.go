@@ -0,0 +1,126 @@
+package ebzcli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrFreqSort = errors.New("invalid frequency sort")
+	ErrDateFlag = errors.New("invalid date flag")
+)
+
+const (
+	freqSortBall = "ball"
+	freqSortFreq = "freq"
+)
+
+// freqOpts are the flags of frequency commands
+type freqOpts struct {
+	sort  string
+	desc  bool
+	chart bool
+}
+
+func addFreqFlags(cmd *cobra.Command, opts *freqOpts) {
+	cmd.Flags().StringVar(&opts.sort, "sort", freqSortBall, "Sort by ball or freq")
+	cmd.Flags().BoolVar(&opts.desc, "desc", false, "Sort in descending order")
+	cmd.Flags().BoolVar(&opts.chart, "chart", false, "Draw a bar chart instead of the output format")
+}
+
+// sortFreqs orders frequencies by ball or frequency. Balls with the same
+// frequency are ordered by ball.
+func sortFreqs[T any](freqs []T, opts freqOpts, ball func(T) uint, freq func(T) uint) error {
+	var compare func(a, b T) int
+	switch opts.sort {
+	case freqSortBall:
+		compare = func(a, b T) int { return cmp.Compare(ball(a), ball(b)) }
+	case freqSortFreq:
+		compare = func(a, b T) int {
+			return cmp.Or(cmp.Compare(freq(a), freq(b)), cmp.Compare(ball(a), ball(b)))
+		}
+	default:
+		return fmt.Errorf("%w: %s, expected %s or %s", ErrFreqSort, opts.sort, freqSortBall, freqSortFreq)
+	}
+	slices.SortStableFunc(freqs, func(a, b T) int {
+		if opts.desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return nil
+}
+
+// drawsOpts are the flags of the draws commands
+type drawsOpts struct {
+	last int
+	from string
+	to   string
+	sort string
+	desc bool
+}
+
+func addDrawsFlags(cmd *cobra.Command, opts *drawsOpts) {
+	cmd.Flags().IntVar(&opts.last, "last", 0, "Show the last N draws only")
+	cmd.Flags().StringVar(&opts.from, "from", "", "Earliest draw date YYYY-MM-DD")
+	cmd.Flags().StringVar(&opts.to, "to", "", "Latest draw date YYYY-MM-DD")
+	cmd.Flags().StringVar(&opts.sort, "sort", string(drawops.SortByDate), "Sort by date or draw_no")
+	cmd.Flags().BoolVar(&opts.desc, "desc", false, "Sort in descending order")
+}
+
+// filter converts the flags to a draw filter
+func (o drawsOpts) filter() (drawops.Filter, error) {
+	f := drawops.Filter{
+		Last: o.last,
+		Sort: drawops.SortField(o.sort),
+		Desc: o.desc,
+	}
+	var err error
+	if o.from != "" {
+		if f.From, err = time.Parse(time.DateOnly, o.from); err != nil {
+			return f, fmt.Errorf("%w: from %s", ErrDateFlag, o.from)
+		}
+	}
+	if o.to != "" {
+		if f.To, err = time.Parse(time.DateOnly, o.to); err != nil {
+			return f, fmt.Errorf("%w: to %s", ErrDateFlag, o.to)
+		}
+	}
+	return f, f.Validate()
+}
+
+// gapsOpts are the flags of the gap commands
+type gapsOpts struct {
+	chart bool
+}
+
+func addGapsFlags(cmd *cobra.Command, opts *gapsOpts) {
+	cmd.Flags().BoolVar(&opts.chart, "chart", false, "Draw a bar chart of current gaps instead of the output format")
+}
+
+// trendOpts are the flags of the trend commands
+type trendOpts struct {
+	period string
+	balls  []uint
+	chart  bool
+}
+
+func addTrendFlags(cmd *cobra.Command, opts *trendOpts) {
+	cmd.Flags().StringVar(&opts.period, "period", string(drawops.Month), "Group draws by month or year")
+	cmd.Flags().UintSliceVar(&opts.balls, "ball", nil, "Show the trend of these balls only")
+	cmd.Flags().BoolVar(&opts.chart, "chart", false, "Draw sparklines of selected balls or a heatmap of all balls instead of the output format")
+}
+
+// selectBalls restricts the trend to the balls of the flags, if any
+func (o trendOpts) selectBalls(trend drawops.Trend) (drawops.Trend, error) {
+	if len(o.balls) == 0 {
+		return trend, nil
+	}
+	return trend.Select(o.balls...)
+}
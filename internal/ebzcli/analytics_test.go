@@ -0,0 +1,88 @@
+package ebzcli
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFreq struct {
+	ball uint
+	freq uint
+}
+
+func TestSortFreqs(t *testing.T) {
+	testcases := []struct {
+		name    string
+		opts    freqOpts
+		want    []uint
+		wantErr error
+	}{
+		{name: "ball", opts: freqOpts{sort: freqSortBall}, want: []uint{1, 2, 3}},
+		{name: "ball descending", opts: freqOpts{sort: freqSortBall, desc: true}, want: []uint{3, 2, 1}},
+		{name: "frequency", opts: freqOpts{sort: freqSortFreq}, want: []uint{3, 1, 2}},
+		{name: "frequency descending", opts: freqOpts{sort: freqSortFreq, desc: true}, want: []uint{2, 1, 3}},
+		{name: "unknown", opts: freqOpts{sort: "expected"}, want: []uint{2, 3, 1}, wantErr: ErrFreqSort},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			freqs := []testFreq{{ball: 2, freq: 5}, {ball: 3, freq: 1}, {ball: 1, freq: 5}}
+			gotErr := sortFreqs(freqs, tc.opts,
+				func(f testFreq) uint { return f.ball },
+				func(f testFreq) uint { return f.freq })
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			got := []uint{}
+			for _, f := range freqs {
+				got = append(got, f.ball)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDrawsOptsFilter(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   drawsOpts
+		want    drawops.Filter
+		wantErr error
+	}{
+		{
+			name:  "date range",
+			input: drawsOpts{from: "2026-01-01", to: "2026-01-31", sort: "date"},
+			want: drawops.Filter{
+				From: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+				Sort: drawops.SortByDate,
+			},
+		},
+		{
+			name:    "invalid date",
+			input:   drawsOpts{from: "01-01-2026", sort: "date"},
+			wantErr: ErrDateFlag,
+		},
+		{
+			name:    "invalid sort",
+			input:   drawsOpts{sort: "ball"},
+			wantErr: drawops.ErrSortField,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := tc.input.filter()
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
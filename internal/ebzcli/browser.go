@@ -16,10 +16,11 @@ var (
 )
 
 var (
-	localhostRegex = regexp.MustCompile(`^http://localhost:[0-9]{1,5}$`)
+	localhostRegex = regexp.MustCompile(`^https?://localhost:[0-9]{1,5}$`)
 )
 
-// raw url can only be http://localhost:<port number>
+// raw url can only be http://localhost:<port number> or
+// https://localhost:<port number>
 func getBrowserCommand(goos, rawUrl string) (string, []string, error) {
 	if _, err := url.ParseRequestURI(rawUrl); err != nil {
 		return "", nil, fmt.Errorf("%w:%w", ErrBadlyFormattedURL, err)
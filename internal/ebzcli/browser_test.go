@@ -32,6 +32,14 @@ var (
 				wantArgs: []string{"url.dll,FileProtocolHandler", "http://localhost:8080"},
 				wantErr:  nil,
 			},
+			{
+				name:     "HTTPS",
+				goos:     "linux",
+				rawUrl:   "https://localhost:8443",
+				wantCmd:  "xdg-open",
+				wantArgs: []string{"https://localhost:8443"},
+				wantErr:  nil,
+			},
 			{
 				name:     "Linux",
 				goos:     "linux",
@@ -75,6 +83,11 @@ var (
 				rawUrl:  "http://localhos:1234",
 				wantErr: ErrBadlyFormattedURL,
 			},
+			{
+				name:    "Not http or https",
+				rawUrl:  "ftp://localhost:1234",
+				wantErr: ErrBadlyFormattedURL,
+			},
 			{
 				name:    "Missing port number",
 				rawUrl:  "http://localhost",
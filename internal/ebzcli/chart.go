@@ -0,0 +1,106 @@
+package ebzcli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/paulwizviz/lotterystat/internal/chartops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"golang.org/x/term"
+)
+
+// defaultWidth is the chart width when the width of the terminal is unknown
+const defaultWidth = 80
+
+// terminalWidth returns the number of columns of the terminal on stdout,
+// falling back to the COLUMNS environment variable and then defaultWidth
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultWidth
+}
+
+// freqBars converts frequencies to bars labelled by ball and marked with the
+// expected frequency
+func freqBars[T any](freqs []T, ball func(T) uint, freq func(T) uint, expected func(T) float64) []chartops.Bar {
+	bars := make([]chartops.Bar, len(freqs))
+	for i, f := range freqs {
+		bars[i] = chartops.Bar{Label: fmt.Sprint(ball(f)), Value: float64(freq(f)), Expected: expected(f)}
+	}
+	return bars
+}
+
+// gapBars converts gaps to bars of the current gap marked with the expected gap
+func gapBars(gaps []drawops.Gap) []chartops.Bar {
+	bars := make([]chartops.Bar, len(gaps))
+	for i, g := range gaps {
+		bars[i] = chartops.Bar{Label: fmt.Sprint(g.Ball), Value: float64(g.Current), Expected: g.Expected}
+	}
+	return bars
+}
+
+// writeTrendChart writes a sparkline per ball when balls are selected,
+// otherwise a heatmap of every ball by period
+func writeTrendChart(w io.Writer, trend drawops.Trend, selected bool, width int) error {
+	if len(trend.Periods) == 0 {
+		return drawops.ErrNoDraw
+	}
+
+	rows := make([]string, len(trend.Balls))
+	values := make([][]float64, len(trend.Balls))
+	for i, b := range trend.Balls {
+		rows[i] = fmt.Sprint(b)
+		values[i] = make([]float64, len(trend.Periods))
+		for j, p := range trend.Periods {
+			values[i][j] = float64(p.Frequency[i])
+		}
+	}
+	if !selected {
+		cols := make([]string, len(trend.Periods))
+		for j, p := range trend.Periods {
+			cols[j] = p.Period
+		}
+		return chartops.Heatmap(w, rows, cols, values, width)
+	}
+
+	// Sparklines cover the last periods fitting the width after the labels
+	labelWidth := 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, len(r))
+	}
+	visible := width - labelWidth - 1
+	if visible < 1 {
+		return fmt.Errorf("%w: %d columns", chartops.ErrWidth, width)
+	}
+	skip := max(0, len(trend.Periods)-visible)
+	first, last := trend.Periods[skip].Period, trend.Periods[len(trend.Periods)-1].Period
+	if _, err := fmt.Fprintf(w, "%*s %s..%s\n", labelWidth, "", first, last); err != nil {
+		return err
+	}
+	for i, r := range rows {
+		if _, err := fmt.Fprintf(w, "%*s %s\n", labelWidth, r, chartops.Sparkline(values[i][skip:])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawBarChart writes bars to stdout at the terminal width
+func drawBarChart(bars []chartops.Bar) {
+	if err := chartops.BarChart(os.Stdout, bars, terminalWidth()); err != nil {
+		fatal("unable to draw chart", err)
+	}
+}
+
+// drawTrendChart writes the trend to stdout at the terminal width
+func drawTrendChart(trend drawops.Trend, selected bool) {
+	if err := writeTrendChart(os.Stdout, trend, selected, terminalWidth()); err != nil {
+		fatal("unable to draw chart", err)
+	}
+}
@@ -0,0 +1,43 @@
+package ebzcli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTrendChart(t *testing.T) {
+	trend := drawops.Trend{
+		Balls: []uint{7, 23},
+		Periods: []drawops.PeriodFrequency{
+			{Period: "2025", Draws: 4, Frequency: []uint{0, 2}},
+			{Period: "2026", Draws: 4, Frequency: []uint{3, 1}},
+		},
+	}
+
+	testcases := []struct {
+		name     string
+		trend    drawops.Trend
+		selected bool
+		want     string
+		wantErr  error
+	}{
+		{name: "sparklines", trend: trend, selected: true, want: "   2025..2026\n 7 ▁█\n23 █▁\n"},
+		{name: "heatmap", trend: trend, selected: false, want: "   2025 2026\n 7  █\n23 ▓░\n"},
+		{name: "no draws", trend: drawops.Trend{}, wantErr: drawops.ErrNoDraw},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeTrendChart(&buf, tc.trend, tc.selected, 40)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
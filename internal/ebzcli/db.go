@@ -0,0 +1,342 @@
+package ebzcli
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrNotConfirmed = errors.New("not confirmed")
+)
+
+var (
+	dbBackupOut     string
+	dbRestoreFile   string
+	dbRestoreYes    bool
+	dbResetGame     string
+	dbResetYes      bool
+	dbMigrateTo     int
+	dbMigrateStatus bool
+)
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbVacuumCmd)
+	dbCmd.AddCommand(dbIntegrityCmd)
+	dbCmd.AddCommand(dbResetCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbBackupCmd.Flags().StringVar(&dbBackupOut, "out", "", "File to write the backup to (default a timestamped file in backup_dir of ebz.yaml)")
+	dbRestoreCmd.Flags().StringVarP(&dbRestoreFile, "file", "f", "", "Backup file to restore")
+	dbRestoreCmd.Flags().BoolVarP(&dbRestoreYes, "yes", "y", false, "Restore without asking for confirmation")
+	dbRestoreCmd.MarkFlagRequired("file")
+	dbResetCmd.Flags().StringVar(&dbResetGame, "game", "", "Game to reset tball, euro, lotto or sflife")
+	dbResetCmd.Flags().BoolVarP(&dbResetYes, "yes", "y", false, "Reset without asking for confirmation")
+	dbResetCmd.MarkFlagRequired("game")
+	dbMigrateCmd.Flags().IntVar(&dbMigrateTo, "to", 0, "Version to migrate every schema to (default the latest)")
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateStatus, "status", false, "Show the migrations and when they were applied without migrating")
+}
+
+// dbResult reports a file written or checked by a database command
+type dbResult struct {
+	Action string `json:"action"`
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// dbResults report the outcome of a database command
+type dbResults []dbResult
+
+func (r dbResults) Header() []string {
+	return []string{"action", "file", "size", "detail"}
+}
+
+func (r dbResults) Rows() [][]string {
+	rows := [][]string{}
+	for _, d := range r {
+		rows = append(rows, []string{d.Action, d.File, fmt.Sprint(d.Size), d.Detail})
+	}
+	return rows
+}
+
+// migrationStatuses report the migrations of the database schemas
+type migrationStatuses []sqlops.MigrationStatus
+
+func (m migrationStatuses) Header() []string {
+	return []string{"schema", "version", "description", "applied_at"}
+}
+
+func (m migrationStatuses) Rows() [][]string {
+	rows := [][]string{}
+	for _, s := range m {
+		applied := ""
+		if s.Applied() {
+			applied = s.AppliedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{s.Schema, fmt.Sprint(s.Version), s.Description, applied})
+	}
+	return rows
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "manage the lottery database",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "write a consistent snapshot of the database while it is in use",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+
+		out := dbBackupOut
+		if out == "" {
+			out = backupPath(time.Now())
+		}
+		result, err := backupDatabase(context.Background(), db, out)
+		if err != nil {
+			fatal("unable to back up database", err)
+		}
+		renderOutput(dbResults{result})
+	},
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore -f <backup>",
+	Short: "replace the database with a backup, after backing up the database",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbFile := ebzconfig.AppConfig.DatabasePath
+		if !dbRestoreYes && !confirm(os.Stdin, os.Stderr, fmt.Sprintf("Replace %s with %s?", dbFile, dbRestoreFile)) {
+			fatal("cancelled", ErrNotConfirmed)
+		}
+
+		ctx := context.Background()
+		db := openDatabase()
+		backup, err := backupDatabase(ctx, db, backupPath(time.Now()))
+		db.Close()
+		if err != nil {
+			fatal("unable to back up database", err)
+		}
+		if err := sqlops.Restore(ctx, dbRestoreFile, dbFile); err != nil {
+			fatal("unable to restore database", err)
+		}
+		renderOutput(dbResults{backup, {Action: "restore", File: dbFile, Size: fileSize(dbFile), Detail: "from " + dbRestoreFile}})
+	},
+}
+
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "rebuild the database to reclaim unused space",
+	Run: func(cmd *cobra.Command, args []string) {
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+
+		dbFile := ebzconfig.AppConfig.DatabasePath
+		before := fileSize(dbFile)
+		if err := sqlops.Vacuum(context.Background(), db); err != nil {
+			fatal("unable to vacuum database", err)
+		}
+		after := fileSize(dbFile)
+		renderOutput(dbResults{{Action: "vacuum", File: dbFile, Size: after, Detail: fmt.Sprintf("reclaimed %d bytes", before-after)}})
+	},
+}
+
+var dbIntegrityCmd = &cobra.Command{
+	Use:   "integrity",
+	Short: "check the integrity of the database",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+
+		dbFile := ebzconfig.AppConfig.DatabasePath
+		msgs, err := sqlops.IntegrityCheck(context.Background(), db)
+		if msgs == nil && err != nil {
+			fatal("unable to check database", err)
+		}
+		results := dbResults{}
+		for _, msg := range msgs {
+			results = append(results, dbResult{Action: "integrity", File: dbFile, Size: fileSize(dbFile), Detail: msg})
+		}
+		renderOutput(results)
+		if err != nil {
+			db.Close()
+			fatal("database integrity check failed", err)
+		}
+	},
+}
+
+var dbResetCmd = &cobra.Command{
+	Use:   "reset --game <game>",
+	Short: "delete every stored draw of a game, after backing up the database",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isGame(dbResetGame) {
+			fatal("invalid game", fmt.Errorf("%w: %s, expected tball, euro, lotto or sflife", ErrGame, dbResetGame))
+		}
+		dbFile := ebzconfig.AppConfig.DatabasePath
+		if !dbResetYes && !confirm(os.Stdin, os.Stderr, fmt.Sprintf("Delete every %s draw from %s?", dbResetGame, dbFile)) {
+			fatal("cancelled", ErrNotConfirmed)
+		}
+
+		ctx := context.Background()
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+
+		backup, err := backupDatabase(ctx, db, backupPath(time.Now()))
+		if err != nil {
+			fatal("unable to back up database", err)
+		}
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+		deleted, err := deleteDraws(ctx, stores, dbResetGame)
+		if err != nil {
+			db.Close()
+			fatal("unable to reset draws", err, "game", dbResetGame)
+		}
+		renderOutput(dbResults{backup, {Action: "reset", File: dbFile, Size: fileSize(dbFile), Detail: fmt.Sprintf("deleted %d %s draws", deleted, dbResetGame)}})
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "migrate the database schemas, or show their migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		db := openDatabase()
+		defer db.Close()
+
+		if !dbMigrateStatus {
+			to := dbMigrateTo
+			if to == 0 {
+				to = sqlops.Latest
+			}
+			release, err := ebzconfig.LockMigrations(ctx, ebzconfig.AppConfig.DatabasePath)
+			if err != nil {
+				db.Close()
+				fatal("unable to lock migrations", err)
+			}
+			_, err = sqlops.Migrate(ctx, db, to, ebzconfig.Schemas...)
+			release()
+			if err != nil {
+				db.Close()
+				fatal("unable to migrate database", err)
+			}
+		}
+		statuses, err := sqlops.MigrationStatuses(ctx, db, ebzconfig.Schemas...)
+		if err != nil {
+			db.Close()
+			fatal("unable to read migrations", err)
+		}
+		renderOutput(migrationStatuses(statuses))
+	},
+}
+
+// openDatabase opens the configured database, exiting on failure
+func openDatabase() *sql.DB {
+	db, err := ebzconfig.OpenDatabase()
+	if err != nil {
+		fatal("unable to open database", err)
+	}
+	return db
+}
+
+// releaseDatabase releases the hold of holdDatabase, if taken
+var releaseDatabase = func() error { return nil }
+
+// holdDatabase holds the database against restores while a command serves
+// or writes it, until releaseDatabase is called or the command exits through
+// fatal
+func holdDatabase() {
+	release, err := ebzconfig.HoldDatabase(context.Background(), ebzconfig.AppConfig.DatabasePath)
+	if err != nil {
+		fatal("unable to hold database", err)
+	}
+	releaseDatabase = release
+}
+
+// backupPath returns the file of an automatic backup taken at t
+func backupPath(t time.Time) string {
+	return filepath.Join(ebzconfig.AppConfig.BackupDir, fmt.Sprintf("lottery-%s.db", t.Format("20060102-150405.000")))
+}
+
+// backupDatabase writes a snapshot of the database to file, creating its
+// directory if needed
+func backupDatabase(ctx context.Context, db *sql.DB, file string) (dbResult, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return dbResult{}, fmt.Errorf("%w: %w", sqlops.ErrBackup, err)
+	}
+	if err := sqlops.Backup(ctx, db, file); err != nil {
+		return dbResult{}, err
+	}
+	return dbResult{Action: "backup", File: file, Size: fileSize(file)}, nil
+}
+
+// deleteDraws deletes every stored draw of the game
+func deleteDraws(ctx context.Context, stores ebzstore.Stores, game string) (int64, error) {
+	switch game {
+	case "tball":
+		return stores.TBall.DeleteAllDraws(ctx)
+	case "euro":
+		return stores.Euro.DeleteAllDraws(ctx)
+	case "lotto":
+		return stores.Lotto.DeleteAllDraws(ctx)
+	case "sflife":
+		return stores.SFLife.DeleteAllDraws(ctx)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrGame, game)
+	}
+}
+
+// isGame reports whether name is the name of a game
+func isGame(name string) bool {
+	for _, g := range games {
+		if g.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm writes the prompt to w and reports whether the answer read from r
+// is yes
+func confirm(r io.Reader, w io.Writer, prompt string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// fileSize returns the size of a file in bytes, or 0 if it cannot be read
+func fileSize(file string) int64 {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
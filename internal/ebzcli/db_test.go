@@ -0,0 +1,83 @@
+package ebzcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	testcases := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: " yes ", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+	}
+	for _, tc := range testcases {
+		var w bytes.Buffer
+		assert.Equal(t, tc.want, confirm(strings.NewReader(tc.input), &w, "Reset?"), tc.input)
+		assert.Equal(t, "Reset? [y/N] ", w.String())
+	}
+}
+
+func TestBackupAndDeleteDraws(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, euro.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+	d := euro.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Friday, Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1922}
+	if err := euro.PersistsDraw(ctx, db, d); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "backup", "lottery.db")
+	result, err := backupDatabase(ctx, db, file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, "backup", result.Action)
+	assert.Equal(t, file, result.File)
+	assert.Positive(t, result.Size)
+
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	deleted, err := deleteDraws(ctx, stores, "euro")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	if _, err := deleteDraws(ctx, stores, "keno"); !errors.Is(err, ErrGame) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrGame, err)
+	}
+	assert.True(t, isGame("sflife"))
+	assert.False(t, isGame("keno"))
+}
+
+func TestMigrationStatuses(t *testing.T) {
+	statuses := migrationStatuses{
+		{Schema: "euro", Version: 1, Description: "create euro table", AppliedAt: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)},
+		{Schema: "euro", Version: 2, Description: "index draw_date"},
+	}
+	assert.Equal(t, [][]string{
+		{"euro", "1", "create euro table", "2026-10-19T12:00:00Z"},
+		{"euro", "2", "index draw_date", ""},
+	}, statuses.Rows())
+}
@@ -1,38 +1,95 @@
 package ebzcli
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
+	"os"
 
 	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzrender"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/spf13/cobra"
 )
 
-var start bool
+var (
+	start     bool
+	readOnly  bool
+	output    string
+	logLevel  string
+	logFormat string
+)
 
 func init() {
 	err := ebzconfig.Initialize()
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to load config", logops.Err(err))
 	}
-	rootCmd.Flags().BoolVarP(&start, "start", "s", false, "Start the frontend web server")
+	rootCmd.Flags().BoolVarP(&start, "start", "s", false, "Start the frontend web server as configured in ebz.yaml, see ebz serve")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "Start the web server with uploads and edits of draws disabled")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", string(ebzrender.Table), "Output format table, json, ndjson, csv or yaml")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Log level debug, info, warn or error (default log_level of ebz.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format text or json (default log_format of ebz.yaml)")
 }
 
 var rootCmd = &cobra.Command{
 	Use:   "ebz",
 	Short: "ebz is a cli app to help you analyze UK National Lottery results.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		_, err := ebzrender.ParseFormat(output)
+		return err
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if start {
-			port := availablePort()
-			rawUrl := fmt.Sprintf("http://localhost:%d", port)
-			openBrowser(rawUrl)
-			runWebserver(port)
+			cfg := configuredServer()
+			cfg.readOnly = readOnly
+			runWebserver(cfg)
 			return
 		}
 		cmd.Help()
 	},
 }
 
+// setupLogging logs to stderr at the level and in the format of the flags,
+// or else of ebz.yaml
+func setupLogging() error {
+	level, format := ebzconfig.AppConfig.LogLevel, ebzconfig.AppConfig.LogFormat
+	if logLevel != "" {
+		level = logLevel
+	}
+	if logFormat != "" {
+		format = logFormat
+	}
+	if level == "" {
+		level = logops.DefaultLevel
+	}
+	if format == "" {
+		format = string(logops.DefaultFormat)
+	}
+	logger, err := logops.New(os.Stderr, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// fatal logs the message with the error and attributes at error level,
+// releases the hold of the database, if taken, and exits
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append([]any{logops.Err(err)}, args...)...)
+	releaseDatabase()
+	os.Exit(1)
+}
+
+// renderOutput writes v to stdout in the format of the output flag
+func renderOutput(v any) {
+	if err := ebzrender.Render(os.Stdout, ebzrender.Format(output), v); err != nil {
+		fatal("unable to render output", err)
+	}
+}
+
 func Execute() error {
 	rootCmd.AddCommand(tballCmd)
 	rootCmd.AddCommand(euroCmd)
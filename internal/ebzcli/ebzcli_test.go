@@ -0,0 +1,50 @@
+package ebzcli
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupLogging(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer func(cfg ebzconfig.Configuration) { ebzconfig.AppConfig = cfg }(ebzconfig.AppConfig)
+	defer func() { logLevel, logFormat = "", "" }()
+
+	testcases := []struct {
+		name      string
+		cfgLevel  string
+		flagLevel string
+		flagFmt   string
+		wantDebug bool
+		wantErr   error
+	}{
+		{name: "config", cfgLevel: "debug", wantDebug: true},
+		{name: "flag overrides config", cfgLevel: "debug", flagLevel: "warn"},
+		{name: "defaults", wantDebug: false},
+		{name: "invalid level", flagLevel: "loud", wantErr: logops.ErrLevel},
+		{name: "invalid format", flagFmt: "xml", wantErr: logops.ErrFormat},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ebzconfig.AppConfig.LogLevel = tc.cfgLevel
+			ebzconfig.AppConfig.LogFormat = ""
+			logLevel, logFormat = tc.flagLevel, tc.flagFmt
+
+			err := setupLogging()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantDebug, slog.Default().Enabled(context.Background(), slog.LevelDebug))
+		})
+	}
+}
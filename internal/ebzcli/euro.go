@@ -2,24 +2,56 @@ package ebzcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
 	"github.com/paulwizviz/lotterystat/internal/euro"
-	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/spf13/cobra"
 )
 
 var (
-	euroFile string
+	euroFile      string
+	euroIntegrity string
+	euroFormat    string
+
+	euroFreqOpts         freqOpts
+	euroSpecialFreqOpts  freqOpts
+	euroDrawsOpts        drawsOpts
+	euroGapsOpts         gapsOpts
+	euroSpecialGapsOpts  gapsOpts
+	euroTrendOpts        trendOpts
+	euroSpecialTrendOpts trendOpts
 )
 
 func init() {
 	euroCmd.AddCommand(euroPersistsCmd)
-	euroPersistsCmd.Flags().StringVarP(&euroFile, "file", "f", "", "EuroMillions CSV file to persist")
+	euroCmd.AddCommand(euroVerifyCmd)
+	euroCmd.AddCommand(euroFreqCmd)
+	euroCmd.AddCommand(euroSpecialFreqCmd)
+	euroCmd.AddCommand(euroDrawsCmd)
+	euroCmd.AddCommand(euroLatestCmd)
+	euroCmd.AddCommand(euroGapsCmd)
+	euroCmd.AddCommand(euroSpecialGapsCmd)
+	euroCmd.AddCommand(euroTrendCmd)
+	euroCmd.AddCommand(euroSpecialTrendCmd)
+	euroPersistsCmd.Flags().StringVarP(&euroFile, "file", "f", "", "EuroMillions CSV, JSON, NDJSON or XLSX file to persist")
+	euroPersistsCmd.Flags().StringVar(&euroIntegrity, "integrity", "", "Integrity mode warn or reject (default from ebz.yaml)")
+	euroPersistsCmd.Flags().StringVar(&euroFormat, "format", "", "File format csv, json, ndjson or xlsx (default detected from the file)")
+	addFreqFlags(euroFreqCmd, &euroFreqOpts)
+	addFreqFlags(euroSpecialFreqCmd, &euroSpecialFreqOpts)
+	addDrawsFlags(euroDrawsCmd, &euroDrawsOpts)
+	addGapsFlags(euroGapsCmd, &euroGapsOpts)
+	addGapsFlags(euroSpecialGapsCmd, &euroSpecialGapsOpts)
+	addTrendFlags(euroTrendCmd, &euroTrendOpts)
+	addTrendFlags(euroSpecialTrendCmd, &euroSpecialTrendOpts)
 }
 
 var euroCmd = &cobra.Command{
@@ -32,38 +64,306 @@ var euroCmd = &cobra.Command{
 
 var euroPersistsCmd = &cobra.Command{
 	Use:   "persists",
-	Short: "persist EuroMillions csv file",
+	Short: "persist EuroMillions draws from a csv, json, ndjson or xlsx file",
 	Run: func(cmd *cobra.Command, args []string) {
 		if euroFile == "" {
 			cmd.Help()
 			return
 		}
 
-		f, err := os.Open(euroFile)
+		if euroIntegrity == "" {
+			euroIntegrity = ebzconfig.AppConfig.Integrity
+		}
+		reject, err := ebzconfig.IsIntegrityReject(euroIntegrity)
+		if err != nil {
+			fatal("invalid integrity mode", err)
+		}
+
+		srcs, err := csvops.Open(euroFile)
 		if err != nil {
-			log.Fatalf("unable to open file %s: %v", euroFile, err)
+			fatal("unable to open file", err, "file", euroFile)
 		}
-		defer f.Close()
 
-		ctx := context.Background()
-		recs := csvops.ExtractRec(ctx, f)
-		drawChans := euro.ProcessCSV(recs, 5)
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
 
-		db, err := sqlops.NewSQLiteFile(ebzconfig.AppConfig.DatabasePath)
+		renderOutput(persistSources(context.Background(), stores, srcs, "euro", euroFormat, reject))
+	},
+}
+
+// persistEuro persists the EuroMillions draws read from r in the format,
+// reporting the progress of the draws persisted
+func persistEuro(ctx context.Context, store euro.DrawStore, r io.Reader, format csvops.Format, reject bool, report func(jobops.Progress)) (importSummary, error) {
+	recs := csvops.Extract(ctx, r, format, euro.RecordOf)
+	drawChans := euro.ProcessCSV(recs, 5)
+
+	draws := []euro.Draw{}
+	invalid := 0
+	for _, dc := range drawChans {
+		if dc.Err != nil {
+			slog.Warn("skipping record", "game", "euro", "line", dc.Line, logops.Err(dc.Err))
+			invalid++
+			continue
+		}
+		draws = append(draws, dc.Draw)
+	}
+
+	draws, violations, err := euro.CheckImport(ctx, store, draws, reject)
+	if err != nil {
+		return importSummary{}, fmt.Errorf("unable to check draws: %w", err)
+	}
+	for _, v := range violations {
+		slog.Warn("integrity violation", "game", "euro", "draw_no", v.DrawNo, logops.Err(v.Err))
+	}
+
+	summary := importSummary{
+		Game:       "euro",
+		Format:     string(format),
+		Records:    len(drawChans),
+		Violations: len(violations),
+	}
+	progress := jobops.Progress{Records: summary.Records, Done: summary.Records - len(draws), Failed: invalid, Violations: summary.Violations}
+	report(progress)
+	for _, d := range draws {
+		progress.Done++
+		err := store.PersistDraw(ctx, d)
+		switch {
+		case errors.Is(err, drawops.ErrStored):
+			progress.Skipped++
+		case err != nil:
+			slog.Warn("unable to persist draw", "game", "euro", "draw_no", d.DrawNo, logops.Err(err))
+			progress.Failed++
+		default:
+			summary.Persisted++
+			progress.Persisted++
+		}
+		report(progress)
+	}
+	summary.Skipped = progress.Skipped
+	summary.Failed = progress.Failed
+	return summary, nil
+}
+
+var euroVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify integrity of stored EuroMillions draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.Euro.ListDraws(context.Background(), drawops.Filter{Sort: drawops.SortByDrawNo})
 		if err != nil {
-			log.Fatalf("unable to open database: %v", err)
+			fatal("unable to list draws", err)
 		}
+
+		violations := euro.Verify(draws)
+		slog.Info("verified draws", "game", "euro", "draws", len(draws), "violations", len(violations))
+		renderOutput(violations)
+	},
+}
+
+var euroFreqCmd = &cobra.Command{
+	Use:   "freq",
+	Short: "show frequencies of EuroMillions main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
 		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := euro.CalculateBallFreq(context.Background(), stores.Euro)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, euroFreqOpts,
+			func(f euro.BallFrequency) uint { return f.Ball },
+			func(f euro.BallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
 
-		for _, dc := range drawChans {
-			if dc.Err != nil {
-				log.Printf("skipping record due to error: %v", dc.Err)
-				continue
-			}
-			if err := euro.PersistsDraw(ctx, db, dc.Draw); err != nil {
-				log.Printf("unable to persist draw %v: %v", dc.Draw.DrawNo, err)
-			}
+		if euroFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f euro.BallFrequency) uint { return f.Ball },
+				func(f euro.BallFrequency) uint { return f.Frequency },
+				func(f euro.BallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var euroSpecialFreqCmd = &cobra.Command{
+	Use:   "special-freq",
+	Short: "show frequencies of EuroMillions lucky stars",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := euro.CalculateStarFreq(context.Background(), stores.Euro)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, euroSpecialFreqOpts,
+			func(f euro.StarFrequency) uint { return f.Star },
+			func(f euro.StarFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
+
+		if euroSpecialFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f euro.StarFrequency) uint { return f.Star },
+				func(f euro.StarFrequency) uint { return f.Frequency },
+				func(f euro.StarFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var euroDrawsCmd = &cobra.Command{
+	Use:   "draws",
+	Short: "show stored EuroMillions draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := euroDrawsOpts.filter()
+		if err != nil {
+			fatal("invalid filter", err)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.Euro.ListDraws(context.Background(), filter)
+		if err != nil {
+			fatal("unable to list draws", err)
+		}
+
+		renderOutput(draws)
+	},
+}
+
+var euroLatestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "show the latest stored EuroMillions draw",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		d, err := euro.LatestDraw(context.Background(), stores.Euro)
+		if err != nil {
+			fatal("unable to get latest draw", err)
+		}
+		renderOutput(d)
+	},
+}
+
+var euroGapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "show gaps between appearances of EuroMillions main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := euro.CalculateBallGaps(context.Background(), stores.Euro)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if euroGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var euroSpecialGapsCmd = &cobra.Command{
+	Use:   "special-gaps",
+	Short: "show gaps between appearances of EuroMillions lucky stars",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := euro.CalculateStarGaps(context.Background(), stores.Euro)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if euroSpecialGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var euroTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "show frequencies of EuroMillions main balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := euro.CalculateBallTrend(context.Background(), stores.Euro, drawops.Period(euroTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = euroTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if euroTrendOpts.chart {
+			drawTrendChart(trend, len(euroTrendOpts.balls) > 0)
+			return
+		}
+		renderOutput(trend)
+	},
+}
+
+var euroSpecialTrendCmd = &cobra.Command{
+	Use:   "special-trend",
+	Short: "show frequencies of EuroMillions lucky stars by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := euro.CalculateStarTrend(context.Background(), stores.Euro, drawops.Period(euroSpecialTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = euroSpecialTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if euroSpecialTrendOpts.chart {
+			drawTrendChart(trend, len(euroSpecialTrendOpts.balls) > 0)
+			return
 		}
-		fmt.Printf("Successfully processed %d records\n", len(drawChans))
+		renderOutput(trend)
 	},
 }
@@ -0,0 +1,134 @@
+package ebzcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/exportops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrGame = errors.New("unsupported game")
+)
+
+var (
+	exportGame   string
+	exportFormat string
+	exportOut    string
+	exportStats  bool
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportGame, "game", "", "Game to export tball, euro, lotto or sflife")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Export format csv, json, ndjson, parquet or xlsx (default from the extension of --out)")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "File to export to")
+	exportCmd.Flags().BoolVar(&exportStats, "stats", false, "Export frequencies and gaps alongside the draws")
+	exportCmd.MarkFlagRequired("game")
+	exportCmd.MarkFlagRequired("out")
+}
+
+// exportSummary reports the files written by an export
+type exportSummary struct {
+	Game   string       `json:"game"`
+	Format string       `json:"format"`
+	Files  []exportFile `json:"files"`
+}
+
+type exportFile struct {
+	Table string `json:"table"`
+	File  string `json:"file"`
+	Rows  int    `json:"rows"`
+}
+
+func (s exportSummary) Header() []string {
+	return []string{"game", "format", "table", "file", "rows"}
+}
+
+func (s exportSummary) Rows() [][]string {
+	rows := [][]string{}
+	for _, f := range s.Files {
+		rows = append(rows, []string{s.Game, s.Format, f.Table, f.File, fmt.Sprint(f.Rows)})
+	}
+	return rows
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export stored draws and statistics of a game to a file",
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := exportops.FormatOf(exportOut)
+		if exportFormat != "" {
+			format, err = exportops.ParseFormat(exportFormat)
+		}
+		if err != nil {
+			fatal("invalid export format", err)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		ctx := context.Background()
+		var tables []exportops.Table
+		switch exportGame {
+		case "tball":
+			tables, err = tball.ExportTables(ctx, stores.TBall, exportStats)
+		case "euro":
+			tables, err = euro.ExportTables(ctx, stores.Euro, exportStats)
+		case "lotto":
+			tables, err = lotto.ExportTables(ctx, stores.Lotto, exportStats)
+		case "sflife":
+			tables, err = sflife.ExportTables(ctx, stores.SFLife, exportStats)
+		default:
+			err = fmt.Errorf("%w: %s, expected tball, euro, lotto or sflife", ErrGame, exportGame)
+		}
+		if err != nil {
+			fatal("unable to export", err)
+		}
+
+		summary := exportSummary{Game: exportGame, Format: string(format)}
+		if format == exportops.XLSX {
+			if err := writeExport(exportOut, format, tables...); err != nil {
+				fatal("unable to write export", err)
+			}
+			for _, t := range tables {
+				summary.Files = append(summary.Files, exportFile{Table: t.Name, File: exportOut, Rows: t.Len()})
+			}
+		} else {
+			for i, t := range tables {
+				path := exportOut
+				if i > 0 {
+					path = exportops.TablePath(exportOut, t.Name)
+				}
+				if err := writeExport(path, format, t); err != nil {
+					fatal("unable to write export", err)
+				}
+				summary.Files = append(summary.Files, exportFile{Table: t.Name, File: path, Rows: t.Len()})
+			}
+		}
+		renderOutput(summary)
+	},
+}
+
+// writeExport writes tables to a file at path in the format
+func writeExport(path string, format exportops.Format, tables ...exportops.Table) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create file %s: %w", path, err)
+	}
+	if err := exportops.Write(f, format, tables...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
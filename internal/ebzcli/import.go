@@ -0,0 +1,72 @@
+package ebzcli
+
+import (
+	"context"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFiles     []string
+	importIntegrity string
+	importFormat    string
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringSliceVarP(&importFiles, "file", "f", nil, "CSV, JSON, NDJSON or XLSX files to import, also given as arguments")
+	importCmd.Flags().StringVar(&importIntegrity, "integrity", "", "Integrity mode warn or reject (default from ebz.yaml)")
+	importCmd.Flags().StringVar(&importFormat, "format", "", "File format csv, json, ndjson or xlsx (default detected from each file)")
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import -f <file> [file ...]",
+	Short: "import draws of any game, detected from the header and values of each file",
+	Run: func(cmd *cobra.Command, args []string) {
+		files := append(importFiles, args...)
+		if len(files) == 0 {
+			cmd.Help()
+			return
+		}
+
+		if importIntegrity == "" {
+			importIntegrity = ebzconfig.AppConfig.Integrity
+		}
+		reject, err := ebzconfig.IsIntegrityReject(importIntegrity)
+		if err != nil {
+			fatal("invalid integrity mode", err)
+		}
+
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		summaries := importSources(context.Background(), stores, files, importFormat, reject)
+		renderOutput(summaries)
+		if n := summaries.refused(); n > 0 {
+			db.Close()
+			fatal("files refused", nil, "refused", n, "files", len(summaries))
+		}
+	},
+}
+
+// importSources persists the draws of every file to the game detected from
+// it. Files that cannot be opened are summarised with their error.
+func importSources(ctx context.Context, stores ebzstore.Stores, files []string, formatFlag string, reject bool) importSummaries {
+	summaries := importSummaries{}
+	for _, file := range files {
+		srcs, err := csvops.Open(file)
+		if err != nil {
+			summaries = append(summaries, importSummary{File: file, Error: err.Error()})
+			continue
+		}
+		summaries = append(summaries, persistSources(ctx, stores, srcs, "", formatFlag, reject)...)
+	}
+	return summaries
+}
@@ -2,24 +2,56 @@ package ebzcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/lotto"
-	"github.com/paulwizviz/lotterystat/internal/sqlops"
 	"github.com/spf13/cobra"
 )
 
 var (
-	lottoFile string
+	lottoFile      string
+	lottoIntegrity string
+	lottoFormat    string
+
+	lottoFreqOpts         freqOpts
+	lottoSpecialFreqOpts  freqOpts
+	lottoDrawsOpts        drawsOpts
+	lottoGapsOpts         gapsOpts
+	lottoSpecialGapsOpts  gapsOpts
+	lottoTrendOpts        trendOpts
+	lottoSpecialTrendOpts trendOpts
 )
 
 func init() {
 	lottoCmd.AddCommand(lottoPersistsCmd)
-	lottoPersistsCmd.Flags().StringVarP(&lottoFile, "file", "f", "", "Lotto CSV file to persist")
+	lottoCmd.AddCommand(lottoVerifyCmd)
+	lottoCmd.AddCommand(lottoFreqCmd)
+	lottoCmd.AddCommand(lottoSpecialFreqCmd)
+	lottoCmd.AddCommand(lottoDrawsCmd)
+	lottoCmd.AddCommand(lottoLatestCmd)
+	lottoCmd.AddCommand(lottoGapsCmd)
+	lottoCmd.AddCommand(lottoSpecialGapsCmd)
+	lottoCmd.AddCommand(lottoTrendCmd)
+	lottoCmd.AddCommand(lottoSpecialTrendCmd)
+	lottoPersistsCmd.Flags().StringVarP(&lottoFile, "file", "f", "", "Lotto CSV, JSON, NDJSON or XLSX file to persist")
+	lottoPersistsCmd.Flags().StringVar(&lottoIntegrity, "integrity", "", "Integrity mode warn or reject (default from ebz.yaml)")
+	lottoPersistsCmd.Flags().StringVar(&lottoFormat, "format", "", "File format csv, json, ndjson or xlsx (default detected from the file)")
+	addFreqFlags(lottoFreqCmd, &lottoFreqOpts)
+	addFreqFlags(lottoSpecialFreqCmd, &lottoSpecialFreqOpts)
+	addDrawsFlags(lottoDrawsCmd, &lottoDrawsOpts)
+	addGapsFlags(lottoGapsCmd, &lottoGapsOpts)
+	addGapsFlags(lottoSpecialGapsCmd, &lottoSpecialGapsOpts)
+	addTrendFlags(lottoTrendCmd, &lottoTrendOpts)
+	addTrendFlags(lottoSpecialTrendCmd, &lottoSpecialTrendOpts)
 }
 
 var lottoCmd = &cobra.Command{
@@ -32,38 +64,306 @@ var lottoCmd = &cobra.Command{
 
 var lottoPersistsCmd = &cobra.Command{
 	Use:   "persists",
-	Short: "persist Lotto csv file",
+	Short: "persist Lotto draws from a csv, json, ndjson or xlsx file",
 	Run: func(cmd *cobra.Command, args []string) {
 		if lottoFile == "" {
 			cmd.Help()
 			return
 		}
 
-		f, err := os.Open(lottoFile)
+		if lottoIntegrity == "" {
+			lottoIntegrity = ebzconfig.AppConfig.Integrity
+		}
+		reject, err := ebzconfig.IsIntegrityReject(lottoIntegrity)
+		if err != nil {
+			fatal("invalid integrity mode", err)
+		}
+
+		srcs, err := csvops.Open(lottoFile)
 		if err != nil {
-			log.Fatalf("unable to open file %s: %v", lottoFile, err)
+			fatal("unable to open file", err, "file", lottoFile)
 		}
-		defer f.Close()
 
-		ctx := context.Background()
-		recs := csvops.ExtractRec(ctx, f)
-		drawChans := lotto.ProcessCSV(recs, 5)
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
 
-		db, err := sqlops.NewSQLiteFile(ebzconfig.AppConfig.DatabasePath)
+		renderOutput(persistSources(context.Background(), stores, srcs, "lotto", lottoFormat, reject))
+	},
+}
+
+// persistLotto persists the Lotto draws read from r in the format,
+// reporting the progress of the draws persisted
+func persistLotto(ctx context.Context, store lotto.DrawStore, r io.Reader, format csvops.Format, reject bool, report func(jobops.Progress)) (importSummary, error) {
+	recs := csvops.Extract(ctx, r, format, lotto.RecordOf)
+	drawChans := lotto.ProcessCSV(recs, 5)
+
+	draws := []lotto.Draw{}
+	invalid := 0
+	for _, dc := range drawChans {
+		if dc.Err != nil {
+			slog.Warn("skipping record", "game", "lotto", "line", dc.Line, logops.Err(dc.Err))
+			invalid++
+			continue
+		}
+		draws = append(draws, dc.Draw)
+	}
+
+	draws, violations, err := lotto.CheckImport(ctx, store, draws, reject)
+	if err != nil {
+		return importSummary{}, fmt.Errorf("unable to check draws: %w", err)
+	}
+	for _, v := range violations {
+		slog.Warn("integrity violation", "game", "lotto", "draw_no", v.DrawNo, logops.Err(v.Err))
+	}
+
+	summary := importSummary{
+		Game:       "lotto",
+		Format:     string(format),
+		Records:    len(drawChans),
+		Violations: len(violations),
+	}
+	progress := jobops.Progress{Records: summary.Records, Done: summary.Records - len(draws), Failed: invalid, Violations: summary.Violations}
+	report(progress)
+	for _, d := range draws {
+		progress.Done++
+		err := store.PersistDraw(ctx, d)
+		switch {
+		case errors.Is(err, drawops.ErrStored):
+			progress.Skipped++
+		case err != nil:
+			slog.Warn("unable to persist draw", "game", "lotto", "draw_no", d.DrawNo, logops.Err(err))
+			progress.Failed++
+		default:
+			summary.Persisted++
+			progress.Persisted++
+		}
+		report(progress)
+	}
+	summary.Skipped = progress.Skipped
+	summary.Failed = progress.Failed
+	return summary, nil
+}
+
+var lottoVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify integrity of stored Lotto draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.Lotto.ListDraws(context.Background(), drawops.Filter{Sort: drawops.SortByDrawNo})
 		if err != nil {
-			log.Fatalf("unable to open database: %v", err)
+			fatal("unable to list draws", err)
 		}
+
+		violations := lotto.Verify(draws)
+		slog.Info("verified draws", "game", "lotto", "draws", len(draws), "violations", len(violations))
+		renderOutput(violations)
+	},
+}
+
+var lottoFreqCmd = &cobra.Command{
+	Use:   "freq",
+	Short: "show frequencies of Lotto main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
 		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := lotto.CalculateBallFreq(context.Background(), stores.Lotto)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, lottoFreqOpts,
+			func(f lotto.BallFrequency) uint { return f.Ball },
+			func(f lotto.BallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
 
-		for _, dc := range drawChans {
-			if dc.Err != nil {
-				log.Printf("skipping record due to error: %v", dc.Err)
-				continue
-			}
-			if err := lotto.PersistsDraw(ctx, db, dc.Draw); err != nil {
-				log.Printf("unable to persist draw %v: %v", dc.Draw.DrawNo, err)
-			}
+		if lottoFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f lotto.BallFrequency) uint { return f.Ball },
+				func(f lotto.BallFrequency) uint { return f.Frequency },
+				func(f lotto.BallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var lottoSpecialFreqCmd = &cobra.Command{
+	Use:   "special-freq",
+	Short: "show frequencies of Lotto bonus balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := lotto.CalculateBonusFreq(context.Background(), stores.Lotto)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, lottoSpecialFreqOpts,
+			func(f lotto.BonusFrequency) uint { return f.Ball },
+			func(f lotto.BonusFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
+
+		if lottoSpecialFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f lotto.BonusFrequency) uint { return f.Ball },
+				func(f lotto.BonusFrequency) uint { return f.Frequency },
+				func(f lotto.BonusFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var lottoDrawsCmd = &cobra.Command{
+	Use:   "draws",
+	Short: "show stored Lotto draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := lottoDrawsOpts.filter()
+		if err != nil {
+			fatal("invalid filter", err)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.Lotto.ListDraws(context.Background(), filter)
+		if err != nil {
+			fatal("unable to list draws", err)
+		}
+
+		renderOutput(draws)
+	},
+}
+
+var lottoLatestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "show the latest stored Lotto draw",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		d, err := lotto.LatestDraw(context.Background(), stores.Lotto)
+		if err != nil {
+			fatal("unable to get latest draw", err)
+		}
+		renderOutput(d)
+	},
+}
+
+var lottoGapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "show gaps between appearances of Lotto main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := lotto.CalculateBallGaps(context.Background(), stores.Lotto)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if lottoGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var lottoSpecialGapsCmd = &cobra.Command{
+	Use:   "special-gaps",
+	Short: "show gaps between appearances of Lotto bonus balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := lotto.CalculateBonusGaps(context.Background(), stores.Lotto)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if lottoSpecialGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var lottoTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "show frequencies of Lotto main balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := lotto.CalculateBallTrend(context.Background(), stores.Lotto, drawops.Period(lottoTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = lottoTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if lottoTrendOpts.chart {
+			drawTrendChart(trend, len(lottoTrendOpts.balls) > 0)
+			return
+		}
+		renderOutput(trend)
+	},
+}
+
+var lottoSpecialTrendCmd = &cobra.Command{
+	Use:   "special-trend",
+	Short: "show frequencies of Lotto bonus balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := lotto.CalculateBonusTrend(context.Background(), stores.Lotto, drawops.Period(lottoSpecialTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = lottoSpecialTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if lottoSpecialTrendOpts.chart {
+			drawTrendChart(trend, len(lottoSpecialTrendOpts.balls) > 0)
+			return
 		}
-		fmt.Printf("Successfully processed %d records\n", len(drawChans))
+		renderOutput(trend)
 	},
 }
@@ -0,0 +1,47 @@
+package ebzcli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"golang.org/x/term"
+)
+
+// progressWidth is the number of cells of a progress bar
+const progressWidth = 30
+
+// progressOutput returns the writer progress bars are drawn on, stderr when
+// it is a terminal, otherwise io.Discard so that redirected output is not
+// cluttered
+func progressOutput() io.Writer {
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		return os.Stderr
+	}
+	return io.Discard
+}
+
+// progressBar returns a progress bar of the status labelled by the name
+func progressBar(name string, status jobops.Status) string {
+	filled := 0
+	if status.Records > 0 {
+		filled = min(progressWidth*status.Done/status.Records, progressWidth)
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressWidth-filled)
+	return fmt.Sprintf("%s [%s] %d/%d %s", name, bar, status.Done, status.Records, status.State)
+}
+
+// drawProgress redraws the progress bar of the name on w for each status,
+// ending the line once the statuses are closed
+func drawProgress(w io.Writer, name string, statuses <-chan jobops.Status) {
+	drawn := false
+	for status := range statuses {
+		fmt.Fprint(w, "\r\033[K"+progressBar(name, status))
+		drawn = true
+	}
+	if drawn {
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,56 @@
+package ebzcli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBar(t *testing.T) {
+	testcases := []struct {
+		name   string
+		status jobops.Status
+		want   string
+	}{
+		{
+			name:   "queued",
+			status: jobops.Status{State: jobops.Queued},
+			want:   "draws.csv [..............................] 0/0 queued",
+		},
+		{
+			name:   "running",
+			status: jobops.Status{State: jobops.Running, Progress: jobops.Progress{Records: 10, Done: 5}},
+			want:   "draws.csv [###############...............] 5/10 running",
+		},
+		{
+			name:   "succeeded",
+			status: jobops.Status{State: jobops.Succeeded, Progress: jobops.Progress{Records: 10, Done: 10}},
+			want:   "draws.csv [##############################] 10/10 succeeded",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := progressBar("draws.csv", tc.status)
+			if got != tc.want {
+				t.Fatalf("Unmatch bar. Want: %v Got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrawProgress(t *testing.T) {
+	statuses := make(chan jobops.Status, 2)
+	statuses <- jobops.Status{State: jobops.Running, Progress: jobops.Progress{Records: 2, Done: 1}}
+	statuses <- jobops.Status{State: jobops.Succeeded, Progress: jobops.Progress{Records: 2, Done: 2}}
+	close(statuses)
+
+	var buf bytes.Buffer
+	drawProgress(&buf, "draws.csv", statuses)
+
+	want := "\r\033[K" + progressBar("draws.csv", jobops.Status{State: jobops.Running, Progress: jobops.Progress{Records: 2, Done: 1}}) +
+		"\r\033[K" + progressBar("draws.csv", jobops.Status{State: jobops.Succeeded, Progress: jobops.Progress{Records: 2, Done: 2}}) + "\n"
+	assert.Equal(t, want, buf.String())
+}
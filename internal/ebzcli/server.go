@@ -1,41 +1,309 @@
 package ebzcli
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/cacheops"
+	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
 	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
 	"github.com/paulwizviz/lotterystat/internal/ebzweb"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
-	"github.com/spf13/viper"
+	"github.com/spf13/cobra"
 )
 
-func runWebserver(port int) {
-	db, err := sqlops.NewSQLiteFile(viper.GetString("database_path"))
+var (
+	serveHost       string
+	servePort       int
+	serveNoBrowser  bool
+	serveTLSCert    string
+	serveTLSKey     string
+	serveSelfSigned bool
+	servePprof      bool
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveHost, "host", "", "Host to listen on (default host of ebz.yaml)")
+	serveCmd.Flags().IntVar(&servePort, "port", 0, "Port to listen on, 0 for any free port (default port of ebz.yaml)")
+	serveCmd.Flags().BoolVar(&serveNoBrowser, "no-browser", false, "Do not open the dashboard in a browser")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "Certificate file to serve HTTPS with (default tls_cert of ebz.yaml)")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "Key file of the certificate (default tls_key of ebz.yaml)")
+	serveCmd.Flags().BoolVar(&serveSelfSigned, "tls-self-signed", false, "Serve HTTPS with a self-signed certificate of localhost kept in tls_dir of ebz.yaml")
+	serveCmd.Flags().BoolVar(&servePprof, "pprof", false, "Serve the profiles of net/http/pprof at /debug/pprof (default pprof of ebz.yaml)")
+	serveCmd.Flags().BoolVar(&readOnly, "read-only", false, "Start the web server with uploads and edits of draws disabled")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve the dashboard and REST API until interrupted",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := configuredServer()
+		if serveHost != "" {
+			cfg.host = serveHost
+		}
+		if cmd.Flags().Changed("port") {
+			cfg.port = servePort
+		}
+		if serveNoBrowser {
+			cfg.openBrowser = false
+		}
+		if serveTLSCert != "" || serveTLSKey != "" {
+			cfg.tlsCert, cfg.tlsKey = serveTLSCert, serveTLSKey
+		}
+		if serveSelfSigned {
+			cfg.tlsSelfSigned = true
+		}
+		if servePprof {
+			cfg.pprof = true
+		}
+		cfg.readOnly = readOnly
+		runWebserver(cfg)
+	},
+}
+
+// serverConfig configures the web server
+type serverConfig struct {
+	host            string
+	port            int
+	openBrowser     bool
+	tlsCert         string
+	tlsKey          string
+	tlsSelfSigned   bool
+	tlsDir          string
+	shutdownTimeout time.Duration
+	readOnly        bool
+	limits          ebzrest.Limits
+	metrics         bool
+	pprof           bool
+}
+
+// configuredServer returns the web server configured in ebz.yaml
+func configuredServer() serverConfig {
+	return serverConfig{
+		host:            ebzconfig.AppConfig.Host,
+		port:            ebzconfig.AppConfig.Port,
+		openBrowser:     ebzconfig.AppConfig.OpenBrowser,
+		tlsCert:         ebzconfig.AppConfig.TLSCert,
+		tlsKey:          ebzconfig.AppConfig.TLSKey,
+		tlsSelfSigned:   ebzconfig.AppConfig.TLSSelfSigned,
+		tlsDir:          ebzconfig.AppConfig.TLSDir,
+		shutdownTimeout: ebzconfig.AppConfig.ShutdownTimeout,
+		limits: ebzrest.Limits{
+			ReadTimeout:  ebzconfig.AppConfig.ReadTimeout,
+			WriteTimeout: ebzconfig.AppConfig.WriteTimeout,
+			MaxBody:      ebzconfig.AppConfig.MaxUploadMB << 20,
+			Rate:         ebzconfig.AppConfig.RateLimit,
+			Burst:        ebzconfig.AppConfig.RateBurst,
+		},
+		metrics: ebzconfig.AppConfig.Metrics,
+		pprof:   ebzconfig.AppConfig.Pprof,
+	}
+}
+
+// tlsConfig returns the TLS configuration of the server, or nil when it
+// serves plain HTTP. A certificate file takes precedence over a self-signed
+// certificate.
+func (c serverConfig) tlsConfig(now time.Time) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case c.tlsCert != "" && c.tlsKey != "":
+		cert, err = tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+		}
+	case c.tlsCert != "" || c.tlsKey != "":
+		return nil, fmt.Errorf("%w: tls_cert and tls_key must be set together", ErrTLSConfig)
+	case c.tlsSelfSigned:
+		cert, err = loadSelfSigned(c.tlsDir, now)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// browserURL returns the URL of the dashboard served on the address, and
+// whether it is on this machine and can be opened in a browser
+func browserURL(host string, addr net.Addr, secure bool) (string, bool) {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	port := addr.(*net.TCPAddr).Port
+	ip := net.ParseIP(host)
+	if host == "" || host == "localhost" || (ip != nil && (ip.IsLoopback() || ip.IsUnspecified())) {
+		return fmt.Sprintf("%s://localhost:%d", scheme, port), true
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(port))), false
+}
+
+// runWebserver serves the dashboard and REST API until SIGINT or SIGTERM,
+// then shuts down gracefully. With readOnly, draws cannot be uploaded or
+// edited; otherwise, when require_token is set in ebz.yaml, uploads and
+// edits need an API token. With metrics, requests, imports and queries are
+// recorded and served at /metrics.
+func runWebserver(cfg serverConfig) {
+	tlsConfig, err := cfg.tlsConfig(time.Now())
 	if err != nil {
-		log.Fatal(err)
+		fatal("unable to configure TLS", err)
 	}
+
+	var reg *metricops.Registry
+	var cacheOpts []sqlops.CacheOption
+	if cfg.metrics {
+		reg = metricops.NewRegistry()
+		cacheOpts = append(cacheOpts, sqlops.WithObserver(observeQueries(reg)))
+	}
+
+	holdDatabase()
+	defer releaseDatabase()
+	db := openDatabase()
 	defer db.Close()
+	stores := ebzstore.NewSQLite(db, cacheOpts...)
+	defer stores.Close()
+
+	reject, err := ebzconfig.IsIntegrityReject(ebzconfig.AppConfig.Integrity)
+	if err != nil {
+		fatal("invalid integrity mode", err)
+	}
+
+	jobs := jobops.NewManager(jobops.DefaultWorkers)
+	defer jobs.Close()
+
+	opts := []ebzrest.Option{
+		ebzrest.WithIntegrityReject(reject),
+		ebzrest.WithJobs(jobs),
+		ebzrest.WithReadOnly(cfg.readOnly),
+		ebzrest.WithCache(cacheops.New(ebzconfig.AppConfig.CacheTTL)),
+	}
+	if reg != nil {
+		opts = append(opts, ebzrest.WithMetrics(reg))
+	}
+	if ebzconfig.AppConfig.RequireToken && !cfg.readOnly {
+		tokens := authops.NewSQLiteStore(db)
+		if list, err := tokens.ListTokens(context.Background()); err == nil && len(list) == 0 {
+			slog.Warn("require_token is set but no token exists, create one with ebz token create")
+		}
+		opts = append(opts, ebzrest.WithTokens(tokens))
+	}
 
 	mux := http.NewServeMux()
-	mux = ebzrest.New(mux, db)
+	mux = ebzrest.New(mux, stores, opts...)
 	mux = ebzweb.New(mux)
-	fmt.Printf("Listening on port: %d", port)
-	err = http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", port), mux)
+	if cfg.pprof {
+		handlePprof(mux)
+	}
+	handler := ebzrest.Harden(mux, ebzrest.DefaultPrefix, cfg.limits)
+	if reg != nil {
+		handler = ebzrest.Measure(handler, mux, reg)
+	}
+	handler = ebzrest.AccessLog(handler)
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(cfg.host, strconv.Itoa(cfg.port)))
 	if err != nil {
-		log.Fatal(err)
+		fatal("unable to listen", err)
+	}
+	rawUrl, local := browserURL(cfg.host, ln.Addr(), tlsConfig != nil)
+	srv := &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
 	}
-}
 
-func availablePort() int {
-	listener, err := net.Listen("tcp", ":0")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = serve(ctx, srv, ln, jobs, cfg.shutdownTimeout, func() {
+		slog.Info("listening", "url", rawUrl)
+		if !cfg.openBrowser || !local {
+			return
+		}
+		if err := openBrowser(rawUrl); err != nil {
+			slog.Warn("unable to open browser", logops.Err(err))
+		}
+	})
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("server stopped", logops.Err(err))
 	}
-	defer listener.Close()
+}
 
-	addr := listener.Addr().(*net.TCPAddr)
-	return addr.Port
+// observeQueries returns an observer timing the statements of the stores on
+// the registry by operation and table
+func observeQueries(reg *metricops.Registry) sqlops.Observer {
+	took := reg.Histogram("ebz_sqlite_query_duration_seconds", "Duration of SQLite statements by operation and table.", metricops.DefaultBuckets, "op", "table")
+	failed := reg.Counter("ebz_sqlite_errors_total", "Failed SQLite statements by operation and table.", "op", "table")
+	return func(op, query string, d time.Duration, err error) {
+		table := sqlops.TableOf(query)
+		took.Observe(d.Seconds(), op, table)
+		if err != nil {
+			failed.Inc(op, table)
+		}
+	}
+}
+
+// handlePprof serves the profiles of net/http/pprof on the mux at
+// /debug/pprof. Profiles disclose the internals of the process, so they are
+// only served when asked for.
+func handlePprof(mux *http.ServeMux) {
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+}
+
+// serve serves on the listener, calling ready once connections are
+// accepted, until the context is done. It then stops accepting connections
+// and waits for the requests and jobs in flight, for up to the timeout
+// before closing connections and cancelling jobs.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, jobs *jobops.Manager, timeout time.Duration, ready func()) error {
+	served := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			served <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		served <- srv.Serve(ln)
+	}()
+	ready()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, waiting for requests and imports to finish")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		srv.Close()
+	}
+	err = errors.Join(err, jobs.Shutdown(shutdownCtx))
+	if serveErr := <-served; !errors.Is(serveErr, http.ErrServerClosed) {
+		err = errors.Join(err, serveErr)
+	}
+	return err
 }
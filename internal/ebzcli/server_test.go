@@ -0,0 +1,184 @@
+package ebzcli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrowserURL(t *testing.T) {
+	addr := &net.TCPAddr{Port: 8080}
+	testcases := []struct {
+		name      string
+		host      string
+		secure    bool
+		wantURL   string
+		wantLocal bool
+	}{
+		{name: "localhost", host: "localhost", wantURL: "http://localhost:8080", wantLocal: true},
+		{name: "loopback https", host: "127.0.0.1", secure: true, wantURL: "https://localhost:8080", wantLocal: true},
+		{name: "every interface", host: "0.0.0.0", wantURL: "http://localhost:8080", wantLocal: true},
+		{name: "other host", host: "192.168.1.10", wantURL: "http://192.168.1.10:8080"},
+		{name: "other ipv6 host", host: "fd00::1", wantURL: "http://[fd00::1]:8080"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotURL, gotLocal := browserURL(tc.host, addr, tc.secure)
+			assert.Equal(t, tc.wantURL, gotURL)
+			assert.Equal(t, tc.wantLocal, gotLocal)
+		})
+	}
+}
+
+func TestServeShutdown(t *testing.T) {
+	testcases := []struct {
+		name      string
+		timeout   time.Duration
+		wantState jobops.State
+		wantErr   bool
+	}{
+		{name: "drained", timeout: time.Minute, wantState: jobops.Succeeded},
+		{name: "timed out", timeout: 50 * time.Millisecond, wantState: jobops.Cancelled, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			jobs := jobops.NewManager(1)
+			defer jobs.Close()
+			proceed := make(chan struct{})
+			handling := make(chan struct{})
+			mux := http.NewServeMux()
+			mux.HandleFunc("/slow", func(rw http.ResponseWriter, req *http.Request) {
+				close(handling)
+				<-proceed
+				io.WriteString(rw, "done")
+			})
+			job := jobs.Start("test", func(ctx context.Context, report func(jobops.Progress)) (any, error) {
+				select {
+				case <-proceed:
+					return nil, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			})
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			isReady := make(chan struct{})
+			served := make(chan error, 1)
+			go func() {
+				served <- serve(ctx, &http.Server{Handler: mux}, ln, jobs, tc.timeout, func() { close(isReady) })
+			}()
+			<-isReady
+
+			body := make(chan string, 1)
+			go func() {
+				res, err := http.Get("http://" + ln.Addr().String() + "/slow")
+				if err != nil {
+					body <- err.Error()
+					return
+				}
+				defer res.Body.Close()
+				b, _ := io.ReadAll(res.Body)
+				body <- string(b)
+			}()
+			<-handling
+			cancel()
+			if tc.wantErr {
+				err := <-served
+				assert.Error(t, err)
+				close(proceed)
+			} else {
+				time.Sleep(50 * time.Millisecond)
+				close(proceed)
+				assert.Equal(t, "done", <-body)
+				assert.NoError(t, <-served)
+			}
+
+			status, _ := jobs.Status(job.ID)
+			assert.Equal(t, tc.wantState, status.State)
+			_, err = net.Dial("tcp", ln.Addr().String())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestServeTLS(t *testing.T) {
+	cfg := serverConfig{tlsSelfSigned: true, tlsDir: t.TempDir()}
+	tlsConfig, err := cfg.tlsConfig(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		io.WriteString(rw, "ok")
+	})
+	jobs := jobops.NewManager(1)
+	defer jobs.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, &http.Server{Handler: mux, TLSConfig: tlsConfig}, ln, jobs, time.Second, func() {})
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res, err := client.Get("https://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	b, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(b))
+	assert.NotNil(t, res.TLS)
+
+	cancel()
+	assert.NoError(t, <-served)
+}
+
+func TestHandlePprof(t *testing.T) {
+	mux := http.NewServeMux()
+	handlePprof(mux)
+
+	for _, target := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline"} {
+		t.Run(target, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	}
+}
+
+func TestObserveQueries(t *testing.T) {
+	reg := metricops.NewRegistry()
+	observe := observeQueries(reg)
+
+	observe("query", "SELECT * FROM tball", time.Millisecond, nil)
+	observe("exec", "DELETE FROM tball WHERE draw_no = $1", time.Millisecond, errors.New("locked"))
+
+	got := &strings.Builder{}
+	reg.WriteText(got)
+	assert.Contains(t, got.String(), `ebz_sqlite_query_duration_seconds_count{op="query",table="tball"} 1`)
+	assert.Contains(t, got.String(), `ebz_sqlite_errors_total{op="exec",table="tball"} 1`)
+	assert.NotContains(t, got.String(), `ebz_sqlite_errors_total{op="query"`)
+}
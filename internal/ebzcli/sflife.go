@@ -2,24 +2,56 @@ package ebzcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/sflife"
-	"github.com/paulwizviz/lotterystat/internal/sqlops"
 	"github.com/spf13/cobra"
 )
 
 var (
-	sflifeFile string
+	sflifeFile      string
+	sflifeIntegrity string
+	sflifeFormat    string
+
+	sflifeFreqOpts         freqOpts
+	sflifeSpecialFreqOpts  freqOpts
+	sflifeDrawsOpts        drawsOpts
+	sflifeGapsOpts         gapsOpts
+	sflifeSpecialGapsOpts  gapsOpts
+	sflifeTrendOpts        trendOpts
+	sflifeSpecialTrendOpts trendOpts
 )
 
 func init() {
 	sflifeCmd.AddCommand(sflifePersistsCmd)
-	sflifePersistsCmd.Flags().StringVarP(&sflifeFile, "file", "f", "", "Set For Life CSV file to persist")
+	sflifeCmd.AddCommand(sflifeVerifyCmd)
+	sflifeCmd.AddCommand(sflifeFreqCmd)
+	sflifeCmd.AddCommand(sflifeSpecialFreqCmd)
+	sflifeCmd.AddCommand(sflifeDrawsCmd)
+	sflifeCmd.AddCommand(sflifeLatestCmd)
+	sflifeCmd.AddCommand(sflifeGapsCmd)
+	sflifeCmd.AddCommand(sflifeSpecialGapsCmd)
+	sflifeCmd.AddCommand(sflifeTrendCmd)
+	sflifeCmd.AddCommand(sflifeSpecialTrendCmd)
+	sflifePersistsCmd.Flags().StringVarP(&sflifeFile, "file", "f", "", "Set For Life CSV, JSON, NDJSON or XLSX file to persist")
+	sflifePersistsCmd.Flags().StringVar(&sflifeIntegrity, "integrity", "", "Integrity mode warn or reject (default from ebz.yaml)")
+	sflifePersistsCmd.Flags().StringVar(&sflifeFormat, "format", "", "File format csv, json, ndjson or xlsx (default detected from the file)")
+	addFreqFlags(sflifeFreqCmd, &sflifeFreqOpts)
+	addFreqFlags(sflifeSpecialFreqCmd, &sflifeSpecialFreqOpts)
+	addDrawsFlags(sflifeDrawsCmd, &sflifeDrawsOpts)
+	addGapsFlags(sflifeGapsCmd, &sflifeGapsOpts)
+	addGapsFlags(sflifeSpecialGapsCmd, &sflifeSpecialGapsOpts)
+	addTrendFlags(sflifeTrendCmd, &sflifeTrendOpts)
+	addTrendFlags(sflifeSpecialTrendCmd, &sflifeSpecialTrendOpts)
 }
 
 var sflifeCmd = &cobra.Command{
@@ -32,38 +64,306 @@ var sflifeCmd = &cobra.Command{
 
 var sflifePersistsCmd = &cobra.Command{
 	Use:   "persists",
-	Short: "persist Set For Life csv file",
+	Short: "persist Set For Life draws from a csv, json, ndjson or xlsx file",
 	Run: func(cmd *cobra.Command, args []string) {
 		if sflifeFile == "" {
 			cmd.Help()
 			return
 		}
 
-		f, err := os.Open(sflifeFile)
+		if sflifeIntegrity == "" {
+			sflifeIntegrity = ebzconfig.AppConfig.Integrity
+		}
+		reject, err := ebzconfig.IsIntegrityReject(sflifeIntegrity)
+		if err != nil {
+			fatal("invalid integrity mode", err)
+		}
+
+		srcs, err := csvops.Open(sflifeFile)
 		if err != nil {
-			log.Fatalf("unable to open file %s: %v", sflifeFile, err)
+			fatal("unable to open file", err, "file", sflifeFile)
 		}
-		defer f.Close()
 
-		ctx := context.Background()
-		recs := csvops.ExtractRec(ctx, f)
-		drawChans := sflife.ProcessCSV(recs, 5)
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
 
-		db, err := sqlops.NewSQLiteFile(ebzconfig.AppConfig.DatabasePath)
+		renderOutput(persistSources(context.Background(), stores, srcs, "sflife", sflifeFormat, reject))
+	},
+}
+
+// persistSFLife persists the Set For Life draws read from r in the format,
+// reporting the progress of the draws persisted
+func persistSFLife(ctx context.Context, store sflife.DrawStore, r io.Reader, format csvops.Format, reject bool, report func(jobops.Progress)) (importSummary, error) {
+	recs := csvops.Extract(ctx, r, format, sflife.RecordOf)
+	drawChans := sflife.ProcessCSV(recs, 5)
+
+	draws := []sflife.Draw{}
+	invalid := 0
+	for _, dc := range drawChans {
+		if dc.Err != nil {
+			slog.Warn("skipping record", "game", "sflife", "line", dc.Line, logops.Err(dc.Err))
+			invalid++
+			continue
+		}
+		draws = append(draws, dc.Draw)
+	}
+
+	draws, violations, err := sflife.CheckImport(ctx, store, draws, reject)
+	if err != nil {
+		return importSummary{}, fmt.Errorf("unable to check draws: %w", err)
+	}
+	for _, v := range violations {
+		slog.Warn("integrity violation", "game", "sflife", "draw_no", v.DrawNo, logops.Err(v.Err))
+	}
+
+	summary := importSummary{
+		Game:       "sflife",
+		Format:     string(format),
+		Records:    len(drawChans),
+		Violations: len(violations),
+	}
+	progress := jobops.Progress{Records: summary.Records, Done: summary.Records - len(draws), Failed: invalid, Violations: summary.Violations}
+	report(progress)
+	for _, d := range draws {
+		progress.Done++
+		err := store.PersistDraw(ctx, d)
+		switch {
+		case errors.Is(err, drawops.ErrStored):
+			progress.Skipped++
+		case err != nil:
+			slog.Warn("unable to persist draw", "game", "sflife", "draw_no", d.DrawNo, logops.Err(err))
+			progress.Failed++
+		default:
+			summary.Persisted++
+			progress.Persisted++
+		}
+		report(progress)
+	}
+	summary.Skipped = progress.Skipped
+	summary.Failed = progress.Failed
+	return summary, nil
+}
+
+var sflifeVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify integrity of stored Set For Life draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.SFLife.ListDraws(context.Background(), drawops.Filter{Sort: drawops.SortByDrawNo})
 		if err != nil {
-			log.Fatalf("unable to open database: %v", err)
+			fatal("unable to list draws", err)
 		}
+
+		violations := sflife.Verify(draws)
+		slog.Info("verified draws", "game", "sflife", "draws", len(draws), "violations", len(violations))
+		renderOutput(violations)
+	},
+}
+
+var sflifeFreqCmd = &cobra.Command{
+	Use:   "freq",
+	Short: "show frequencies of Set For Life main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
 		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := sflife.CalculateBallFreq(context.Background(), stores.SFLife)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, sflifeFreqOpts,
+			func(f sflife.BallFrequency) uint { return f.Ball },
+			func(f sflife.BallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
 
-		for _, dc := range drawChans {
-			if dc.Err != nil {
-				log.Printf("skipping record due to error: %v", dc.Err)
-				continue
-			}
-			if err := sflife.PersistsDraw(ctx, db, dc.Draw); err != nil {
-				log.Printf("unable to persist draw %v: %v", dc.Draw.DrawNo, err)
-			}
+		if sflifeFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f sflife.BallFrequency) uint { return f.Ball },
+				func(f sflife.BallFrequency) uint { return f.Frequency },
+				func(f sflife.BallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var sflifeSpecialFreqCmd = &cobra.Command{
+	Use:   "special-freq",
+	Short: "show frequencies of Set For Life life balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := sflife.CalculateLBallFreq(context.Background(), stores.SFLife)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, sflifeSpecialFreqOpts,
+			func(f sflife.LBallFrequency) uint { return f.LBall },
+			func(f sflife.LBallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
+
+		if sflifeSpecialFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f sflife.LBallFrequency) uint { return f.LBall },
+				func(f sflife.LBallFrequency) uint { return f.Frequency },
+				func(f sflife.LBallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var sflifeDrawsCmd = &cobra.Command{
+	Use:   "draws",
+	Short: "show stored Set For Life draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := sflifeDrawsOpts.filter()
+		if err != nil {
+			fatal("invalid filter", err)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.SFLife.ListDraws(context.Background(), filter)
+		if err != nil {
+			fatal("unable to list draws", err)
+		}
+
+		renderOutput(draws)
+	},
+}
+
+var sflifeLatestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "show the latest stored Set For Life draw",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		d, err := sflife.LatestDraw(context.Background(), stores.SFLife)
+		if err != nil {
+			fatal("unable to get latest draw", err)
+		}
+		renderOutput(d)
+	},
+}
+
+var sflifeGapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "show gaps between appearances of Set For Life main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := sflife.CalculateBallGaps(context.Background(), stores.SFLife)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if sflifeGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var sflifeSpecialGapsCmd = &cobra.Command{
+	Use:   "special-gaps",
+	Short: "show gaps between appearances of Set For Life life balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := sflife.CalculateLBallGaps(context.Background(), stores.SFLife)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if sflifeSpecialGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var sflifeTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "show frequencies of Set For Life main balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := sflife.CalculateBallTrend(context.Background(), stores.SFLife, drawops.Period(sflifeTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = sflifeTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if sflifeTrendOpts.chart {
+			drawTrendChart(trend, len(sflifeTrendOpts.balls) > 0)
+			return
+		}
+		renderOutput(trend)
+	},
+}
+
+var sflifeSpecialTrendCmd = &cobra.Command{
+	Use:   "special-trend",
+	Short: "show frequencies of Set For Life life balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := sflife.CalculateLBallTrend(context.Background(), stores.SFLife, drawops.Period(sflifeSpecialTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = sflifeSpecialTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if sflifeSpecialTrendOpts.chart {
+			drawTrendChart(trend, len(sflifeSpecialTrendOpts.balls) > 0)
+			return
 		}
-		fmt.Printf("Successfully processed %d records\n", len(drawChans))
+		renderOutput(trend)
 	},
 }
@@ -0,0 +1,146 @@
+package ebzcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+)
+
+// games lists the games that draws are detected for
+var games = []csvops.Game{
+	{Name: "tball", RecordOf: tball.RecordOf, Match: tball.Match},
+	{Name: "euro", RecordOf: euro.RecordOf, Match: euro.Match},
+	{Name: "lotto", RecordOf: lotto.RecordOf, Match: lotto.Match},
+	{Name: "sflife", RecordOf: sflife.RecordOf, Match: sflife.Match},
+}
+
+// importSummary reports the outcome of persisting a file of draws
+type importSummary struct {
+	Game       string `json:"game"`
+	File       string `json:"file"`
+	Format     string `json:"format"`
+	Records    int    `json:"records"`
+	Persisted  int    `json:"persisted"`
+	Skipped    int    `json:"skipped"` // Draws already stored
+	Failed     int    `json:"failed"`  // Invalid records and draws the store refused
+	Violations int    `json:"violations"`
+	Error      string `json:"error,omitempty"`
+}
+
+// importSummaries report the outcome of persisting every source of a file
+type importSummaries []importSummary
+
+// refused returns the number of sources that were not persisted
+func (s importSummaries) refused() int {
+	n := 0
+	for _, i := range s {
+		if i.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func (s importSummaries) Header() []string {
+	return []string{"game", "file", "format", "records", "persisted", "skipped", "failed", "violations", "error"}
+}
+
+func (s importSummaries) Rows() [][]string {
+	rows := [][]string{}
+	for _, i := range s {
+		rows = append(rows, []string{i.Game, i.File, i.Format, fmt.Sprint(i.Records), fmt.Sprint(i.Persisted), fmt.Sprint(i.Skipped), fmt.Sprint(i.Failed), fmt.Sprint(i.Violations), i.Error})
+	}
+	return rows
+}
+
+// persistSources persists the draws of every source, one import job at a
+// time, and closes it. The progress of each job is drawn as a progress bar
+// on a terminal. Sources that fail are summarised with their error.
+func persistSources(ctx context.Context, stores ebzstore.Stores, srcs []csvops.Source, game string, formatFlag string, reject bool) importSummaries {
+	jobs := jobops.NewManager(1)
+	defer jobs.Close()
+	out := progressOutput()
+
+	summaries := importSummaries{}
+	for _, src := range srcs {
+		started := jobs.Start("import", func(ctx context.Context, report func(jobops.Progress)) (any, error) {
+			return persistSource(ctx, stores, src, game, formatFlag, reject, report)
+		})
+		if statuses, err := jobs.Watch(ctx, started.ID); err == nil {
+			drawProgress(out, sourceName(src), statuses)
+		}
+		status, err := jobs.Wait(ctx, started.ID)
+		summary, _ := status.Result.(importSummary)
+		src.Close()
+		if err != nil {
+			summary = importSummary{Game: summary.Game, File: sourceName(src), Format: summary.Format, Error: err.Error()}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// persistSource persists the draws of a source as draws of the game. If game
+// is empty, or for CSV entries of an archive, the draws are persisted as
+// draws of the game detected from the source instead. The progress of the
+// draws persisted is reported as it goes.
+func persistSource(ctx context.Context, stores ebzstore.Stores, src csvops.Source, game string, formatFlag string, reject bool, report func(jobops.Progress)) (importSummary, error) {
+	format, r, err := sourceFormat(src.Name, formatFlag, src)
+	if err != nil {
+		return importSummary{}, err
+	}
+	if game == "" || (src.Archive != "" && format == csvops.CSV) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return importSummary{Format: string(format)}, err
+		}
+		if game, err = csvops.DetectGame(ctx, content, format, games); err != nil {
+			return importSummary{Format: string(format)}, err
+		}
+		r = bytes.NewReader(content)
+	}
+
+	var summary importSummary
+	switch game {
+	case "tball":
+		summary, err = persistTBall(ctx, stores.TBall, r, format, reject, report)
+	case "euro":
+		summary, err = persistEuro(ctx, stores.Euro, r, format, reject, report)
+	case "lotto":
+		summary, err = persistLotto(ctx, stores.Lotto, r, format, reject, report)
+	case "sflife":
+		summary, err = persistSFLife(ctx, stores.SFLife, r, format, reject, report)
+	default:
+		err = fmt.Errorf("%w: %s", ErrGame, game)
+	}
+	summary.File = sourceName(src)
+	return summary, err
+}
+
+// sourceFormat returns the format of the file named by the format flag or,
+// if the flag is empty, detected from the file
+func sourceFormat(name string, flag string, f io.Reader) (csvops.Format, io.Reader, error) {
+	if flag != "" {
+		format, err := csvops.ParseFormat(flag)
+		return format, f, err
+	}
+	format, r := csvops.DetectFormat(name, f)
+	return format, r, nil
+}
+
+// sourceName returns the name of a source, prefixed by its archive if any
+func sourceName(src csvops.Source) string {
+	if src.Archive != "" {
+		return src.Archive + ":" + src.Name
+	}
+	return src.Name
+}
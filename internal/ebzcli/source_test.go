@@ -0,0 +1,112 @@
+package ebzcli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportSources(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := sqlops.CreateTables(context.TODO(), db, tball.CreateTableFn, euro.CreateTableFn, lotto.CreateTableFn, sflife.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	tballDraw := tball.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Friday, Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, BallSet: "T9", Machine: "Excalibur6", DrawNo: 3856}
+	sflifeDraw := sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Thursday, Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, BallSet: "SFL3", Machine: "Excalibur6", DrawNo: 724}
+	sflifeJSON, _ := json.Marshal([]sflife.Draw{sflifeDraw})
+	// A Thunderball header with values out of the Thunderball ranges
+	outOfRange := tball.FormatRecord(tballDraw)
+	outOfRange[6] = "20"
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"tball.csv":     strings.Join(tball.CSVHeader, ",") + "\n" + strings.Join(tball.FormatRecord(tballDraw), ",") + "\n",
+		"sflife.json":   string(sflifeJSON),
+		"unknown.csv":   "Date,Number\n20-Feb-2026,1\n",
+		"tball-bad.csv": strings.Join(tball.CSVHeader, ",") + "\n" + strings.Join(outOfRange, ",") + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name      string
+		file      string
+		game      string
+		persisted int
+		refused   bool
+	}{
+		{name: "csv by header", file: "tball.csv", game: "tball", persisted: 1},
+		{name: "json by values", file: "sflife.json", game: "sflife", persisted: 1},
+		{name: "unknown header", file: "unknown.csv", refused: true},
+		{name: "out of range", file: "tball-bad.csv", refused: true},
+		{name: "missing file", file: "missing.csv", refused: true},
+	}
+
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := importSources(context.TODO(), stores, []string{filepath.Join(dir, tc.file)}, "", false)
+			if !assert.Len(t, got, 1) {
+				return
+			}
+			assert.Equal(t, tc.game, got[0].Game)
+			assert.Equal(t, tc.persisted, got[0].Persisted)
+			assert.Equal(t, tc.refused, got[0].Error != "", got[0].Error)
+			assert.Equal(t, tc.refused, got.refused() == 1)
+		})
+	}
+}
+
+func TestPersistSummary(t *testing.T) {
+	stored := tball.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Friday, Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, BallSet: "T9", Machine: "Excalibur6", DrawNo: 3856}
+	// No Thunderball draw on a Sunday
+	sunday := tball.Draw{DrawDate: time.Date(2026, time.February, 22, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Sunday, Ball1: 2, Ball2: 5, Ball3: 7, Ball4: 9, Ball5: 12, TBall: 4, BallSet: "T9", Machine: "Excalibur6", DrawNo: 3857}
+	invalid := tball.FormatRecord(tball.Draw{DrawDate: time.Date(2026, time.February, 21, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3858})
+	invalid[6] = "20"
+
+	store := tball.NewMemStore()
+	if err := store.PersistDraw(context.TODO(), stored); err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Join([]string{
+		strings.Join(tball.CSVHeader, ","),
+		strings.Join(tball.FormatRecord(stored), ","),
+		strings.Join(tball.FormatRecord(sunday), ","),
+		strings.Join(invalid, ","),
+	}, "\n") + "\n"
+
+	got, err := persistTBall(context.TODO(), store, strings.NewReader(content), csvops.CSV, true, func(jobops.Progress) {})
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	// The draw refused by the integrity checks is neither skipped nor failed
+	assert.Equal(t, importSummary{Game: "tball", Format: "csv", Records: 3, Persisted: 0, Skipped: 1, Failed: 1, Violations: 1}, got)
+}
+
+func TestSourceName(t *testing.T) {
+	assert.Equal(t, "draws.csv", sourceName(csvops.Source{Name: "draws.csv"}))
+	assert.Equal(t, "draws.zip:euro.csv", sourceName(csvops.Source{Name: "euro.csv", Archive: "draws.zip"}))
+}
@@ -2,24 +2,56 @@ package ebzcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/ebzconfig"
-	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/tball"
 	"github.com/spf13/cobra"
 )
 
 var (
-	tballFile string
+	tballFile      string
+	tballIntegrity string
+	tballFormat    string
+
+	tballFreqOpts         freqOpts
+	tballSpecialFreqOpts  freqOpts
+	tballDrawsOpts        drawsOpts
+	tballGapsOpts         gapsOpts
+	tballSpecialGapsOpts  gapsOpts
+	tballTrendOpts        trendOpts
+	tballSpecialTrendOpts trendOpts
 )
 
 func init() {
 	tballCmd.AddCommand(tballPersistsCmd)
-	tballPersistsCmd.Flags().StringVarP(&tballFile, "file", "f", "", "Thunderball CSV file to persist")
+	tballCmd.AddCommand(tballVerifyCmd)
+	tballCmd.AddCommand(tballFreqCmd)
+	tballCmd.AddCommand(tballSpecialFreqCmd)
+	tballCmd.AddCommand(tballDrawsCmd)
+	tballCmd.AddCommand(tballLatestCmd)
+	tballCmd.AddCommand(tballGapsCmd)
+	tballCmd.AddCommand(tballSpecialGapsCmd)
+	tballCmd.AddCommand(tballTrendCmd)
+	tballCmd.AddCommand(tballSpecialTrendCmd)
+	tballPersistsCmd.Flags().StringVarP(&tballFile, "file", "f", "", "Thunderball CSV, JSON, NDJSON or XLSX file to persist")
+	tballPersistsCmd.Flags().StringVar(&tballIntegrity, "integrity", "", "Integrity mode warn or reject (default from ebz.yaml)")
+	tballPersistsCmd.Flags().StringVar(&tballFormat, "format", "", "File format csv, json, ndjson or xlsx (default detected from the file)")
+	addFreqFlags(tballFreqCmd, &tballFreqOpts)
+	addFreqFlags(tballSpecialFreqCmd, &tballSpecialFreqOpts)
+	addDrawsFlags(tballDrawsCmd, &tballDrawsOpts)
+	addGapsFlags(tballGapsCmd, &tballGapsOpts)
+	addGapsFlags(tballSpecialGapsCmd, &tballSpecialGapsOpts)
+	addTrendFlags(tballTrendCmd, &tballTrendOpts)
+	addTrendFlags(tballSpecialTrendCmd, &tballSpecialTrendOpts)
 }
 
 var tballCmd = &cobra.Command{
@@ -32,38 +64,306 @@ var tballCmd = &cobra.Command{
 
 var tballPersistsCmd = &cobra.Command{
 	Use:   "persists",
-	Short: "persist thunderball csv file",
+	Short: "persist thunderball draws from a csv, json, ndjson or xlsx file",
 	Run: func(cmd *cobra.Command, args []string) {
 		if tballFile == "" {
 			cmd.Help()
 			return
 		}
 
-		f, err := os.Open(tballFile)
+		if tballIntegrity == "" {
+			tballIntegrity = ebzconfig.AppConfig.Integrity
+		}
+		reject, err := ebzconfig.IsIntegrityReject(tballIntegrity)
+		if err != nil {
+			fatal("invalid integrity mode", err)
+		}
+
+		srcs, err := csvops.Open(tballFile)
 		if err != nil {
-			log.Fatalf("unable to open file %s: %v", tballFile, err)
+			fatal("unable to open file", err, "file", tballFile)
 		}
-		defer f.Close()
 
-		ctx := context.Background()
-		recs := csvops.ExtractRec(ctx, f)
-		drawChans := tball.ProcessCSV(recs, 5)
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
 
-		db, err := sqlops.NewSQLiteFile(ebzconfig.AppConfig.DatabasePath)
+		renderOutput(persistSources(context.Background(), stores, srcs, "tball", tballFormat, reject))
+	},
+}
+
+// persistTBall persists the Thunderball draws read from r in the format,
+// reporting the progress of the draws persisted
+func persistTBall(ctx context.Context, store tball.DrawStore, r io.Reader, format csvops.Format, reject bool, report func(jobops.Progress)) (importSummary, error) {
+	recs := csvops.Extract(ctx, r, format, tball.RecordOf)
+	drawChans := tball.ProcessCSV(recs, 5)
+
+	draws := []tball.Draw{}
+	invalid := 0
+	for _, dc := range drawChans {
+		if dc.Err != nil {
+			slog.Warn("skipping record", "game", "tball", "line", dc.Line, logops.Err(dc.Err))
+			invalid++
+			continue
+		}
+		draws = append(draws, dc.Draw)
+	}
+
+	draws, violations, err := tball.CheckImport(ctx, store, draws, reject)
+	if err != nil {
+		return importSummary{}, fmt.Errorf("unable to check draws: %w", err)
+	}
+	for _, v := range violations {
+		slog.Warn("integrity violation", "game", "tball", "draw_no", v.DrawNo, logops.Err(v.Err))
+	}
+
+	summary := importSummary{
+		Game:       "tball",
+		Format:     string(format),
+		Records:    len(drawChans),
+		Violations: len(violations),
+	}
+	progress := jobops.Progress{Records: summary.Records, Done: summary.Records - len(draws), Failed: invalid, Violations: summary.Violations}
+	report(progress)
+	for _, d := range draws {
+		progress.Done++
+		err := store.PersistDraw(ctx, d)
+		switch {
+		case errors.Is(err, drawops.ErrStored):
+			progress.Skipped++
+		case err != nil:
+			slog.Warn("unable to persist draw", "game", "tball", "draw_no", d.DrawNo, logops.Err(err))
+			progress.Failed++
+		default:
+			summary.Persisted++
+			progress.Persisted++
+		}
+		report(progress)
+	}
+	summary.Skipped = progress.Skipped
+	summary.Failed = progress.Failed
+	return summary, nil
+}
+
+var tballVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify integrity of stored Thunderball draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.TBall.ListDraws(context.Background(), drawops.Filter{Sort: drawops.SortByDrawNo})
 		if err != nil {
-			log.Fatalf("unable to open database: %v", err)
+			fatal("unable to list draws", err)
 		}
+
+		violations := tball.Verify(draws)
+		slog.Info("verified draws", "game", "tball", "draws", len(draws), "violations", len(violations))
+		renderOutput(violations)
+	},
+}
+
+var tballFreqCmd = &cobra.Command{
+	Use:   "freq",
+	Short: "show frequencies of Thunderball main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
 		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := tball.CalculateBallFreq(context.Background(), stores.TBall)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, tballFreqOpts,
+			func(f tball.BallFrequency) uint { return f.Ball },
+			func(f tball.BallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
 
-		for _, dc := range drawChans {
-			if dc.Err != nil {
-				log.Printf("skipping record due to error: %v", dc.Err)
-				continue
-			}
-			if err := tball.PersistsDraw(ctx, db, dc.Draw); err != nil {
-				log.Printf("unable to persist draw %v: %v", dc.Draw.DrawNo, err)
-			}
+		if tballFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f tball.BallFrequency) uint { return f.Ball },
+				func(f tball.BallFrequency) uint { return f.Frequency },
+				func(f tball.BallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var tballSpecialFreqCmd = &cobra.Command{
+	Use:   "special-freq",
+	Short: "show frequencies of Thunderball thunderballs",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		freqs, err := tball.CalculateTBallFreq(context.Background(), stores.TBall)
+		if err != nil {
+			fatal("unable to calculate frequencies", err)
+		}
+		err = sortFreqs(freqs, tballSpecialFreqOpts,
+			func(f tball.TBallFrequency) uint { return f.TBall },
+			func(f tball.TBallFrequency) uint { return f.Frequency })
+		if err != nil {
+			fatal("unable to sort frequencies", err)
+		}
+
+		if tballSpecialFreqOpts.chart {
+			drawBarChart(freqBars(freqs,
+				func(f tball.TBallFrequency) uint { return f.TBall },
+				func(f tball.TBallFrequency) uint { return f.Frequency },
+				func(f tball.TBallFrequency) float64 { return f.Expected }))
+			return
+		}
+
+		renderOutput(freqs)
+	},
+}
+
+var tballDrawsCmd = &cobra.Command{
+	Use:   "draws",
+	Short: "show stored Thunderball draws",
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := tballDrawsOpts.filter()
+		if err != nil {
+			fatal("invalid filter", err)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		draws, err := stores.TBall.ListDraws(context.Background(), filter)
+		if err != nil {
+			fatal("unable to list draws", err)
+		}
+
+		renderOutput(draws)
+	},
+}
+
+var tballLatestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "show the latest stored Thunderball draw",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		d, err := tball.LatestDraw(context.Background(), stores.TBall)
+		if err != nil {
+			fatal("unable to get latest draw", err)
+		}
+		renderOutput(d)
+	},
+}
+
+var tballGapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "show gaps between appearances of Thunderball main balls",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := tball.CalculateBallGaps(context.Background(), stores.TBall)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if tballGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var tballSpecialGapsCmd = &cobra.Command{
+	Use:   "special-gaps",
+	Short: "show gaps between appearances of Thunderball thunderballs",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		gaps, err := tball.CalculateTBallGaps(context.Background(), stores.TBall)
+		if err != nil {
+			fatal("unable to calculate gaps", err)
+		}
+
+		if tballSpecialGapsOpts.chart {
+			drawBarChart(gapBars(gaps))
+			return
+		}
+		renderOutput(gaps)
+	},
+}
+
+var tballTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "show frequencies of Thunderball main balls by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := tball.CalculateBallTrend(context.Background(), stores.TBall, drawops.Period(tballTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = tballTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if tballTrendOpts.chart {
+			drawTrendChart(trend, len(tballTrendOpts.balls) > 0)
+			return
+		}
+		renderOutput(trend)
+	},
+}
+
+var tballSpecialTrendCmd = &cobra.Command{
+	Use:   "special-trend",
+	Short: "show frequencies of Thunderball thunderballs by period",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+		stores := ebzstore.NewSQLite(db)
+		defer stores.Close()
+
+		trend, err := tball.CalculateTBallTrend(context.Background(), stores.TBall, drawops.Period(tballSpecialTrendOpts.period))
+		if err != nil {
+			fatal("unable to calculate trend", err)
+		}
+		trend, err = tballSpecialTrendOpts.selectBalls(trend)
+		if err != nil {
+			fatal("invalid balls", err)
+		}
+
+		if tballSpecialTrendOpts.chart {
+			drawTrendChart(trend, len(tballSpecialTrendOpts.balls) > 0)
+			return
 		}
-		fmt.Printf("Successfully processed %d records\n", len(drawChans))
+		renderOutput(trend)
 	},
 }
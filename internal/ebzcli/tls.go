@@ -0,0 +1,95 @@
+package ebzcli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrTLSConfig = errors.New("invalid tls config")
+)
+
+const (
+	// selfSignedCert and selfSignedKey are the files of the self-signed
+	// certificate in tls_dir
+	selfSignedCert = "localhost.crt"
+	selfSignedKey  = "localhost.key"
+	// selfSignedValidity is how long a self-signed certificate is valid
+	selfSignedValidity = 365 * 24 * time.Hour
+	// selfSignedRenewal is how long before it expires a self-signed
+	// certificate is replaced
+	selfSignedRenewal = 7 * 24 * time.Hour
+)
+
+// loadSelfSigned returns the self-signed certificate of localhost kept in
+// dir, creating it when it is missing or about to expire, so that a browser
+// told to trust it keeps trusting it between runs
+func loadSelfSigned(dir string, now time.Time) (tls.Certificate, error) {
+	certFile := filepath.Join(dir, selfSignedCert)
+	keyFile := filepath.Join(dir, selfSignedKey)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err == nil && now.Add(selfSignedRenewal).Before(cert.Leaf.NotAfter) {
+		return cert, nil
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned(now)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// generateSelfSigned returns a PEM encoded certificate and key of
+// localhost, 127.0.0.1 and ::1, valid from now for selfSignedValidity
+func generateSelfSigned(now time.Time) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "localhost", Organization: []string{"ebz"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(selfSignedValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTLSConfig, err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,68 @@
+package ebzcli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSelfSigned(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	now := time.Now()
+
+	first, err := loadSelfSigned(dir, now)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, []string{"localhost"}, first.Leaf.DNSNames)
+	assert.NoError(t, first.Leaf.VerifyHostname("127.0.0.1"))
+	info, err := os.Stat(filepath.Join(dir, selfSignedKey))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := loadSelfSigned(dir, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, first.Leaf.SerialNumber, again.Leaf.SerialNumber)
+
+	renewed, err := loadSelfSigned(dir, now.Add(selfSignedValidity-selfSignedRenewal))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Leaf.SerialNumber, renewed.Leaf.SerialNumber)
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM, err := generateSelfSigned(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, certPEM, 0600)
+	os.WriteFile(keyFile, keyPEM, 0600)
+
+	testcases := []struct {
+		name    string
+		cfg     serverConfig
+		wantTLS bool
+		wantErr error
+	}{
+		{name: "plain http", cfg: serverConfig{}},
+		{name: "certificate files", cfg: serverConfig{tlsCert: certFile, tlsKey: keyFile}, wantTLS: true},
+		{name: "self-signed", cfg: serverConfig{tlsSelfSigned: true, tlsDir: filepath.Join(dir, "tls")}, wantTLS: true},
+		{name: "certificate without key", cfg: serverConfig{tlsCert: certFile}, wantErr: ErrTLSConfig},
+		{name: "missing certificate", cfg: serverConfig{tlsCert: filepath.Join(dir, "none.pem"), tlsKey: keyFile}, wantErr: ErrTLSConfig},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.cfg.tlsConfig(time.Now())
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.wantTLS, got != nil)
+		})
+	}
+}
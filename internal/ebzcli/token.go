@@ -0,0 +1,109 @@
+package ebzcli
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenName string
+	tokenID   string
+)
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "Name of the token, such as the client using it")
+	tokenCreateCmd.MarkFlagRequired("name")
+	tokenRevokeCmd.Flags().StringVar(&tokenID, "id", "", "ID of the token to revoke")
+	tokenRevokeCmd.MarkFlagRequired("id")
+}
+
+// tokenRow reports a token, with its secret when it has just been created
+type tokenRow struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Secret  string    `json:"secret,omitempty"`
+}
+
+// tokenRows report API tokens
+type tokenRows []tokenRow
+
+func (t tokenRows) Header() []string {
+	return []string{"id", "name", "created", "secret"}
+}
+
+func (t tokenRows) Rows() [][]string {
+	rows := [][]string{}
+	for _, r := range t {
+		rows = append(rows, []string{r.ID, r.Name, r.Created.Format(time.RFC3339), r.Secret})
+	}
+	return rows
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "manage the API tokens of the web server",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create --name <name>",
+	Short: "create an API token, shown once",
+	Run: func(cmd *cobra.Command, args []string) {
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+
+		t, secret, err := authops.CreateToken(context.Background(), db, tokenName)
+		if err != nil {
+			fatal("unable to create token", err)
+		}
+		renderOutput(tokenRows{{ID: t.ID, Name: t.Name, Created: t.Created, Secret: secret}})
+		slog.Warn("store the secret now, it cannot be shown again")
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke --id <id>",
+	Short: "revoke an API token, signing out the browsers using it",
+	Run: func(cmd *cobra.Command, args []string) {
+		holdDatabase()
+		defer releaseDatabase()
+		db := openDatabase()
+		defer db.Close()
+
+		if err := authops.RevokeToken(context.Background(), db, tokenID); err != nil {
+			fatal("unable to revoke token", err)
+		}
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the API tokens, without their secrets",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+
+		tokens, err := authops.ListTokens(context.Background(), db)
+		if err != nil {
+			fatal("unable to list tokens", err)
+		}
+		rows := tokenRows{}
+		for _, t := range tokens {
+			rows = append(rows, tokenRow{ID: t.ID, Name: t.Name, Created: t.Created})
+		}
+		renderOutput(rows)
+	},
+}
@@ -3,12 +3,18 @@ package ebzconfig
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path"
+	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/cacheops"
 	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/lotto"
 	"github.com/paulwizviz/lotterystat/internal/sflife"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
@@ -17,7 +23,36 @@ import (
 )
 
 var (
-	ErrConfig = errors.New("config err")
+	ErrConfig        = errors.New("config err")
+	ErrIntegrityMode = errors.New("invalid integrity mode")
+)
+
+const (
+	// IntegrityWarn persists draws failing integrity checks and reports them
+	IntegrityWarn = "warn"
+	// IntegrityReject skips draws failing integrity checks and reports them
+	IntegrityReject = "reject"
+)
+
+const (
+	// DefaultHost is the host the web server listens on, reachable from
+	// this machine only
+	DefaultHost = "localhost"
+	// DefaultShutdownTimeout is how long the web server waits for requests
+	// and imports to finish on shutdown
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultReadTimeout is how long a request, including its body, may take
+	// to be read
+	DefaultReadTimeout = time.Minute
+	// DefaultWriteTimeout is how long a request may take to be handled and
+	// its response written
+	DefaultWriteTimeout = time.Minute
+	// DefaultMaxUploadMB is the largest request body in megabytes
+	DefaultMaxUploadMB = 32
+	// DefaultRateLimit is the number of requests per second a client IP may
+	// make to routes changing draws, after a burst of DefaultRateBurst
+	DefaultRateLimit = 1.0
+	DefaultRateBurst = 10
 )
 
 var locationFunc = location
@@ -29,11 +64,34 @@ const (
 
 // Configuration represents the application configuration
 type Configuration struct {
-	TballCache       string `mapstructure:"tball_cache"`
-	EuromillionCache string `mapstructure:"euromillion_cache"`
-	SflCache         string `mapstructure:"sfl_cache"`
-	LottoCache       string `mapstructure:"lotto_cache"`
-	DatabasePath     string `mapstructure:"database_path"`
+	TballCache       string        `mapstructure:"tball_cache"`
+	EuromillionCache string        `mapstructure:"euromillion_cache"`
+	SflCache         string        `mapstructure:"sfl_cache"`
+	LottoCache       string        `mapstructure:"lotto_cache"`
+	DatabasePath     string        `mapstructure:"database_path"`
+	BackupDir        string        `mapstructure:"backup_dir"`
+	Integrity        string        `mapstructure:"integrity"`
+	AutoMigrate      bool          `mapstructure:"auto_migrate"`
+	BusyTimeout      time.Duration `mapstructure:"busy_timeout"`
+	RequireToken     bool          `mapstructure:"require_token"`
+	Host             string        `mapstructure:"host"`
+	Port             int           `mapstructure:"port"`
+	OpenBrowser      bool          `mapstructure:"open_browser"`
+	TLSCert          string        `mapstructure:"tls_cert"`
+	TLSKey           string        `mapstructure:"tls_key"`
+	TLSSelfSigned    bool          `mapstructure:"tls_self_signed"`
+	TLSDir           string        `mapstructure:"tls_dir"`
+	ShutdownTimeout  time.Duration `mapstructure:"shutdown_timeout"`
+	ReadTimeout      time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout     time.Duration `mapstructure:"write_timeout"`
+	MaxUploadMB      int64         `mapstructure:"max_upload_mb"`
+	RateLimit        float64       `mapstructure:"rate_limit"`
+	RateBurst        int           `mapstructure:"rate_burst"`
+	CacheTTL         time.Duration `mapstructure:"cache_ttl"`
+	Metrics          bool          `mapstructure:"metrics"`
+	Pprof            bool          `mapstructure:"pprof"`
+	LogLevel         string        `mapstructure:"log_level"`
+	LogFormat        string        `mapstructure:"log_format"`
 }
 
 // AppConfig is the global configuration instance
@@ -62,6 +120,29 @@ func Initialize() error {
 	viper.SetDefault("sfl_cache", path.Join(appHome, "cache", "sfl"))
 	viper.SetDefault("lotto_cache", path.Join(appHome, "cache", "lotto"))
 	viper.SetDefault("database_path", path.Join(appHome, dbName))
+	viper.SetDefault("backup_dir", path.Join(appHome, "backup"))
+	viper.SetDefault("integrity", IntegrityWarn)
+	viper.SetDefault("auto_migrate", true)
+	viper.SetDefault("busy_timeout", sqlops.DefaultBusyTimeout.String())
+	viper.SetDefault("require_token", false)
+	viper.SetDefault("host", DefaultHost)
+	viper.SetDefault("port", 0)
+	viper.SetDefault("open_browser", true)
+	viper.SetDefault("tls_cert", "")
+	viper.SetDefault("tls_key", "")
+	viper.SetDefault("tls_self_signed", false)
+	viper.SetDefault("tls_dir", path.Join(appHome, "tls"))
+	viper.SetDefault("shutdown_timeout", DefaultShutdownTimeout.String())
+	viper.SetDefault("read_timeout", DefaultReadTimeout.String())
+	viper.SetDefault("write_timeout", DefaultWriteTimeout.String())
+	viper.SetDefault("max_upload_mb", DefaultMaxUploadMB)
+	viper.SetDefault("rate_limit", DefaultRateLimit)
+	viper.SetDefault("rate_burst", DefaultRateBurst)
+	viper.SetDefault("cache_ttl", cacheops.DefaultTTL.String())
+	viper.SetDefault("metrics", true)
+	viper.SetDefault("pprof", false)
+	viper.SetDefault("log_level", logops.DefaultLevel)
+	viper.SetDefault("log_format", string(logops.DefaultFormat))
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -88,33 +169,102 @@ func Initialize() error {
 		}
 	}
 
-	if err := createDBTables(context.Background(), AppConfig.DatabasePath); err != nil {
+	if err := migrateDB(context.Background(), AppConfig.DatabasePath, AppConfig.AutoMigrate); err != nil {
 		return fmt.Errorf("%w: %v", ErrConfig, err)
 	}
 
 	return nil
 }
 
-func createDBTables(ctx context.Context, dbFile string) error {
-	db, err := sqlops.NewSQLiteFile(dbFile)
+// Schemas lists the schemas of the database
+var Schemas = []sqlops.Schema{
+	tball.Schema,
+	euro.Schema,
+	lotto.Schema,
+	sflife.Schema,
+	authops.Schema,
+}
+
+// migrationLockWait is how long to wait for another process migrating the
+// database
+var migrationLockWait = 30 * time.Second
+
+// OpenDatabase opens the configured database with the configured busy
+// timeout
+func OpenDatabase() (*sql.DB, error) {
+	return sqlops.NewSQLiteFile(AppConfig.DatabasePath, sqlops.WithBusyTimeout(AppConfig.BusyTimeout))
+}
+
+// LockMigrations locks the migrations of the database file against other
+// ebz processes, waiting for a process migrating it to finish. The returned
+// function releases the lock.
+func LockMigrations(ctx context.Context, dbFile string) (func() error, error) {
+	ctx, cancel := context.WithTimeout(ctx, migrationLockWait)
+	defer cancel()
+	return sqlops.LockMigrations(ctx, dbFile)
+}
+
+// holdDatabaseWait is how long to wait for another process restoring the
+// database
+var holdDatabaseWait = 30 * time.Second
+
+// HoldDatabase holds the database file against restores while it is served
+// or written, waiting for a process restoring it to finish. The returned
+// function releases the hold.
+func HoldDatabase(ctx context.Context, dbFile string) (func() error, error) {
+	ctx, cancel := context.WithTimeout(ctx, holdDatabaseWait)
+	defer cancel()
+	return sqlops.HoldDatabase(ctx, dbFile)
+}
+
+// migrateDB migrates the database to the latest version of every schema
+// when auto is set, otherwise it only logs migrations not yet applied
+func migrateDB(ctx context.Context, dbFile string, auto bool) error {
+	db, err := sqlops.NewSQLiteFile(dbFile, sqlops.WithBusyTimeout(AppConfig.BusyTimeout))
 	if err != nil {
 		return err
 	}
 	defer db.Close()
-	tblCreators := []sqlops.TblCreator{
-		tball.CreateTableFn,
-		euro.CreateTableFn,
-		lotto.CreateTableFn,
-		sflife.CreateTableFn,
-	}
 
-	if err := sqlops.CreateTables(ctx, db, tblCreators...); err != nil {
+	if auto {
+		release, err := LockMigrations(ctx, dbFile)
+		if err != nil {
+			return err
+		}
+		defer release()
+		_, err = sqlops.Migrate(ctx, db, sqlops.Latest, Schemas...)
 		return err
 	}
 
+	statuses, err := sqlops.MigrationStatuses(ctx, db, Schemas...)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, s := range statuses {
+		if !s.Applied() {
+			pending++
+		}
+	}
+	if pending > 0 {
+		slog.Warn("database migrations not applied, run ebz db migrate", "pending", pending)
+	}
 	return nil
 }
 
+// IsIntegrityReject reports whether the integrity mode rejects draws
+// failing integrity checks
+func IsIntegrityReject(mode string) (bool, error) {
+	switch mode {
+	case IntegrityWarn:
+		return false, nil
+	case IntegrityReject:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %s, expected %s or %s", ErrIntegrityMode, mode, IntegrityWarn, IntegrityReject)
+	}
+}
+
 // location returns $HOME/.ebz
 func location() (string, error) {
 	dir, err := os.UserHomeDir()
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -64,4 +65,43 @@ func TestInitialize(t *testing.T) {
 	assert.Contains(t, AppConfig.SflCache, path.Join(configDir, "cache", "sfl"))
 	assert.Contains(t, AppConfig.LottoCache, path.Join(configDir, "cache", "lotto"))
 	assert.Equal(t, path.Join(configDir, "lottery.db"), AppConfig.DatabasePath)
+	assert.Equal(t, path.Join(configDir, "backup"), AppConfig.BackupDir)
+	assert.Equal(t, IntegrityWarn, AppConfig.Integrity)
+	assert.Equal(t, 5*time.Second, AppConfig.BusyTimeout)
+	assert.False(t, AppConfig.RequireToken)
+	assert.Equal(t, DefaultHost, AppConfig.Host)
+	assert.Equal(t, 0, AppConfig.Port)
+	assert.True(t, AppConfig.OpenBrowser)
+	assert.False(t, AppConfig.TLSSelfSigned)
+	assert.Equal(t, path.Join(configDir, "tls"), AppConfig.TLSDir)
+	assert.Equal(t, DefaultShutdownTimeout, AppConfig.ShutdownTimeout)
+	assert.Equal(t, time.Minute, AppConfig.ReadTimeout)
+	assert.Equal(t, time.Minute, AppConfig.WriteTimeout)
+	assert.Equal(t, int64(32), AppConfig.MaxUploadMB)
+	assert.Equal(t, 1.0, AppConfig.RateLimit)
+	assert.Equal(t, 10, AppConfig.RateBurst)
+	assert.Equal(t, time.Minute, AppConfig.CacheTTL)
+	assert.True(t, AppConfig.Metrics)
+	assert.False(t, AppConfig.Pprof)
+	assert.Equal(t, "info", AppConfig.LogLevel)
+	assert.Equal(t, "text", AppConfig.LogFormat)
+}
+
+func TestIsIntegrityReject(t *testing.T) {
+	testcases := []struct {
+		input   string
+		want    bool
+		wantErr error
+	}{
+		{input: IntegrityWarn, want: false, wantErr: nil},
+		{input: IntegrityReject, want: true, wantErr: nil},
+		{input: "ignore", want: false, wantErr: ErrIntegrityMode},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, gotErr := IsIntegrityReject(tc.input)
+			assert.ErrorIs(t, gotErr, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
 }
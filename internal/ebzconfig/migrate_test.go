@@ -0,0 +1,157 @@
+package ebzconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureDB returns a database file loaded from a SQL fixture in testdata
+func fixtureDB(t *testing.T, fixture string) string {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "lottery.db")
+	db, err := sqlops.NewSQLiteFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(string(content)); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestMigrateDB(t *testing.T) {
+	ctx := context.TODO()
+	testcases := []struct {
+		name    string
+		file    string
+		draws   int
+		invalid int
+	}{
+		{name: "new database", file: filepath.Join(t.TempDir(), "lottery.db"), draws: 0, invalid: 0},
+		{name: "database before migrations", file: fixtureDB(t, "lottery-v0.sql"), draws: 3, invalid: 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Migrating twice applies each migration once
+			for range 2 {
+				if err := migrateDB(ctx, tc.file, true); err != nil {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+				}
+			}
+
+			db, err := sqlops.NewSQLiteFile(tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			statuses, err := sqlops.MigrationStatuses(ctx, db, Schemas...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range statuses {
+				assert.True(t, s.Applied(), "%s version %d", s.Schema, s.Version)
+			}
+
+			tballDraws, err := tball.ListAllDraws(ctx, db)
+			assert.NoError(t, err)
+			assert.Len(t, tballDraws, tc.draws)
+			euroDraws, err := euro.ListAllDraws(ctx, db)
+			assert.NoError(t, err)
+			assert.Len(t, euroDraws, tc.draws)
+			lottoDraws, err := lotto.ListAllDraws(ctx, db)
+			assert.NoError(t, err)
+			assert.Len(t, lottoDraws, tc.draws)
+			sflifeDraws, err := sflife.ListAllDraws(ctx, db)
+			assert.NoError(t, err)
+			assert.Len(t, sflifeDraws, tc.draws)
+
+			// Dates are stored as YYYY-MM-DD and indexed
+			var notISO int
+			if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tball WHERE length(draw_date) <> 10`).Scan(&notISO); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 0, notISO)
+			var indexes int
+			if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name LIKE '%_draw_date'`).Scan(&indexes); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 4, indexes) // One per game
+
+			// Draws failing the constraints are kept aside
+			var invalid int
+			if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'euro_invalid'`).Scan(&invalid); err != nil {
+				t.Fatal(err)
+			}
+			if invalid > 0 {
+				if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM euro_invalid`).Scan(&invalid); err != nil {
+					t.Fatal(err)
+				}
+			}
+			assert.Equal(t, tc.invalid, invalid)
+		})
+	}
+}
+
+func TestMigrateDBManual(t *testing.T) {
+	ctx := context.TODO()
+	file := fixtureDB(t, "lottery-v0.sql")
+	if err := migrateDB(ctx, file, false); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+
+	db, err := sqlops.NewSQLiteFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	statuses, err := sqlops.MigrationStatuses(ctx, db, Schemas...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		assert.False(t, s.Applied(), "%s version %d", s.Schema, s.Version)
+	}
+}
+
+func TestMigrateDBLocked(t *testing.T) {
+	ctx := context.TODO()
+	file := fixtureDB(t, "lottery-v0.sql")
+
+	oldWait := migrationLockWait
+	migrationLockWait = 100 * time.Millisecond
+	t.Cleanup(func() { migrationLockWait = oldWait })
+
+	release, err := LockMigrations(ctx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateDB(ctx, file, true); !errors.Is(err, sqlops.ErrLocked) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrLocked, err)
+	}
+
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateDB(ctx, file, true); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	_, err = os.Stat(file + ".migrate.lock")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,25 @@
+-- A database written by ebz persists before schema migrations, with a euro
+-- draw of duplicate balls persisted in warn mode
+BEGIN TRANSACTION;
+CREATE TABLE tball (
+        draw_date INTEGER, day_of_week INTEGER, ball1 INTEGER, ball2 INTEGER, ball3 INTEGER, ball4 INTEGER, ball5 INTEGER, tball INTEGER, ball_set INTEGER,machine TEXT,draw_no INTEGER PRIMARY KEY);
+INSERT INTO tball VALUES('2026-02-17 00:00:00 +0000 UTC',2,14,29,32,36,38,11,'T9','Excalibur6',3854);
+INSERT INTO tball VALUES('2026-02-18 00:00:00 +0000 UTC',3,11,15,17,27,34,6,'T9','Excalibur6',3855);
+INSERT INTO tball VALUES('2026-02-20 00:00:00 +0000 UTC',5,1,3,4,8,11,3,'T9','Excalibur6',3856);
+CREATE TABLE euro (
+        draw_date INTEGER, day_of_week INTEGER, ball1 INTEGER, ball2 INTEGER, ball3 INTEGER, ball4 INTEGER, ball5 INTEGER, star1 INTEGER, star2 INTEGER, uk_maker TEXT, eu_maker TEXT, ball_set TEXT, machine TEXT, draw_no INTEGER PRIMARY KEY);
+INSERT INTO euro VALUES('2023-09-22 00:00:00 +0000 UTC',5,3,23,24,34,35,5,8,'HNRB16622','','','',1670);
+INSERT INTO euro VALUES('2023-09-26 00:00:00 +0000 UTC',2,2,6,14,19,23,5,7,'VPRC26636','','','',1671);
+INSERT INTO euro VALUES('2023-09-29 00:00:00 +0000 UTC',5,9,11,13,21,32,2,7,'HQSB24670','','','',1672);
+INSERT INTO euro VALUES('2023-10-03 00:00:00 +0000 UTC',2,9,9,13,21,32,2,7,'HQSB24671','','','',1673);
+CREATE TABLE lotto (
+        draw_date INTEGER, day_of_week INTEGER, ball1 INTEGER, ball2 INTEGER, ball3 INTEGER, ball4 INTEGER, ball5 INTEGER, ball6 INTEGER, bonus_ball INTEGER, ball_set TEXT, machine TEXT, draw_no INTEGER PRIMARY KEY);
+INSERT INTO lotto VALUES('2026-02-11 00:00:00 +0000 UTC',3,5,11,28,30,47,53,52,'L9','Lotto2',3145);
+INSERT INTO lotto VALUES('2026-02-14 00:00:00 +0000 UTC',6,10,13,27,50,54,56,14,'L9','Lotto2',3146);
+INSERT INTO lotto VALUES('2026-02-18 00:00:00 +0000 UTC',3,1,11,12,13,18,49,33,'L10','Lotto4',3147);
+CREATE TABLE sflife (
+        draw_date INTEGER, day_of_week INTEGER, ball1 INTEGER, ball2 INTEGER, ball3 INTEGER, ball4 INTEGER, ball5 INTEGER, lball INTEGER,ball_set TEXT,machine TEXT,draw_no INTEGER PRIMARY KEY);
+INSERT INTO sflife VALUES('2026-02-12 00:00:00 +0000 UTC',4,6,26,30,32,36,10,'SFL6','Excalibur5',722);
+INSERT INTO sflife VALUES('2026-02-16 00:00:00 +0000 UTC',1,12,23,28,30,43,9,'SFL3','Excalibur6',723);
+INSERT INTO sflife VALUES('2026-02-19 00:00:00 +0000 UTC',4,5,9,13,34,45,8,'SFL3','Excalibur6',724);
+COMMIT;
@@ -0,0 +1,2 @@
+// Package ebzrender contains operations to render draws, frequencies and other command results in supported output formats.
+package ebzrender
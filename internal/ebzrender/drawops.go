@@ -0,0 +1,32 @@
+package ebzrender
+
+import (
+	"fmt"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+func drawopsTabular(v any, compact bool) (tabular, bool) {
+	switch v := v.(type) {
+	case []drawops.Gap:
+		t := tabular{header: []string{"ball", "current", "longest", "average", "expected"}}
+		for _, g := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(g.Ball), fmt.Sprint(g.Current), fmt.Sprint(g.Longest), formatExpected(g.Average, compact), formatExpected(g.Expected, compact)})
+		}
+		return t, true
+	case drawops.Trend:
+		t := tabular{header: []string{"period", "draws"}}
+		for _, b := range v.Balls {
+			t.header = append(t.header, fmt.Sprint(b))
+		}
+		for _, p := range v.Periods {
+			row := []string{p.Period, fmt.Sprint(p.Draws)}
+			for _, f := range p.Frequency {
+				row = append(row, fmt.Sprint(f))
+			}
+			t.rows = append(t.rows, row)
+		}
+		return t, true
+	}
+	return tabular{}, false
+}
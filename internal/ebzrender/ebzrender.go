@@ -0,0 +1,45 @@
+package ebzrender
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrFormat = errors.New("unsupported output format")
+	ErrType   = errors.New("unsupported type")
+	ErrRender = errors.New("unable to render")
+)
+
+// Format is an output format
+type Format string
+
+const (
+	Table  Format = "table"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+	YAML   Format = "yaml"
+)
+
+// Formats lists the supported output formats
+var Formats = []Format{Table, JSON, NDJSON, CSV, YAML}
+
+// ParseFormat converts the name of a format to Format
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s, expected one of %v", ErrFormat, name, Formats)
+}
+
+// Tabular is implemented by values rendered as a table or CSV that are not
+// draws, frequencies or violations of a game
+type Tabular interface {
+	// Header returns the column names
+	Header() []string
+	// Rows returns the cells of each row
+	Rows() [][]string
+}
@@ -0,0 +1,51 @@
+package ebzrender
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/euro"
+)
+
+func euroTabular(v any, compact bool) (tabular, bool) {
+	switch v := v.(type) {
+	case euro.Draw:
+		return euroDraws([]euro.Draw{v}, compact), true
+	case []euro.Draw:
+		return euroDraws(v, compact), true
+	case []euro.BallFrequency:
+		t := tabular{header: []string{"ball", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.Ball), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []euro.StarFrequency:
+		t := tabular{header: []string{"star", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.Star), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []euro.Violation:
+		t := tabular{header: []string{"draw_no", "related", "error"}}
+		for _, vl := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(vl.DrawNo), formatRelated(vl.Related), errorText(vl.Err)})
+		}
+		return t, true
+	}
+	return tabular{}, false
+}
+
+func euroDraws(draws []euro.Draw, compact bool) tabular {
+	if compact {
+		t := tabular{header: []string{"draw_no", "draw_date", "day", "balls", "stars", "uk_maker", "ball_set", "machine"}}
+		for _, d := range draws {
+			t.rows = append(t.rows, []string{fmt.Sprint(d.DrawNo), d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), joinBalls(d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5), joinBalls(d.Star1, d.Star2), d.UKMaker, d.BallSet, d.Machine})
+		}
+		return t
+	}
+	t := tabular{header: []string{"draw_date", "day_of_week", "ball1", "ball2", "ball3", "ball4", "ball5", "star1", "star2", "uk_maker", "eu_maker", "ball_set", "machine", "draw_no"}}
+	for _, d := range draws {
+		t.rows = append(t.rows, []string{d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), fmt.Sprint(d.Ball1), fmt.Sprint(d.Ball2), fmt.Sprint(d.Ball3), fmt.Sprint(d.Ball4), fmt.Sprint(d.Ball5), fmt.Sprint(d.Star1), fmt.Sprint(d.Star2), d.UKMaker, d.EUMaker, d.BallSet, d.Machine, fmt.Sprint(d.DrawNo)})
+	}
+	return t
+}
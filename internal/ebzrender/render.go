@@ -0,0 +1,181 @@
+package ebzrender
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// tabular holds the header and rows of a table
+type tabular struct {
+	header []string
+	rows   [][]string
+}
+
+// tabularFunc converts a value of a game to a table. compact selects a
+// layout for display, otherwise one column per field. ok is false if the
+// type of the value is not of the game.
+type tabularFunc func(v any, compact bool) (t tabular, ok bool)
+
+var tabularFuncs = []tabularFunc{drawopsTabular, euroTabular, lottoTabular, sflifeTabular, tballTabular}
+
+// Render writes v to w in the format
+func Render(w io.Writer, format Format, v any) error {
+	switch format {
+	case Table:
+		t, err := tabularOf(v, true)
+		if err != nil {
+			return err
+		}
+		return writeTable(w, t)
+	case CSV:
+		t, err := tabularOf(v, false)
+		if err != nil {
+			return err
+		}
+		return writeCSV(w, t)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+		return nil
+	case NDJSON:
+		return writeNDJSON(w, v)
+	case YAML:
+		return writeYAML(w, v)
+	default:
+		return fmt.Errorf("%w: %s", ErrFormat, format)
+	}
+}
+
+func tabularOf(v any, compact bool) (tabular, error) {
+	if t, ok := v.(Tabular); ok {
+		return tabular{header: t.Header(), rows: t.Rows()}, nil
+	}
+	for _, fn := range tabularFuncs {
+		if t, ok := fn(v, compact); ok {
+			return t, nil
+		}
+	}
+	return tabular{}, fmt.Errorf("%w: %T", ErrType, v)
+}
+
+func writeTable(w io.Writer, t tabular) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(t.header))
+	for i, h := range t.header {
+		header[i] = strings.ToUpper(strings.ReplaceAll(h, "_", " "))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, t tabular) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.header); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	if err := cw.WriteAll(t.rows); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	return nil
+}
+
+// writeNDJSON writes each element of a slice as a line of JSON, or v as a
+// single line if it is not a slice
+func writeNDJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+		return nil
+	}
+	for i := range rv.Len() {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+	}
+	return nil
+}
+
+// writeYAML writes v in block style YAML with the field names and order
+// of its JSON encoding
+func writeYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRender, err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func blockStyle(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// joinBalls formats ball numbers separated by spaces
+func joinBalls(balls ...uint8) string {
+	s := make([]string, len(balls))
+	for i, b := range balls {
+		s[i] = fmt.Sprint(b)
+	}
+	return strings.Join(s, " ")
+}
+
+func formatExpected(expected float64, compact bool) string {
+	if compact {
+		return fmt.Sprintf("%.2f", expected)
+	}
+	return strconv.FormatFloat(expected, 'f', -1, 64)
+}
+
+func formatRelated(drawNo uint64) string {
+	if drawNo == 0 {
+		return ""
+	}
+	return fmt.Sprint(drawNo)
+}
+
+// errorText returns the message of err on a single line
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return strings.ReplaceAll(err.Error(), "\n", "; ")
+}
@@ -0,0 +1,160 @@
+package ebzrender
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+var testDraws = []euro.Draw{
+	{
+		DrawDate:  time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC),
+		DayOfWeek: time.Friday,
+		Ball1:     13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35,
+		Star1: 5, Star2: 9,
+		UKMaker: "ZDTF34718",
+		BallSet: "21",
+		Machine: "13",
+		DrawNo:  1922,
+	},
+}
+
+type testSummary struct {
+	Name string `json:"name"`
+}
+
+func (s testSummary) Header() []string { return []string{"name"} }
+func (s testSummary) Rows() [][]string { return [][]string{{s.Name}} }
+
+func TestRender(t *testing.T) {
+	testcases := []struct {
+		name    string
+		format  Format
+		input   any
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "table",
+			format: Table,
+			input:  testDraws,
+			want: "DRAW NO  DRAW DATE   DAY     BALLS           STARS  UK MAKER   BALL SET  MACHINE\n" +
+				"1922     2026-02-20  Friday  13 24 28 33 35  5 9    ZDTF34718  21        13\n",
+		},
+		{
+			name:   "csv",
+			format: CSV,
+			input:  testDraws,
+			want: "draw_date,day_of_week,ball1,ball2,ball3,ball4,ball5,star1,star2,uk_maker,eu_maker,ball_set,machine,draw_no\n" +
+				"2026-02-20,Friday,13,24,28,33,35,5,9,ZDTF34718,,21,13,1922\n",
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			input:  []euro.StarFrequency{{Star: 1, Frequency: 2, Expected: 1.5}, {Star: 2, Frequency: 0, Expected: 1.5}},
+			want:   "{\"Star\":1,\"Frequency\":2,\"Expected\":1.5}\n{\"Star\":2,\"Frequency\":0,\"Expected\":1.5}\n",
+		},
+		{
+			name:   "violations",
+			format: NDJSON,
+			input:  []euro.Violation{{DrawNo: 2, Related: 1, Err: euro.ErrDrawOrder}},
+			want:   "{\"draw_no\":2,\"related\":1,\"error\":\"draw number out of order with draw date\"}\n",
+		},
+		{
+			name:   "json",
+			format: JSON,
+			input:  testSummary{Name: "euro"},
+			want:   "{\n  \"name\": \"euro\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: YAML,
+			input:  []euro.BallFrequency{{Ball: 1, Frequency: 2, Expected: 0.5}},
+			want:   "- Ball: 1\n  Frequency: 2\n  Expected: 0.5\n",
+		},
+		{
+			name:   "yaml keeps numeric strings",
+			format: YAML,
+			input:  testSummary{Name: "21"},
+			want:   "name: \"21\"\n",
+		},
+		{
+			name:   "tabular",
+			format: Table,
+			input:  testSummary{Name: "euro"},
+			want:   "NAME\neuro\n",
+		},
+		{
+			name:   "gaps",
+			format: CSV,
+			input:  []drawops.Gap{{Ball: 1, Current: 2, Longest: 9, Average: 4.5, Expected: 9}},
+			want:   "ball,current,longest,average,expected\n1,2,9,4.5,9\n",
+		},
+		{
+			name:   "trend",
+			format: Table,
+			input:  drawops.Trend{Balls: []uint{1, 2}, Periods: []drawops.PeriodFrequency{{Period: "2026-02", Draws: 8, Frequency: []uint{1, 3}}}},
+			want:   "PERIOD   DRAWS  1  2\n2026-02  8      1  3\n",
+		},
+		{
+			name:    "unsupported type",
+			format:  CSV,
+			input:   map[string]int{},
+			wantErr: ErrType,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			input:   testDraws,
+			wantErr: ErrFormat,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			gotErr := Render(&b, tc.format, tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.want, b.String())
+			}
+		})
+	}
+}
+
+func TestRenderGameTypes(t *testing.T) {
+	inputs := []any{
+		euro.Draw{}, []euro.Draw{}, []euro.BallFrequency{}, []euro.StarFrequency{}, []euro.Violation{},
+		lotto.Draw{}, []lotto.Draw{}, []lotto.BallFrequency{}, []lotto.BonusFrequency{}, []lotto.Violation{},
+		sflife.Draw{}, []sflife.Draw{}, []sflife.BallFrequency{}, []sflife.LBallFrequency{}, []sflife.Violation{},
+		tball.Draw{}, []tball.Draw{}, []tball.BallFrequency{}, []tball.TBallFrequency{}, []tball.Violation{},
+	}
+	for _, input := range inputs {
+		for _, format := range Formats {
+			var b bytes.Buffer
+			if err := Render(&b, format, input); err != nil {
+				t.Errorf("%s %T: %v", format, input, err)
+			}
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		got, err := ParseFormat(string(f))
+		assert.NoError(t, err)
+		assert.Equal(t, f, got)
+	}
+	_, err := ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrFormat)
+}
@@ -0,0 +1,51 @@
+package ebzrender
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+)
+
+func sflifeTabular(v any, compact bool) (tabular, bool) {
+	switch v := v.(type) {
+	case sflife.Draw:
+		return sflifeDraws([]sflife.Draw{v}, compact), true
+	case []sflife.Draw:
+		return sflifeDraws(v, compact), true
+	case []sflife.BallFrequency:
+		t := tabular{header: []string{"ball", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.Ball), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []sflife.LBallFrequency:
+		t := tabular{header: []string{"life_ball", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.LBall), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []sflife.Violation:
+		t := tabular{header: []string{"draw_no", "related", "error"}}
+		for _, vl := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(vl.DrawNo), formatRelated(vl.Related), errorText(vl.Err)})
+		}
+		return t, true
+	}
+	return tabular{}, false
+}
+
+func sflifeDraws(draws []sflife.Draw, compact bool) tabular {
+	if compact {
+		t := tabular{header: []string{"draw_no", "draw_date", "day", "balls", "life_ball", "ball_set", "machine"}}
+		for _, d := range draws {
+			t.rows = append(t.rows, []string{fmt.Sprint(d.DrawNo), d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), joinBalls(d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5), joinBalls(d.LBall), d.BallSet, d.Machine})
+		}
+		return t
+	}
+	t := tabular{header: []string{"draw_date", "day_of_week", "ball1", "ball2", "ball3", "ball4", "ball5", "lball", "ball_set", "machine", "draw_no"}}
+	for _, d := range draws {
+		t.rows = append(t.rows, []string{d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), fmt.Sprint(d.Ball1), fmt.Sprint(d.Ball2), fmt.Sprint(d.Ball3), fmt.Sprint(d.Ball4), fmt.Sprint(d.Ball5), fmt.Sprint(d.LBall), d.BallSet, d.Machine, fmt.Sprint(d.DrawNo)})
+	}
+	return t
+}
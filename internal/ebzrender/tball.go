@@ -0,0 +1,51 @@
+package ebzrender
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/tball"
+)
+
+func tballTabular(v any, compact bool) (tabular, bool) {
+	switch v := v.(type) {
+	case tball.Draw:
+		return tballDraws([]tball.Draw{v}, compact), true
+	case []tball.Draw:
+		return tballDraws(v, compact), true
+	case []tball.BallFrequency:
+		t := tabular{header: []string{"ball", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.Ball), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []tball.TBallFrequency:
+		t := tabular{header: []string{"thunderball", "frequency", "expected"}}
+		for _, f := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(f.TBall), fmt.Sprint(f.Frequency), formatExpected(f.Expected, compact)})
+		}
+		return t, true
+	case []tball.Violation:
+		t := tabular{header: []string{"draw_no", "related", "error"}}
+		for _, vl := range v {
+			t.rows = append(t.rows, []string{fmt.Sprint(vl.DrawNo), formatRelated(vl.Related), errorText(vl.Err)})
+		}
+		return t, true
+	}
+	return tabular{}, false
+}
+
+func tballDraws(draws []tball.Draw, compact bool) tabular {
+	if compact {
+		t := tabular{header: []string{"draw_no", "draw_date", "day", "balls", "thunderball", "ball_set", "machine"}}
+		for _, d := range draws {
+			t.rows = append(t.rows, []string{fmt.Sprint(d.DrawNo), d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), joinBalls(d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5), joinBalls(d.TBall), d.BallSet, d.Machine})
+		}
+		return t
+	}
+	t := tabular{header: []string{"draw_date", "day_of_week", "ball1", "ball2", "ball3", "ball4", "ball5", "tball", "ball_set", "machine", "draw_no"}}
+	for _, d := range draws {
+		t.rows = append(t.rows, []string{d.DrawDate.Format(time.DateOnly), d.DayOfWeek.String(), fmt.Sprint(d.Ball1), fmt.Sprint(d.Ball2), fmt.Sprint(d.Ball3), fmt.Sprint(d.Ball4), fmt.Sprint(d.Ball5), fmt.Sprint(d.TBall), d.BallSet, d.Machine, fmt.Sprint(d.DrawNo)})
+	}
+	return t
+}
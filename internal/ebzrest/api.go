@@ -0,0 +1,151 @@
+package ebzrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultPrefix is the path the /api/v1 endpoints are mounted at unless
+// WithPrefix gives another
+const DefaultPrefix = "/api/v1"
+
+// Envelope wraps the data of every successful /api/v1 response
+type Envelope[T any] struct {
+	Data T     `json:"data"`
+	Meta *Meta `json:"meta,omitempty"`
+}
+
+// Meta describes the page of a list in an Envelope. NextCursor, when set,
+// is the cursor query parameter requesting the following page.
+type Meta struct {
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// response is the outcome of an endpoint, written by the API serving it
+type response struct {
+	status int         // Status of the response
+	header http.Header // Headers of the response, besides its content type
+	data   any         // Data of the response. nil for no body
+	meta   *Meta       // Page of a list. nil for other data
+	// modified is when the data was computed, if it was cached
+	modified time.Time
+	// legacy returns the deprecated response in place of this one. nil
+	// for the same response.
+	legacy func() (response, error)
+}
+
+// endpoint serves a request of both the /api/v1 and deprecated routes
+type endpoint func(req *http.Request) (response, error)
+
+// v1 serves the endpoint with the data in an Envelope and errors as
+// problem details
+func (r RESTFul) v1(e endpoint) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		res, err := e(req)
+		if err != nil {
+			writeProblem(rw, req, r.prefix, err)
+			return
+		}
+		copyHeader(rw, res.header)
+		if res.data == nil {
+			rw.WriteHeader(res.status)
+			return
+		}
+		writeJSON(rw, req, res, Envelope[any]{Data: res.data, Meta: res.meta})
+	}
+}
+
+// legacyPage is the deprecated response of a list of draws
+type legacyPage struct {
+	Draws      any    `json:"draws"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// legacy serves the endpoint on a deprecated route, with bare data and
+// plain text errors. The response links the /api/v1 route succeeding it.
+func (r RESTFul) legacy(e endpoint) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		successor := r.prefix + req.URL.Path
+		if req.URL.RawQuery != "" {
+			successor += "?" + req.URL.RawQuery
+		}
+		rw.Header().Set("Deprecation", "true")
+		rw.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+
+		res, err := e(req)
+		if err == nil && res.legacy != nil {
+			res, err = res.legacy()
+		}
+		if err != nil {
+			writeText(rw, req, err)
+			return
+		}
+		copyHeader(rw, res.header)
+		if res.data == nil {
+			rw.WriteHeader(res.status)
+			return
+		}
+		if res.meta != nil {
+			writeJSON(rw, req, res, legacyPage{Draws: res.data, NextCursor: res.meta.NextCursor})
+			return
+		}
+		writeJSON(rw, req, res, res.data)
+	}
+}
+
+// stream serves a request of the /api/v1 routes by writing the response
+// itself. Errors are returned only before anything is written.
+type stream func(rw http.ResponseWriter, req *http.Request) error
+
+// v1Stream serves the stream with errors as problem details
+func (r RESTFul) v1Stream(s stream) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if err := s(rw, req); err != nil {
+			writeProblem(rw, req, r.prefix, err)
+		}
+	}
+}
+
+// copyHeader adds the headers to the response
+func copyHeader(rw http.ResponseWriter, header http.Header) {
+	for k, vs := range header {
+		for _, v := range vs {
+			rw.Header().Add(k, v)
+		}
+	}
+}
+
+// writeJSON writes v as the JSON body of the response. Successful
+// responses to GET requests are validated by an ETag of the body, and by
+// Last-Modified when the data was cached, and answered with 304 Not
+// Modified when the client has them. Large bodies are compressed with gzip
+// for clients accepting it.
+func writeJSON(rw http.ResponseWriter, req *http.Request, res response, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeText(rw, req, fmt.Errorf("%w: %w", ErrInternal, err))
+		return
+	}
+	body = append(body, '\n')
+
+	h := rw.Header()
+	h.Set("Content-Type", "application/json")
+	if req.Method == http.MethodGet && res.status == http.StatusOK {
+		etag := etagOf(body)
+		h.Set("ETag", etag)
+		h.Set("Cache-Control", "no-cache")
+		if !res.modified.IsZero() {
+			h.Set("Last-Modified", res.modified.UTC().Format(http.TimeFormat))
+		}
+		if notModified(req, etag, res.modified) {
+			h.Del("Content-Type")
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeBody(rw, req, res.status, body)
+}
@@ -0,0 +1,185 @@
+package ebzrest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIV1(t *testing.T) {
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory())
+
+	t.Run("Envelope", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/tball/draw/frequency", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Empty(t, rr.Header().Get("Deprecation"))
+		var got ebzrest.Envelope[[]tball.BallFrequency]
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Len(t, got.Data, tball.MaxBall())
+		assert.Nil(t, got.Meta)
+	})
+
+	t.Run("Deprecated alias", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/tball/draw/frequency", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+		assert.Equal(t, `</api/v1/tball/draw/frequency>; rel="successor-version"`, rr.Header().Get("Link"))
+		var got []tball.BallFrequency
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Len(t, got, tball.MaxBall())
+	})
+
+	testcases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		want        ebzrest.Problem
+	}{
+		{
+			name:   "draw not found",
+			method: "GET",
+			target: "/api/v1/euro/draws/7",
+			want:   ebzrest.Problem{Type: "/api/v1/problems/draw-not-found", Title: "Draw not found", Status: http.StatusNotFound, Instance: "/api/v1/euro/draws/7"},
+		},
+		{
+			name:   "invalid filter",
+			method: "GET",
+			target: "/api/v1/lotto/draws?from=yesterday",
+			want:   ebzrest.Problem{Type: "/api/v1/problems/invalid-filter", Title: "Invalid filter", Status: http.StatusBadRequest, Instance: "/api/v1/lotto/draws"},
+		},
+		{
+			name:   "invalid body",
+			method: "PUT",
+			target: "/api/v1/sflife/draws/7",
+			body:   "{",
+			want:   ebzrest.Problem{Type: "/api/v1/problems/invalid-request", Title: "Invalid request", Status: http.StatusBadRequest, Instance: "/api/v1/sflife/draws/7"},
+		},
+		{
+			name:        "invalid draw",
+			method:      "PUT",
+			target:      "/api/v1/tball/draws/7",
+			contentType: "application/json",
+			body:        `{"draw_date":"2026-02-21T00:00:00Z","ball1":1,"ball2":1,"ball3":3,"ball4":4,"ball5":5,"tball":6}`,
+			want:        ebzrest.Problem{Type: "/api/v1/problems/invalid-draw", Title: "Invalid draw", Status: http.StatusUnprocessableEntity, Instance: "/api/v1/tball/draws/7"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.Status, rr.Code)
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			var got ebzrest.Problem
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.NotEmpty(t, got.Detail)
+			got.Detail = ""
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAPIServerError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _ := logops.New(buf, "info", "json")
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logger)
+
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	mux := http.NewServeMux()
+	ebzrest.New(mux, stores)
+	h := ebzrest.AccessLog(mux)
+
+	testcases := []struct {
+		name        string
+		target      string
+		contentType string
+	}{
+		{name: "problem", target: "/api/v1/tball/draws", contentType: "application/problem+json"},
+		{name: "deprecated alias", target: "/tball/draws", contentType: "text/plain; charset=utf-8"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			rr := httptest.NewRecorder()
+
+			h.ServeHTTP(rr, httptest.NewRequest("GET", tc.target, nil))
+
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Body.String(), "Database error")
+			assert.NotContains(t, rr.Body.String(), "closed")
+
+			var got struct {
+				Msg       string `json:"msg"`
+				RequestID string `json:"request_id"`
+				Error     struct {
+					Msg string `json:"msg"`
+				} `json:"error"`
+			}
+			line, _, _ := bytes.Cut(buf.Bytes(), []byte("\n"))
+			assert.NoError(t, json.Unmarshal(line, &got))
+			assert.Equal(t, "request failed", got.Msg)
+			assert.Equal(t, rr.Header().Get("X-Request-ID"), got.RequestID)
+			assert.Contains(t, got.Error.Msg, "closed")
+		})
+	}
+}
+
+func TestAPIPrefix(t *testing.T) {
+	testcases := []struct {
+		name       string
+		prefix     string
+		target     string
+		wantStatus int
+	}{
+		{name: "custom prefix", prefix: "/lottery/v1/", target: "/lottery/v1/euro/draws", wantStatus: http.StatusOK},
+		{name: "default prefix unmounted", prefix: "/lottery/v1", target: "/api/v1/euro/draws", wantStatus: http.StatusNotFound},
+		{name: "deprecated alias", prefix: "/lottery/v1", target: "/euro/draws", wantStatus: http.StatusOK},
+		{name: "empty prefix", prefix: "", target: "/euro/draws", wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithPrefix(tc.prefix))
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", tc.target, nil))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package ebzrest
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+)
+
+// LoginPath and LogoutPath are the paths browsers sign in and out at when
+// the API requires tokens
+const (
+	LoginPath  = "/login"
+	LogoutPath = "/logout"
+)
+
+// SessionCookie is the cookie holding the session of a signed in browser
+const SessionCookie = "ebz_session"
+
+//go:embed login.html
+var loginPage []byte
+
+// WithTokens requires a token of the store for the routes changing draws,
+// given as a bearer token or by the session of a browser signed in at
+// LoginPath
+func WithTokens(tokens authops.TokenStore) Option {
+	return func(r *RESTFul) {
+		r.tokens = tokens
+	}
+}
+
+// WithReadOnly sets whether the routes changing draws are disabled
+func WithReadOnly(readOnly bool) Option {
+	return func(r *RESTFul) {
+		r.readOnly = readOnly
+	}
+}
+
+// guard returns the endpoint of a route changing draws: refusing every
+// request in read-only mode, otherwise requiring a token when the API
+// requires tokens
+func (r RESTFul) guard(e endpoint) endpoint {
+	if r.readOnly {
+		return func(req *http.Request) (response, error) {
+			return response{}, fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path)
+		}
+	}
+	if r.tokens == nil {
+		return e
+	}
+	return func(req *http.Request) (response, error) {
+		if err := r.authenticate(req); err != nil {
+			return response{}, err
+		}
+		return e(req)
+	}
+}
+
+// authenticate checks that the request has the secret of a stored token as
+// its bearer token, or the cookie of a session signed in with a token still
+// stored
+func (r RESTFul) authenticate(req *http.Request) error {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		secret, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return fmt.Errorf("%w: expected a bearer token", ErrUnauthorized)
+		}
+		_, err := r.tokens.VerifyToken(req.Context(), secret)
+		if errors.Is(err, authops.ErrToken) {
+			return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		}
+		return err
+	}
+
+	cookie, err := req.Cookie(SessionCookie)
+	if err != nil {
+		return fmt.Errorf("%w: no token or session", ErrUnauthorized)
+	}
+	tokenID, err := r.sessions.Lookup(cookie.Value)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+	_, err = r.tokens.GetToken(req.Context(), tokenID)
+	if errors.Is(err, authops.ErrNoToken) {
+		r.sessions.Delete(cookie.Value)
+		return fmt.Errorf("%w: token revoked", ErrUnauthorized)
+	}
+	return err
+}
+
+// serveLogin serves the page signing a browser in with a token
+func serveLogin(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Write(loginPage)
+}
+
+// login signs the browser in with the token of the form, and redirects it
+// to the local path next of the form. A wrong token redirects back to the
+// login page.
+func (r RESTFul) login(rw http.ResponseWriter, req *http.Request) {
+	next := req.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+	token, err := r.tokens.VerifyToken(req.Context(), req.FormValue("token"))
+	if errors.Is(err, authops.ErrToken) {
+		http.Redirect(rw, req, LoginPath+"?failed=1&next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		writeText(rw, req, err)
+		return
+	}
+
+	id, expires := r.sessions.Create(token.ID)
+	http.SetCookie(rw, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(rw, req, next, http.StatusSeeOther)
+}
+
+// logout ends the session of the browser and redirects it to the login page
+func (r RESTFul) logout(rw http.ResponseWriter, req *http.Request) {
+	if cookie, err := req.Cookie(SessionCookie); err == nil {
+		r.sessions.Delete(cookie.Value)
+	}
+	http.SetCookie(rw, &http.Cookie{
+		Name:     SessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(rw, req, LoginPath, http.StatusSeeOther)
+}
+
+// challenge asks the client of a request failing authentication for a
+// bearer token
+func challenge(rw http.ResponseWriter, status int) {
+	if status == http.StatusUnauthorized {
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="ebz"`)
+	}
+}
@@ -0,0 +1,185 @@
+package ebzrest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/stretchr/testify/assert"
+)
+
+const authDraw = `{"draw_date":"2026-02-21T00:00:00Z","ball1":1,"ball2":2,"ball3":3,"ball4":4,"ball5":5,"tball":6,"draw_no":3857}`
+
+func TestAuthTokens(t *testing.T) {
+	tokens := authops.NewMemStore()
+	_, secret, err := tokens.CreateToken(context.TODO(), "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithTokens(tokens))
+
+	testcases := []struct {
+		name          string
+		method        string
+		target        string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "read without token", method: "GET", target: "/api/v1/tball/draws", wantStatus: http.StatusOK},
+		{name: "upload without token", method: "POST", target: "/api/v1/tball/csv", wantStatus: http.StatusUnauthorized},
+		{name: "upload with wrong token", method: "POST", target: "/api/v1/tball/csv", authorization: "Bearer ebz_wrong", wantStatus: http.StatusUnauthorized},
+		{name: "upload with basic auth", method: "POST", target: "/api/v1/tball/csv", authorization: "Basic Y2k6Y2k=", wantStatus: http.StatusUnauthorized},
+		{name: "upload with token", method: "POST", target: "/api/v1/tball/csv", authorization: "Bearer " + secret, wantStatus: http.StatusAccepted},
+		{name: "deprecated upload without token", method: "POST", target: "/tball/csv", wantStatus: http.StatusUnauthorized},
+		{name: "delete without token", method: "DELETE", target: "/api/v1/tball/draws/3857", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader("["+authDraw+"]"))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantStatus != http.StatusUnauthorized {
+				return
+			}
+			assert.Equal(t, `Bearer realm="ebz"`, rr.Header().Get("WWW-Authenticate"))
+			if !strings.HasPrefix(tc.target, "/api/v1") {
+				return
+			}
+			var got ebzrest.Problem
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, "/api/v1/problems/unauthorized", got.Type)
+		})
+	}
+}
+
+func TestAuthSession(t *testing.T) {
+	tokens := authops.NewMemStore()
+	token, secret, err := tokens.CreateToken(context.TODO(), "browser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithTokens(tokens))
+
+	login := func(secret string, next string) *httptest.ResponseRecorder {
+		form := url.Values{"token": {secret}, "next": {next}}
+		req := httptest.NewRequest("POST", ebzrest.LoginPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	deleteDraw := func(cookie *http.Cookie) int {
+		req := httptest.NewRequest("DELETE", "/api/v1/tball/draws/1", nil)
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("Login page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", ebzrest.LoginPath, nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `name="token"`)
+	})
+
+	t.Run("Wrong token", func(t *testing.T) {
+		rr := login("ebz_wrong", "/")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/login?failed=1&next=%2F", rr.Header().Get("Location"))
+		assert.Empty(t, rr.Result().Cookies())
+	})
+
+	t.Run("Next off site", func(t *testing.T) {
+		rr := login(secret, "//example.com")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/", rr.Header().Get("Location"))
+	})
+
+	rr := login(secret, "/?game=euro")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/?game=euro", rr.Header().Get("Location"))
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Unmatch cookies. Want: 1 Got: %d", len(cookies))
+	}
+	session := cookies[0]
+	assert.Equal(t, ebzrest.SessionCookie, session.Name)
+	assert.True(t, session.HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, session.SameSite)
+
+	t.Run("Signed in", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, deleteDraw(session))
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, deleteDraw(&http.Cookie{Name: ebzrest.SessionCookie, Value: "unknown"}))
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		rr := login(secret, "/")
+		other := rr.Result().Cookies()[0]
+		req := httptest.NewRequest("POST", ebzrest.LogoutPath, nil)
+		req.AddCookie(other)
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, ebzrest.LoginPath, rr.Header().Get("Location"))
+		assert.Equal(t, http.StatusUnauthorized, deleteDraw(other))
+	})
+
+	t.Run("Token revoked", func(t *testing.T) {
+		assert.NoError(t, tokens.RevokeToken(context.TODO(), token.ID))
+		assert.Equal(t, http.StatusUnauthorized, deleteDraw(session))
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithReadOnly(true))
+
+	testcases := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "read", method: "GET", target: "/api/v1/tball/draws", wantStatus: http.StatusOK},
+		{name: "upload", method: "POST", target: "/api/v1/tball/csv", wantStatus: http.StatusForbidden},
+		{name: "import", method: "POST", target: "/api/v1/import", wantStatus: http.StatusForbidden},
+		{name: "update", method: "PUT", target: "/api/v1/tball/draws/3857", wantStatus: http.StatusForbidden},
+		{name: "delete", method: "DELETE", target: "/api/v1/tball/draws/3857", wantStatus: http.StatusForbidden},
+		{name: "deprecated upload", method: "POST", target: "/tball/csv", wantStatus: http.StatusForbidden},
+		{name: "no login without tokens", method: "GET", target: ebzrest.LoginPath, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader("["+authDraw+"]"))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ebzrest
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/cacheops"
+)
+
+// gzipMinSize is the smallest body compressed, as smaller bodies gain
+// little
+const gzipMinSize = 1024
+
+// WithCache caches the statistics, draws and lists of draws of each game
+// on the cache instead of one created by New, so that the caller can share
+// or disable it
+func WithCache(c *cacheops.Cache) Option {
+	return func(r *RESTFul) {
+		r.cache = c
+	}
+}
+
+// cached serves the route from the cache of its game, keyed by the
+// pattern, draw number and query of the request. Failures are not cached.
+func (r RESTFul) cached(rt route, e endpoint) endpoint {
+	return func(req *http.Request) (response, error) {
+		key := rt.pattern + " " + req.PathValue("drawNo") + "?" + req.URL.Query().Encode()
+		entry, err := r.cache.Get(rt.game, key, func() (any, error) {
+			return e(req)
+		})
+		if err != nil {
+			return response{}, err
+		}
+		res := entry.Value.(response)
+		res.modified = entry.Computed
+		return res, nil
+	}
+}
+
+// etagOf returns a weak ETag of the body, weak as it is the same whether
+// or not the body is compressed
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified reports whether the client of the request has the response
+// of the ETag, last modified at the time. If-None-Match takes precedence
+// over If-Modified-Since.
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// acceptsGzip reports whether the client of the request accepts gzip
+// encoded responses
+func acceptsGzip(req *http.Request) bool {
+	for _, coding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		_, q, ok := strings.Cut(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// writeBody writes the body with the status, compressed with gzip when it
+// is large and the client accepts it
+func writeBody(rw http.ResponseWriter, req *http.Request, status int, body []byte) {
+	rw.Header().Add("Vary", "Accept-Encoding")
+	if len(body) < gzipMinSize || !acceptsGzip(req) {
+		rw.WriteHeader(status)
+		rw.Write(body)
+		return
+	}
+	rw.Header().Set("Content-Encoding", "gzip")
+	rw.WriteHeader(status)
+	gz := gzip.NewWriter(rw)
+	gz.Write(body)
+	gz.Close()
+}
@@ -0,0 +1,153 @@
+package ebzrest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedInvalidation(t *testing.T) {
+	stores := ebzstore.NewMemory()
+	mux := http.NewServeMux()
+	New(mux, stores)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	count := func() int {
+		rr := serve("GET", "/api/v1/tball/draws", "")
+		var got Envelope[[]tball.Draw]
+		json.NewDecoder(rr.Body).Decode(&got)
+		return got.Meta.Count
+	}
+	draw := func(drawNo uint64) tball.Draw {
+		return tball.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(drawNo-3856)*7), DayOfWeek: time.Friday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, TBall: 6, DrawNo: drawNo}
+	}
+
+	assert.Equal(t, 0, count())
+
+	// Draws stored by another process are seen once the cache expires
+	stores.TBall.PersistDraw(context.TODO(), draw(3856))
+	assert.Equal(t, 0, count())
+
+	body, _ := json.Marshal([]tball.Draw{draw(3857)})
+	assert.Equal(t, http.StatusAccepted, serve("POST", "/tball/csv", string(body)).Code)
+	assert.Equal(t, 2, count())
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", "/api/v1/tball/draws/3856", "").Code)
+	assert.Equal(t, 1, count())
+
+	ball5 := func() uint8 {
+		var got Envelope[tball.Draw]
+		json.NewDecoder(serve("GET", "/api/v1/tball/draws/3857", "").Body).Decode(&got)
+		return got.Data.Ball5
+	}
+	assert.Equal(t, uint8(5), ball5())
+	updated := draw(3857)
+	updated.Ball5 = 9
+	body, _ = json.Marshal(updated)
+	assert.Equal(t, http.StatusOK, serve("PUT", "/api/v1/tball/draws/3857", string(body)).Code)
+	assert.Equal(t, uint8(9), ball5())
+}
+
+func TestConditionalGet(t *testing.T) {
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/tball/draw/frequency", nil))
+	etag := rr.Header().Get("ETag")
+	modified := rr.Header().Get("Last-Modified")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	assert.NotEmpty(t, modified)
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	testcases := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{name: "same etag", header: "If-None-Match", value: etag, wantStatus: http.StatusNotModified},
+		{name: "strong form of etag", header: "If-None-Match", value: strings.TrimPrefix(etag, "W/"), wantStatus: http.StatusNotModified},
+		{name: "one of etags", header: "If-None-Match", value: `"other", ` + etag, wantStatus: http.StatusNotModified},
+		{name: "other etag", header: "If-None-Match", value: `W/"other"`, wantStatus: http.StatusOK},
+		{name: "not modified since", header: "If-Modified-Since", value: modified, wantStatus: http.StatusNotModified},
+		{name: "modified since", header: "If-Modified-Since", value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/tball/draw/frequency", nil)
+			req.Header.Set(tc.header, tc.value)
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantStatus == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+				assert.Equal(t, etag, rr.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestGzip(t *testing.T) {
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+
+	testcases := []struct {
+		name           string
+		target         string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{name: "accepted", target: "/api/v1/tball/draw/frequency", acceptEncoding: "gzip, deflate, br", wantGzip: true},
+		{name: "deprecated route", target: "/tball/draw/frequency", acceptEncoding: "gzip", wantGzip: true},
+		{name: "not accepted", target: "/api/v1/tball/draw/frequency"},
+		{name: "refused", target: "/api/v1/tball/draw/frequency", acceptEncoding: "gzip;q=0, identity"},
+		{name: "small body", target: "/api/v1/tball/draws", acceptEncoding: "gzip"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.target, nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+			if !tc.wantGzip {
+				assert.Empty(t, rr.Header().Get("Content-Encoding"))
+				assert.True(t, json.Valid(rr.Body.Bytes()))
+				return
+			}
+			assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+			gz, err := gzip.NewReader(rr.Body)
+			if err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			var got any
+			assert.NoError(t, json.NewDecoder(gz).Decode(&got))
+		})
+	}
+}
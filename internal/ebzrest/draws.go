@@ -0,0 +1,135 @@
+package ebzrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+const (
+	// DefaultPageLimit is the number of draws in a page when the request
+	// gives no limit
+	DefaultPageLimit = 100
+	// MaxPageLimit is the most draws a page holds
+	MaxPageLimit = 1000
+)
+
+// parseFilter returns the filter and page of draws given by the query
+// parameters of the request: from and to (YYYY-MM-DD), from_draw and
+// to_draw, contains (comma separated balls), last, sort (date or draw_no),
+// order (asc or desc), limit, offset and cursor
+func parseFilter(req *http.Request) (drawops.Filter, error) {
+	q := req.URL.Query()
+	f := drawops.Filter{Limit: DefaultPageLimit}
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = time.Parse(time.DateOnly, v); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: from %s", drawops.ErrDateRange, v)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = time.Parse(time.DateOnly, v); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: to %s", drawops.ErrDateRange, v)
+		}
+	}
+	if v := q.Get("from_draw"); v != "" {
+		if f.FromDrawNo, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: from_draw %s", drawops.ErrDrawRange, v)
+		}
+	}
+	if v := q.Get("to_draw"); v != "" {
+		if f.ToDrawNo, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: to_draw %s", drawops.ErrDrawRange, v)
+		}
+	}
+	if v := q.Get("contains"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			b, err := strconv.ParseUint(strings.TrimSpace(s), 10, 8)
+			if err != nil {
+				return drawops.Filter{}, fmt.Errorf("%w: %s", drawops.ErrBall, s)
+			}
+			f.Contains = append(f.Contains, uint8(b))
+		}
+	}
+	if v := q.Get("last"); v != "" {
+		if f.Last, err = strconv.Atoi(v); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: %s", drawops.ErrLastDraws, v)
+		}
+	}
+	f.Sort = drawops.SortField(q.Get("sort"))
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		f.Desc = true
+	default:
+		return drawops.Filter{}, fmt.Errorf("%w: order %s", drawops.ErrSortField, q.Get("order"))
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > MaxPageLimit {
+			return drawops.Filter{}, fmt.Errorf("%w: limit %s", drawops.ErrPage, v)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil {
+			return drawops.Filter{}, fmt.Errorf("%w: offset %s", drawops.ErrPage, v)
+		}
+	}
+	if f.After, err = drawops.ParseCursor(q.Get("cursor")); err != nil {
+		return drawops.Filter{}, err
+	}
+	return f, f.Validate()
+}
+
+// listDraws returns the page of draws selected by the query parameters of
+// the request. One draw more than the limit is listed to find whether a
+// following page exists.
+func listDraws[D any](req *http.Request, list func(context.Context, drawops.Filter) ([]D, error), cursorOf func(D) drawops.Cursor) (response, error) {
+	f, err := parseFilter(req)
+	if err != nil {
+		return response{}, err
+	}
+	limit := f.Limit
+	f.Limit++
+	draws, err := list(req.Context(), f)
+	if err != nil {
+		return response{}, err
+	}
+
+	if draws == nil {
+		draws = []D{}
+	}
+	meta := &Meta{Limit: limit}
+	if len(draws) > limit {
+		meta.NextCursor = cursorOf(draws[limit-1]).String()
+		draws = draws[:limit]
+	}
+	meta.Count = len(draws)
+	return response{status: http.StatusOK, data: draws, meta: meta}, nil
+}
+
+// parseDrawNo returns the drawNo path value of the request
+func parseDrawNo(req *http.Request) (uint64, error) {
+	no, err := strconv.ParseUint(req.PathValue("drawNo"), 10, 64)
+	if err != nil || no == 0 {
+		return 0, fmt.Errorf("%w: draw number %s", ErrRequest, req.PathValue("drawNo"))
+	}
+	return no, nil
+}
+
+// checkDrawNo sets the draw number of a draw in a request body to the
+// draw number of the path, unless the body gives another draw number
+func checkDrawNo(body *uint64, path uint64) error {
+	switch *body {
+	case 0:
+		*body = path
+	case path:
+	default:
+		return fmt.Errorf("%w: draw %d in body of draw %d", ErrRequest, *body, path)
+	}
+	return nil
+}
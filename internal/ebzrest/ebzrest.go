@@ -1,34 +1,200 @@
 package ebzrest
 
 import (
-	"database/sql"
 	"net/http"
+	"strings"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/cacheops"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
 )
 
 type RESTFul struct {
-	db *sql.DB
+	stores   ebzstore.Stores
+	reject   bool
+	prefix   string
+	jobs     *jobops.Manager
+	tokens   authops.TokenStore
+	sessions *authops.Sessions
+	readOnly bool
+	cache    *cacheops.Cache
+	registry *metricops.Registry
+	metrics  *metrics
+}
+
+// Option configures the RESTFul endpoints
+type Option func(*RESTFul)
+
+// WithIntegrityReject sets whether uploads skip draws failing integrity
+// checks rather than persisting them
+func WithIntegrityReject(reject bool) Option {
+	return func(r *RESTFul) {
+		r.reject = reject
+	}
 }
 
-func New(mux *http.ServeMux, db *sql.DB) *http.ServeMux {
+// WithPrefix mounts the endpoints at the prefix instead of DefaultPrefix.
+// With an empty prefix, the endpoints replace the deprecated routes.
+func WithPrefix(prefix string) Option {
+	return func(r *RESTFul) {
+		r.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithJobs runs the import jobs of uploads on the manager instead of one
+// created by New, so that the caller can close it
+func WithJobs(m *jobops.Manager) Option {
+	return func(r *RESTFul) {
+		r.jobs = m
+	}
+}
+
+// New registers the RESTFul endpoints, serving the draws of the stores, on
+// the mux. The endpoints are mounted at the prefix, by default /api/v1, and
+// remain at their unversioned routes as deprecated aliases. Uploads are
+// imported by background jobs, reported at /jobs/{id}. The statistics and
+// draws of each game are cached until its draws change. Routes changing
+// draws are disabled in read-only mode, and otherwise require a token when
+// the API has a token store, with browsers signing in at LoginPath. The
+// OpenAPI document of the endpoints is served at OpenAPIPath and its docs
+// page at DocsPath. With a registry of metrics, imports and stored draws are
+// recorded and served at MetricsPath.
+func New(mux *http.ServeMux, stores ebzstore.Stores, opts ...Option) *http.ServeMux {
+	newRESTFul(stores, opts...).register(mux)
+	return mux
+}
+
+// newRESTFul returns the endpoints serving the draws of the stores,
+// configured by the options
+func newRESTFul(stores ebzstore.Stores, opts ...Option) RESTFul {
 	rest := RESTFul{
-		db: db,
+		stores: stores,
+		prefix: DefaultPrefix,
+	}
+	for _, opt := range opts {
+		opt(&rest)
+	}
+	if rest.jobs == nil {
+		rest.jobs = jobops.NewManager(jobops.DefaultWorkers)
+	}
+	if rest.cache == nil {
+		rest.cache = cacheops.New(cacheops.DefaultTTL)
+	}
+	if rest.tokens != nil {
+		rest.sessions = authops.NewSessions(authops.DefaultSessionTTL)
+	}
+	if rest.registry != nil {
+		rest.metrics = newMetrics(rest.registry, stores)
 	}
+	return rest
+}
 
-	mux.HandleFunc("POST /tball/csv", rest.TBallUploadCSV)
-	mux.HandleFunc("GET /tball/draw/frequency", rest.TBallDrawFrequencies)
-	mux.HandleFunc("GET /tball/tball/frequency", rest.TBallFrequencies)
+// register registers every route of the endpoints on the mux, and returns
+// the patterns registered
+func (r RESTFul) register(mux *http.ServeMux) []string {
+	patterns := []string{}
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, h)
+		patterns = append(patterns, pattern)
+	}
 
-	mux.HandleFunc("POST /euro/csv", rest.EuroUploadCSV)
-	mux.HandleFunc("GET /euro/draw/frequency", rest.EuroDrawFrequencies)
-	mux.HandleFunc("GET /euro/star/frequency", rest.EuroStarFrequencies)
+	for _, rt := range r.routes() {
+		r.handle(handle, rt)
+	}
+	handle("GET "+OpenAPIPath, serveOpenAPI(r.prefix))
+	handle("GET "+DocsPath, http.HandlerFunc(serveDocs))
+	if r.tokens != nil {
+		handle("GET "+LoginPath, http.HandlerFunc(serveLogin))
+		handle("POST "+LoginPath, http.HandlerFunc(r.login))
+		handle("POST "+LogoutPath, http.HandlerFunc(r.logout))
+	}
+	if r.registry != nil {
+		handle("GET "+MetricsPath, r.registry.Handler())
+	}
+	return patterns
+}
+
+// jobEventsPattern is the pattern of the event stream of a job, relative to
+// the prefix
+const jobEventsPattern = "GET /jobs/{id}/events"
 
-	mux.HandleFunc("POST /lotto/csv", rest.LottoUploadCSV)
-	mux.HandleFunc("GET /lotto/draw/frequency", rest.LottoDrawFrequencies)
-	mux.HandleFunc("GET /lotto/bonus/frequency", rest.LottoBonusFrequencies)
+// route is an endpoint, or a stream, and the pattern it is registered at,
+// relative to the prefix. Routes added with /api/v1 have no deprecated
+// alias. The GET routes of a game are cached until its draws change.
+type route struct {
+	pattern  string
+	endpoint endpoint
+	stream   stream
+	noAlias  bool
+	game     string
+}
 
-	mux.HandleFunc("POST /sflife/csv", rest.SFLifeUploadCSV)
-	mux.HandleFunc("GET /sflife/draw/frequency", rest.SFLifeDrawFrequencies)
-	mux.HandleFunc("GET /sflife/lball/frequency", rest.SFLifeLBallFrequencies)
+// routes lists the endpoints of the API. Each route is described by the
+// OpenAPI document served at OpenAPIPath.
+func (r RESTFul) routes() []route {
+	return []route{
+		{pattern: "POST /import", endpoint: r.importDraws},
+		{pattern: "GET /jobs/{id}", endpoint: r.jobStatus, noAlias: true},
+		{pattern: jobEventsPattern, stream: r.jobEvents, noAlias: true},
 
-	return mux
+		{pattern: "POST /tball/csv", endpoint: r.tballUploadCSV, game: "tball"},
+		{pattern: "GET /tball/draws", endpoint: r.tballDraws, game: "tball"},
+		{pattern: "GET /tball/draws/latest", endpoint: r.tballLatestDraw, game: "tball"},
+		{pattern: "GET /tball/draws/{drawNo}", endpoint: r.tballDraw, game: "tball"},
+		{pattern: "PUT /tball/draws/{drawNo}", endpoint: r.tballUpdateDraw, game: "tball"},
+		{pattern: "DELETE /tball/draws/{drawNo}", endpoint: r.tballDeleteDraw, game: "tball"},
+		{pattern: "GET /tball/draw/frequency", endpoint: r.tballDrawFrequencies, game: "tball"},
+		{pattern: "GET /tball/tball/frequency", endpoint: r.tballFrequencies, game: "tball"},
+
+		{pattern: "POST /euro/csv", endpoint: r.euroUploadCSV, game: "euro"},
+		{pattern: "GET /euro/draws", endpoint: r.euroDraws, game: "euro"},
+		{pattern: "GET /euro/draws/latest", endpoint: r.euroLatestDraw, game: "euro"},
+		{pattern: "GET /euro/draws/{drawNo}", endpoint: r.euroDraw, game: "euro"},
+		{pattern: "PUT /euro/draws/{drawNo}", endpoint: r.euroUpdateDraw, game: "euro"},
+		{pattern: "DELETE /euro/draws/{drawNo}", endpoint: r.euroDeleteDraw, game: "euro"},
+		{pattern: "GET /euro/draw/frequency", endpoint: r.euroDrawFrequencies, game: "euro"},
+		{pattern: "GET /euro/star/frequency", endpoint: r.euroStarFrequencies, game: "euro"},
+
+		{pattern: "POST /lotto/csv", endpoint: r.lottoUploadCSV, game: "lotto"},
+		{pattern: "GET /lotto/draws", endpoint: r.lottoDraws, game: "lotto"},
+		{pattern: "GET /lotto/draws/latest", endpoint: r.lottoLatestDraw, game: "lotto"},
+		{pattern: "GET /lotto/draws/{drawNo}", endpoint: r.lottoDraw, game: "lotto"},
+		{pattern: "PUT /lotto/draws/{drawNo}", endpoint: r.lottoUpdateDraw, game: "lotto"},
+		{pattern: "DELETE /lotto/draws/{drawNo}", endpoint: r.lottoDeleteDraw, game: "lotto"},
+		{pattern: "GET /lotto/draw/frequency", endpoint: r.lottoDrawFrequencies, game: "lotto"},
+		{pattern: "GET /lotto/bonus/frequency", endpoint: r.lottoBonusFrequencies, game: "lotto"},
+
+		{pattern: "POST /sflife/csv", endpoint: r.sflifeUploadCSV, game: "sflife"},
+		{pattern: "GET /sflife/draws", endpoint: r.sflifeDraws, game: "sflife"},
+		{pattern: "GET /sflife/draws/latest", endpoint: r.sflifeLatestDraw, game: "sflife"},
+		{pattern: "GET /sflife/draws/{drawNo}", endpoint: r.sflifeDraw, game: "sflife"},
+		{pattern: "PUT /sflife/draws/{drawNo}", endpoint: r.sflifeUpdateDraw, game: "sflife"},
+		{pattern: "DELETE /sflife/draws/{drawNo}", endpoint: r.sflifeDeleteDraw, game: "sflife"},
+		{pattern: "GET /sflife/draw/frequency", endpoint: r.sflifeDrawFrequencies, game: "sflife"},
+		{pattern: "GET /sflife/lball/frequency", endpoint: r.sflifeLBallFrequencies, game: "sflife"},
+	}
+}
+
+// handle registers the route on its pattern under the prefix, and on the
+// pattern itself as a deprecated route. Routes of methods other than GET
+// change draws and are guarded, and GET routes of a game are cached.
+func (r RESTFul) handle(register func(pattern string, h http.Handler), rt route) {
+	method, path, _ := strings.Cut(rt.pattern, " ")
+	if rt.stream != nil {
+		register(method+" "+r.prefix+path, r.v1Stream(rt.stream))
+		return
+	}
+	e := rt.endpoint
+	switch {
+	case method != http.MethodGet:
+		e = r.guard(e)
+	case rt.game != "":
+		e = r.cached(rt, e)
+	}
+	register(method+" "+r.prefix+path, r.v1(e))
+	if r.prefix != "" && !rt.noAlias {
+		register(rt.pattern, r.legacy(e))
+	}
 }
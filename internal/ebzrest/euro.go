@@ -1,52 +1,159 @@
 package ebzrest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 )
 
-// EuroUploadCSV handles the upload of a EuroMillions CSV file and persists the draws.
-func (r RESTFul) EuroUploadCSV(rw http.ResponseWriter, req *http.Request) {
-	file, _, err := req.FormFile("file")
-	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
+// euroUploadCSV starts a job persisting the draws of an uploaded EuroMillions CSV, JSON,
+// NDJSON or XLSX file.
+func (r RESTFul) euroUploadCSV(req *http.Request) (response, error) {
+	return r.startImport(req, r.persistEuro)
+}
 
-	recs := csvops.ExtractRec(req.Context(), file)
+// persistEuro persists the EuroMillions draws of an uploaded file in the format. The
+// cached responses of the game are invalidated once it returns.
+func (r RESTFul) persistEuro(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error) {
+	defer r.cache.Invalidate("euro")
+	recs := csvops.Extract(ctx, file, format, euro.RecordOf)
 	drawChans := euro.ProcessCSV(recs, 1)
 
+	res := ImportResult{
+		Game:    "euro",
+		Format:  string(format),
+		Records: len(drawChans),
+	}
+	draws := []euro.Draw{}
 	for _, dc := range drawChans {
 		if dc.Err != nil {
+			slog.DebugContext(ctx, "skipping record", "game", "euro", "line", dc.Line, logops.Err(dc.Err))
+			res.fail(dc.Err)
 			continue
 		}
-		_ = euro.PersistsDraw(req.Context(), r.db, dc.Draw)
+		draws = append(draws, dc.Draw)
+	}
+	report(res.progress(res.Failed))
+
+	draws, violations, err := euro.CheckImport(ctx, r.stores.Euro, draws, r.reject)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res.Violations = len(violations)
+	done := res.Records - len(draws)
+	report(res.progress(done))
+	for _, d := range draws {
+		err := r.stores.Euro.PersistDraw(ctx, d)
+		switch {
+		case err == nil:
+			res.Persisted++
+		case errors.Is(err, drawops.ErrStored):
+			res.Skipped++
+		case errors.Is(err, drawops.ErrRefused):
+			slog.DebugContext(ctx, "draw refused", "game", "euro", "draw_no", d.DrawNo, logops.Err(err))
+			res.fail(err)
+		default:
+			return ImportResult{}, err
+		}
+		done++
+		report(res.progress(done))
+	}
+	return res, nil
+}
+
+// euroDrawFrequencies returns the frequencies of EuroMillions draw balls.
+func (r RESTFul) euroDrawFrequencies(req *http.Request) (response, error) {
+	freqs, err := euro.CalculateBallFreq(req.Context(), r.stores.Euro)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: freqs}, nil
+}
+
+// euroStarFrequencies returns the frequencies of EuroMillions lucky stars.
+func (r RESTFul) euroStarFrequencies(req *http.Request) (response, error) {
+	freqs, err := euro.CalculateStarFreq(req.Context(), r.stores.Euro)
+	if err != nil {
+		return response{}, err
 	}
-	rw.WriteHeader(http.StatusAccepted)
+	return response{status: http.StatusOK, data: freqs}, nil
 }
 
-// EuroDrawFrequencies returns the frequencies of EuroMillions draw balls.
-func (r RESTFul) EuroDrawFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := euro.CalculateBallFreq(req.Context(), r.db)
+// euroDraws returns a page of the stored EuroMillions draws selected by the query
+// parameters.
+func (r RESTFul) euroDraws(req *http.Request) (response, error) {
+	return listDraws(req, r.stores.Euro.ListDraws, func(d euro.Draw) drawops.Cursor {
+		return drawops.Cursor{Date: d.DrawDate, DrawNo: d.DrawNo}
+	})
+}
+
+// euroLatestDraw returns the stored EuroMillions draw with the highest draw number.
+func (r RESTFul) euroLatestDraw(req *http.Request) (response, error) {
+	d, err := euro.LatestDraw(req.Context(), r.stores.Euro)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	return response{status: http.StatusOK, data: d}, nil
 }
 
-// EuroStarFrequencies returns the frequencies of EuroMillions lucky stars.
-func (r RESTFul) EuroStarFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := euro.CalculateStarFreq(req.Context(), r.db)
+// euroDraw returns the stored EuroMillions draw with the draw number of the path.
+func (r RESTFul) euroDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
+	}
+	d, err := r.stores.Euro.GetDraw(req.Context(), no)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// euroUpdateDraw replaces the stored EuroMillions draw with the draw number of the
+// path by the draw of the JSON body, once the draw passes euro.CheckDraw.
+func (r RESTFul) euroUpdateDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	var d euro.Draw
+	if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	if err := checkDrawNo(&d.DrawNo, no); err != nil {
+		return response{}, err
+	}
+	d.DayOfWeek = d.DrawDate.Weekday()
+	if err := euro.CheckDraw(d); err != nil {
+		return response{}, err
+	}
+	if err := r.stores.Euro.UpdateDraw(req.Context(), d); err != nil {
+		return response{}, err
+	}
+	r.cache.Invalidate("euro")
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// euroDeleteDraw removes the stored EuroMillions draw with the draw number of the
+// path.
+func (r RESTFul) euroDeleteDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	if err := r.stores.Euro.DeleteDraw(req.Context(), no); err != nil {
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	r.cache.Invalidate("euro")
+	return response{status: http.StatusNoContent}, nil
 }
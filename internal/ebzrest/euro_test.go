@@ -8,9 +8,12 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
 	"github.com/paulwizviz/lotterystat/internal/euro"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +31,9 @@ func TestEuroHandlers(t *testing.T) {
 	}
 
 	mux := http.NewServeMux()
-	ebzrest.New(mux, db)
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	ebzrest.New(mux, stores)
 
 	// Test CSV Upload
 	t.Run("Upload CSV", func(t *testing.T) {
@@ -53,6 +58,42 @@ func TestEuroHandlers(t *testing.T) {
 		assert.Equal(t, http.StatusAccepted, rr.Code)
 	})
 
+	// Test JSON Upload
+	t.Run("Upload JSON", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/euro/csv", strings.NewReader(`[{"draw_date":"2026-02-24T00:00:00Z","ball1":1,"ball2":2,"ball3":3,"ball4":4,"ball5":5,"star1":1,"star2":2,"uk_maker":"ABCD12345","draw_no":1923}]`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		latest, err := euro.LatestDraw(context.TODO(), euro.NewSQLiteStore(db))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1923), latest.DrawNo)
+	})
+
+	// Test NDJSON Upload detected by file name
+	t.Run("Upload NDJSON file", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "euro.ndjson")
+		assert.NoError(t, err)
+		_, err = io.WriteString(part, `{"draw_date":"2026-02-27T00:00:00Z","ball1":1,"ball2":2,"ball3":3,"ball4":4,"ball5":5,"star1":1,"star2":2,"uk_maker":"ABCD12345","draw_no":1924}`+"\n")
+		assert.NoError(t, err)
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/euro/csv", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		latest, err := euro.LatestDraw(context.TODO(), euro.NewSQLiteStore(db))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1924), latest.DrawNo)
+	})
+
 	// Test Ball Frequencies
 	t.Run("Get Ball Frequencies", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/euro/draw/frequency", nil)
@@ -85,3 +126,122 @@ func TestEuroHandlers(t *testing.T) {
 		assert.NotEmpty(t, freqs)
 	})
 }
+
+func TestEuroDrawResources(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, euro.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	ebzrest.New(mux, stores)
+
+	for i, date := range []time.Time{time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, time.February, 24, 0, 0, 0, 0, time.UTC)} {
+		d := euro.Draw{DrawDate: date, DayOfWeek: date.Weekday(), Ball1: uint8(i + 1), Ball2: 10, Ball3: 11, Ball4: 12, Ball5: 13, Star1: 1, Star2: 2, DrawNo: uint64(i + 1)}
+		if err := stores.Euro.PersistDraw(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Paginate draws", func(t *testing.T) {
+		var page ebzrest.Envelope[[]euro.Draw]
+		target := "/api/v1/euro/draws?limit=2"
+		for _, want := range [][]uint64{{1, 2}, {3}} {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			page = ebzrest.Envelope[[]euro.Draw]{}
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+			assert.Equal(t, want, drawNumbers(page.Data, func(d euro.Draw) uint64 { return d.DrawNo }))
+			target = "/api/v1/euro/draws?limit=2&cursor=" + page.Meta.NextCursor
+		}
+		assert.Empty(t, page.Meta.NextCursor)
+	})
+
+	testcases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantPage   []uint64
+		wantDraw   uint64
+	}{
+		{name: "list draws", method: "GET", target: "/api/v1/euro/draws", wantStatus: http.StatusOK, wantPage: []uint64{1, 2, 3}},
+		{name: "filter by date descending", method: "GET", target: "/api/v1/euro/draws?from=2026-02-20&order=desc", wantStatus: http.StatusOK, wantPage: []uint64{3, 2}},
+		{name: "filter by draw number", method: "GET", target: "/api/v1/euro/draws?from_draw=2&to_draw=2", wantStatus: http.StatusOK, wantPage: []uint64{2}},
+		{name: "filter by balls", method: "GET", target: "/api/v1/euro/draws?contains=1,10", wantStatus: http.StatusOK, wantPage: []uint64{1}},
+		{name: "sort by draw number with offset", method: "GET", target: "/api/v1/euro/draws?sort=draw_no&order=desc&offset=1", wantStatus: http.StatusOK, wantPage: []uint64{2, 1}},
+		{name: "invalid sort", method: "GET", target: "/api/v1/euro/draws?sort=ball", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: "GET", target: "/api/v1/euro/draws?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "invalid cursor", method: "GET", target: "/api/v1/euro/draws?cursor=!!", wantStatus: http.StatusBadRequest},
+		{name: "latest draw", method: "GET", target: "/api/v1/euro/draws/latest", wantStatus: http.StatusOK, wantDraw: 3},
+		{name: "draw", method: "GET", target: "/api/v1/euro/draws/2", wantStatus: http.StatusOK, wantDraw: 2},
+		{name: "missing draw", method: "GET", target: "/api/v1/euro/draws/99", wantStatus: http.StatusNotFound},
+		{name: "invalid draw number", method: "GET", target: "/api/v1/euro/draws/two", wantStatus: http.StatusBadRequest},
+		{name: "update draw", method: "PUT", target: "/api/v1/euro/draws/2", body: `{"draw_date":"2026-02-20T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":2,"draw_no":0}`, wantStatus: http.StatusOK, wantDraw: 2},
+		{name: "update other draw number", method: "PUT", target: "/api/v1/euro/draws/2", body: `{"draw_date":"2026-02-20T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":2,"draw_no":3}`, wantStatus: http.StatusBadRequest},
+		{name: "update repeated balls", method: "PUT", target: "/api/v1/euro/draws/2", body: `{"draw_date":"2026-02-20T00:00:00Z","ball1":10,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":2,"draw_no":2}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "update ball outside pool", method: "PUT", target: "/api/v1/euro/draws/2", body: `{"draw_date":"2026-02-20T00:00:00Z","ball1":51,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":2,"draw_no":2}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "update missing draw", method: "PUT", target: "/api/v1/euro/draws/99", body: `{"draw_date":"2026-02-20T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":2,"draw_no":0}`, wantStatus: http.StatusNotFound},
+		{name: "delete draw", method: "DELETE", target: "/api/v1/euro/draws/1", wantStatus: http.StatusNoContent},
+		{name: "delete missing draw", method: "DELETE", target: "/api/v1/euro/draws/1", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantStatus >= http.StatusBadRequest {
+				assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			}
+			if tc.wantPage != nil {
+				var page ebzrest.Envelope[[]euro.Draw]
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+				assert.Equal(t, tc.wantPage, drawNumbers(page.Data, func(d euro.Draw) uint64 { return d.DrawNo }))
+			}
+			if tc.wantDraw > 0 {
+				var d ebzrest.Envelope[euro.Draw]
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&d))
+				assert.Equal(t, tc.wantDraw, d.Data.DrawNo)
+			}
+		})
+	}
+}
+
+func TestEuroUpdateDrawEraRange(t *testing.T) {
+	ctx := context.TODO()
+	mux := http.NewServeMux()
+	stores := ebzstore.NewMemory()
+	ebzrest.New(mux, stores)
+
+	date := time.Date(2005, time.March, 4, 0, 0, 0, 0, time.UTC)
+	want := euro.Draw{DrawDate: date, DayOfWeek: date.Weekday(), Ball1: 1, Ball2: 10, Ball3: 11, Ball4: 12, Ball5: 13, Star1: 1, Star2: 2, DrawNo: 1}
+	if err := stores.Euro.PersistDraw(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lucky star of 12 is drawn since 2016, in 2005 the largest is 9
+	body := `{"draw_date":"2005-03-04T00:00:00Z","ball1":1,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"star1":1,"star2":12,"draw_no":1}`
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("PUT", "/api/v1/euro/draws/1", strings.NewReader(body)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+
+	got, err := stores.Euro.GetDraw(ctx, 1)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, want, got)
+}
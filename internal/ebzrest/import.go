@@ -0,0 +1,178 @@
+package ebzrest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+)
+
+// games lists the games that uploaded draws are detected for
+var games = []csvops.Game{
+	{Name: "tball", RecordOf: tball.RecordOf, Match: tball.Match},
+	{Name: "euro", RecordOf: euro.RecordOf, Match: euro.Match},
+	{Name: "lotto", RecordOf: lotto.RecordOf, Match: lotto.Match},
+	{Name: "sflife", RecordOf: sflife.RecordOf, Match: sflife.Match},
+}
+
+// maxImportErrors is the most errors of records an ImportResult lists
+const maxImportErrors = 20
+
+// ImportResult reports the outcome of persisting an uploaded file of draws.
+// Records failing processing or refused by the store are Failed, and the
+// first of their errors are listed in Errors.
+type ImportResult struct {
+	Game       string   `json:"game"`
+	Format     string   `json:"format"`
+	Records    int      `json:"records"`
+	Persisted  int      `json:"persisted"`
+	Skipped    int      `json:"skipped"` // Draws already stored
+	Failed     int      `json:"failed"`
+	Violations int      `json:"violations"`
+	Errors     []string `json:"errors,omitempty"`
+
+	failures map[string]int // Failed records by errorKind
+}
+
+// fail counts a record failing with the error
+func (res *ImportResult) fail(err error) {
+	res.Failed++
+	if res.failures == nil {
+		res.failures = map[string]int{}
+	}
+	res.failures[errorKind(err)]++
+	if len(res.Errors) < maxImportErrors {
+		res.Errors = append(res.Errors, err.Error())
+	}
+}
+
+// progress returns the progress of the import once done records are
+// persisted, skipped, failed or rejected
+func (res ImportResult) progress(done int) jobops.Progress {
+	return jobops.Progress{
+		Records:    res.Records,
+		Done:       done,
+		Persisted:  res.Persisted,
+		Skipped:    res.Skipped,
+		Failed:     res.Failed,
+		Violations: res.Violations,
+		Errors:     slices.Clone(res.Errors),
+	}
+}
+
+// importer persists the draws of an uploaded file in the format, reporting
+// its progress as it goes
+type importer func(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error)
+
+// startImport spools the uploaded file of the request and starts a job
+// importing it. The response is the status of the job, located at
+// /jobs/{id}. The job fails when no draw of the file is stored.
+//
+// Deprecated routes wait for the job and respond with its ImportResult.
+func (r RESTFul) startImport(req *http.Request, imp importer) (response, error) {
+	file, format, err := openUpload(req)
+	if err != nil {
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	defer file.Close()
+
+	spool, err := os.CreateTemp("", "lotterystat-upload-*")
+	if err != nil {
+		return response{}, err
+	}
+	defer spool.Close()
+	if _, err := io.Copy(spool, file); err != nil {
+		os.Remove(spool.Name())
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+
+	requestID := logops.RequestID(req.Context())
+	status := r.jobs.Start("import", func(ctx context.Context, report func(jobops.Progress)) (any, error) {
+		ctx = logops.WithRequestID(ctx, requestID)
+		f, err := os.Open(spool.Name())
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		res, err := imp(ctx, f, format, report)
+		r.metrics.imported(res)
+		if err != nil {
+			return nil, err
+		}
+		if res.Persisted == 0 && res.Skipped == 0 {
+			return res, importError{result: res}
+		}
+		return res, nil
+	})
+	go func() {
+		ctx := logops.WithRequestID(context.Background(), requestID)
+		status, err := r.jobs.Wait(ctx, status.ID)
+		r.metrics.job(status)
+		res, _ := status.Result.(ImportResult)
+		attrs := []any{"job", status.ID, "state", status.State, "game", res.Game, "records", res.Records,
+			"persisted", res.Persisted, "skipped", res.Skipped, "failed", res.Failed}
+		if err != nil {
+			slog.WarnContext(ctx, "import failed", append(attrs, logops.Err(err))...)
+		} else {
+			slog.InfoContext(ctx, "import finished", attrs...)
+		}
+		os.Remove(spool.Name())
+	}()
+
+	return response{
+		status: http.StatusAccepted,
+		header: http.Header{"Location": {r.prefix + "/jobs/" + status.ID}},
+		data:   status,
+		legacy: func() (response, error) {
+			status, err := r.jobs.Wait(req.Context(), status.ID)
+			if err != nil {
+				return response{}, err
+			}
+			return response{status: http.StatusAccepted, data: status.Result}, nil
+		},
+	}, nil
+}
+
+// importDraws persists the draws of an uploaded CSV, JSON, NDJSON or XLSX
+// file of any game, detected from the header and values of the file. Files
+// matching no game fail the import.
+func (r RESTFul) importDraws(req *http.Request) (response, error) {
+	return r.startImport(req, r.persistDetected)
+}
+
+// persistDetected persists the draws of a file in the format as draws of
+// the game detected from the file
+func (r RESTFul) persistDetected(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	game, err := csvops.DetectGame(ctx, content, format, games)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	switch game {
+	case "tball":
+		return r.persistTBall(ctx, bytes.NewReader(content), format, report)
+	case "euro":
+		return r.persistEuro(ctx, bytes.NewReader(content), format, report)
+	case "lotto":
+		return r.persistLotto(ctx, bytes.NewReader(content), format, report)
+	case "sflife":
+		return r.persistSFLife(ctx, bytes.NewReader(content), format, report)
+	}
+	return ImportResult{}, fmt.Errorf("%w: %s", csvops.ErrGame, game)
+}
@@ -0,0 +1,81 @@
+package ebzrest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory())
+
+	testcases := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		want        ebzrest.ImportResult
+	}{
+		{
+			name:        "lotto csv",
+			contentType: "text/csv",
+			body:        "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Ball 6,Bonus Ball,Ball Set,Machine,DrawNumber\n18-Feb-2026,1,11,12,13,18,49,33,L10,Lotto4,3147\n",
+			wantStatus:  http.StatusAccepted,
+			want:        ebzrest.ImportResult{Game: "lotto", Format: "csv", Records: 1, Persisted: 1},
+		},
+		{
+			name:        "lotto csv already stored",
+			contentType: "text/csv",
+			body:        "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Ball 6,Bonus Ball,Ball Set,Machine,DrawNumber\n18-Feb-2026,1,11,12,13,18,49,33,L10,Lotto4,3147\n",
+			wantStatus:  http.StatusAccepted,
+			want:        ebzrest.ImportResult{Game: "lotto", Format: "csv", Records: 1, Skipped: 1},
+		},
+		{
+			name:        "euro json",
+			contentType: "application/json",
+			body:        `[{"draw_date":"2026-02-24T00:00:00Z","ball1":1,"ball2":2,"ball3":3,"ball4":4,"ball5":5,"star1":1,"star2":2,"uk_maker":"ABCD12345","draw_no":1923}]`,
+			wantStatus:  http.StatusAccepted,
+			want:        ebzrest.ImportResult{Game: "euro", Format: "json", Records: 1, Persisted: 1},
+		},
+		{
+			name:        "no game matches",
+			contentType: "text/csv",
+			body:        "DrawDate,Number\n18-Feb-2026,1\n",
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/import", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantStatus != http.StatusAccepted {
+				return
+			}
+			var got ebzrest.ImportResult
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// drawNumbers returns the draw numbers of the draws in order
+func drawNumbers[D any](draws []D, drawNo func(D) uint64) []uint64 {
+	nos := []uint64{}
+	for _, d := range draws {
+		nos = append(nos, drawNo(d))
+	}
+	return nos
+}
@@ -0,0 +1,49 @@
+package ebzrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// jobStatus returns the status of the job with the ID of the path.
+func (r RESTFul) jobStatus(req *http.Request) (response, error) {
+	status, err := r.jobs.Status(req.PathValue("id"))
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: status}, nil
+}
+
+// jobEvents streams the status of the job with the ID of the path as
+// Server-Sent Events. Each change of the status is sent as a progress
+// event, and the status of the finished job as a done event ending the
+// stream.
+func (r RESTFul) jobEvents(rw http.ResponseWriter, req *http.Request) error {
+	statuses, err := r.jobs.Watch(req.Context(), req.PathValue("id"))
+	if err != nil {
+		return err
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(rw)
+	id := 0
+	for status := range statuses {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return nil
+		}
+		event := "progress"
+		if status.State.Done() {
+			event = "done"
+		}
+		id++
+		if _, err := fmt.Fprintf(rw, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data); err != nil {
+			return nil
+		}
+		rc.Flush()
+	}
+	return nil
+}
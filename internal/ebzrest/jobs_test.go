@@ -0,0 +1,143 @@
+package ebzrest_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/stretchr/testify/assert"
+)
+
+// importStatus is the status of an import job with its result
+type importStatus struct {
+	jobops.Status
+	Result ebzrest.ImportResult `json:"result"`
+}
+
+// event is a Server-Sent Event
+type event struct {
+	id   string
+	name string
+	data string
+}
+
+// readEvents returns the Server-Sent Events of a stream
+func readEvents(r io.Reader) []event {
+	events := []event{}
+	var e event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		field, value, _ := strings.Cut(scanner.Text(), ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.name = value
+		case "data":
+			e.data = value
+		case "":
+			events = append(events, e)
+			e = event{}
+		}
+	}
+	return events
+}
+
+func TestImportJobs(t *testing.T) {
+	jobs := jobops.NewManager(1)
+	defer jobs.Close()
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithJobs(jobs))
+
+	testcases := []struct {
+		name      string
+		body      string
+		wantState jobops.State
+		want      ebzrest.ImportResult
+		wantError string
+	}{
+		{
+			name:      "persisted",
+			body:      "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Thunderball,Ball Set,Machine,DrawNumber\n28-Aug-2024,16,4,6,13,28,3,T6,Excalibur 1,3547\n",
+			wantState: jobops.Succeeded,
+			want:      ebzrest.ImportResult{Game: "tball", Format: "csv", Records: 1, Persisted: 1},
+		},
+		{
+			name:      "every record failed",
+			body:      "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Thunderball,Ball Set,Machine,DrawNumber\n28-Aug-2024,16,4,6,13,99,3,T6,Excalibur 1,3548\n",
+			wantState: jobops.Failed,
+			want:      ebzrest.ImportResult{Game: "tball", Format: "csv", Records: 1, Failed: 1},
+			wantError: ebzrest.ErrImport.Error(),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/tball/csv", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "text/csv")
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusAccepted, rr.Code)
+			var started ebzrest.Envelope[jobops.Status]
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&started))
+			assert.Equal(t, "import", started.Data.Kind)
+			location := rr.Header().Get("Location")
+			assert.Equal(t, "/api/v1/jobs/"+started.Data.ID, location)
+
+			rr = httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", location+"/events", nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			events := readEvents(rr.Body)
+			if len(events) == 0 {
+				t.Fatalf("Unmatch events. Want: done event Got: none")
+			}
+			last := events[len(events)-1]
+			assert.Equal(t, "done", last.name)
+			var streamed importStatus
+			assert.NoError(t, json.Unmarshal([]byte(last.data), &streamed))
+			assert.Equal(t, tc.wantState, streamed.State)
+			assert.Equal(t, tc.wantError, streamed.Error)
+			assert.Equal(t, streamed.Records, streamed.Done)
+
+			rr = httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", location, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var got ebzrest.Envelope[importStatus]
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, tc.wantState, got.Data.State)
+			got.Data.Result.Errors = nil
+			assert.Equal(t, tc.want, got.Data.Result)
+		})
+	}
+
+	t.Run("Job not found", func(t *testing.T) {
+		for _, target := range []string{"/api/v1/jobs/missing", "/api/v1/jobs/missing/events"} {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			var got ebzrest.Problem
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, "/api/v1/problems/job-not-found", got.Type)
+		}
+	})
+
+	t.Run("Deprecated route waits", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/tball/csv", strings.NewReader("DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Thunderball,Ball Set,Machine,DrawNumber\n28-Aug-2024,16,4,6,13,99,3,T6,Excalibur 1,3549\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Empty(t, rr.Header().Get("Location"))
+	})
+}
@@ -1,52 +1,159 @@
 package ebzrest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/lotto"
 )
 
-// LottoUploadCSV handles the upload of a Lotto CSV file and persists the draws.
-func (r RESTFul) LottoUploadCSV(rw http.ResponseWriter, req *http.Request) {
-	file, _, err := req.FormFile("file")
-	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
+// lottoUploadCSV starts a job persisting the draws of an uploaded Lotto CSV, JSON,
+// NDJSON or XLSX file.
+func (r RESTFul) lottoUploadCSV(req *http.Request) (response, error) {
+	return r.startImport(req, r.persistLotto)
+}
 
-	recs := csvops.ExtractRec(req.Context(), file)
+// persistLotto persists the Lotto draws of an uploaded file in the format. The
+// cached responses of the game are invalidated once it returns.
+func (r RESTFul) persistLotto(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error) {
+	defer r.cache.Invalidate("lotto")
+	recs := csvops.Extract(ctx, file, format, lotto.RecordOf)
 	drawChans := lotto.ProcessCSV(recs, 1)
 
+	res := ImportResult{
+		Game:    "lotto",
+		Format:  string(format),
+		Records: len(drawChans),
+	}
+	draws := []lotto.Draw{}
 	for _, dc := range drawChans {
 		if dc.Err != nil {
+			slog.DebugContext(ctx, "skipping record", "game", "lotto", "line", dc.Line, logops.Err(dc.Err))
+			res.fail(dc.Err)
 			continue
 		}
-		_ = lotto.PersistsDraw(req.Context(), r.db, dc.Draw)
+		draws = append(draws, dc.Draw)
+	}
+	report(res.progress(res.Failed))
+
+	draws, violations, err := lotto.CheckImport(ctx, r.stores.Lotto, draws, r.reject)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res.Violations = len(violations)
+	done := res.Records - len(draws)
+	report(res.progress(done))
+	for _, d := range draws {
+		err := r.stores.Lotto.PersistDraw(ctx, d)
+		switch {
+		case err == nil:
+			res.Persisted++
+		case errors.Is(err, drawops.ErrStored):
+			res.Skipped++
+		case errors.Is(err, drawops.ErrRefused):
+			slog.DebugContext(ctx, "draw refused", "game", "lotto", "draw_no", d.DrawNo, logops.Err(err))
+			res.fail(err)
+		default:
+			return ImportResult{}, err
+		}
+		done++
+		report(res.progress(done))
+	}
+	return res, nil
+}
+
+// lottoDrawFrequencies returns the frequencies of Lotto draw balls.
+func (r RESTFul) lottoDrawFrequencies(req *http.Request) (response, error) {
+	freqs, err := lotto.CalculateBallFreq(req.Context(), r.stores.Lotto)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: freqs}, nil
+}
+
+// lottoBonusFrequencies returns the frequencies of Lotto bonus balls.
+func (r RESTFul) lottoBonusFrequencies(req *http.Request) (response, error) {
+	freqs, err := lotto.CalculateBonusFreq(req.Context(), r.stores.Lotto)
+	if err != nil {
+		return response{}, err
 	}
-	rw.WriteHeader(http.StatusAccepted)
+	return response{status: http.StatusOK, data: freqs}, nil
 }
 
-// LottoDrawFrequencies returns the frequencies of Lotto draw balls.
-func (r RESTFul) LottoDrawFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := lotto.CalculateBallFreq(req.Context(), r.db)
+// lottoDraws returns a page of the stored Lotto draws selected by the query
+// parameters.
+func (r RESTFul) lottoDraws(req *http.Request) (response, error) {
+	return listDraws(req, r.stores.Lotto.ListDraws, func(d lotto.Draw) drawops.Cursor {
+		return drawops.Cursor{Date: d.DrawDate, DrawNo: d.DrawNo}
+	})
+}
+
+// lottoLatestDraw returns the stored Lotto draw with the highest draw number.
+func (r RESTFul) lottoLatestDraw(req *http.Request) (response, error) {
+	d, err := lotto.LatestDraw(req.Context(), r.stores.Lotto)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	return response{status: http.StatusOK, data: d}, nil
 }
 
-// LottoBonusFrequencies returns the frequencies of Lotto bonus balls.
-func (r RESTFul) LottoBonusFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := lotto.CalculateBonusFreq(req.Context(), r.db)
+// lottoDraw returns the stored Lotto draw with the draw number of the path.
+func (r RESTFul) lottoDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
+	}
+	d, err := r.stores.Lotto.GetDraw(req.Context(), no)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// lottoUpdateDraw replaces the stored Lotto draw with the draw number of the
+// path by the draw of the JSON body, once the draw passes lotto.CheckDraw.
+func (r RESTFul) lottoUpdateDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	var d lotto.Draw
+	if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	if err := checkDrawNo(&d.DrawNo, no); err != nil {
+		return response{}, err
+	}
+	d.DayOfWeek = d.DrawDate.Weekday()
+	if err := lotto.CheckDraw(d); err != nil {
+		return response{}, err
+	}
+	if err := r.stores.Lotto.UpdateDraw(req.Context(), d); err != nil {
+		return response{}, err
+	}
+	r.cache.Invalidate("lotto")
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// lottoDeleteDraw removes the stored Lotto draw with the draw number of the
+// path.
+func (r RESTFul) lottoDeleteDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	if err := r.stores.Lotto.DeleteDraw(req.Context(), no); err != nil {
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	r.cache.Invalidate("lotto")
+	return response{status: http.StatusNoContent}, nil
 }
@@ -0,0 +1,148 @@
+package ebzrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
+)
+
+const (
+	// MetricsPath is where the metrics of WithMetrics are served
+	MetricsPath = "/metrics"
+	// countTimeout is how long counting the stored draws of a scrape may take
+	countTimeout = 5 * time.Second
+)
+
+// WithMetrics records import jobs, imported rows and stored draws on the
+// registry, and serves the registry at MetricsPath
+func WithMetrics(reg *metricops.Registry) Option {
+	return func(r *RESTFul) {
+		r.registry = reg
+	}
+}
+
+// metrics are the metrics of imports
+type metrics struct {
+	jobs    *metricops.Counter
+	jobTime *metricops.Histogram
+	rows    *metricops.Counter
+}
+
+// newMetrics registers the metrics of imports and the stored draws of the
+// stores on the registry
+func newMetrics(reg *metricops.Registry, stores ebzstore.Stores) *metrics {
+	m := &metrics{
+		jobs:    reg.Counter("ebz_import_jobs_total", "Import jobs finished, by state.", "state"),
+		jobTime: reg.Histogram("ebz_import_job_duration_seconds", "Duration of import jobs from start to finish, by state.", importBuckets, "state"),
+		rows:    reg.Counter("ebz_import_rows_total", "Imported records by game, outcome and error.", "game", "outcome", "error"),
+	}
+	reg.GaugeFunc("ebz_stored_draws", "Draws stored by game.", []string{"game"}, func() []metricops.Sample {
+		return countDraws(stores)
+	})
+	return m
+}
+
+// importBuckets are the upper bounds in seconds of the durations of import
+// jobs, which take longer than requests
+var importBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900}
+
+// job records the finished job
+func (m *metrics) job(status jobops.Status) {
+	if m == nil || status.ID == "" {
+		return
+	}
+	m.jobs.Inc(string(status.State))
+	m.jobTime.Observe(status.Updated.Sub(status.Created).Seconds(), string(status.State))
+}
+
+// imported records the records of the import as persisted, skipped or
+// rejected by their error. Records neither persisted, skipped nor failed
+// were rejected by integrity checks.
+func (m *metrics) imported(res ImportResult) {
+	if m == nil || res.Game == "" {
+		return
+	}
+	m.rows.Add(float64(res.Persisted), res.Game, "persisted", "")
+	m.rows.Add(float64(res.Skipped), res.Game, "skipped", "")
+	for kind, n := range res.failures {
+		m.rows.Add(float64(n), res.Game, "rejected", kind)
+	}
+	if n := res.Records - res.Persisted - res.Skipped - res.Failed; n > 0 {
+		m.rows.Add(float64(n), res.Game, "rejected", "integrity")
+	}
+}
+
+// errorKind returns the text of the first error of problemKinds that the
+// error wraps, so that records failing alike share a label, or "other"
+func errorKind(err error) string {
+	for _, kind := range problemKinds {
+		for _, e := range kind.errs {
+			if errors.Is(err, e) {
+				return e.Error()
+			}
+		}
+	}
+	return "other"
+}
+
+// countDraws returns the number of draws stored of each game. Games failing
+// to be counted are left out.
+func countDraws(stores ebzstore.Stores) []metricops.Sample {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+	counters := []struct {
+		game  string
+		count func(context.Context) ([]int, error)
+	}{
+		{game: "tball", count: stores.TBall.CountDrawsByEra},
+		{game: "euro", count: stores.Euro.CountDrawsByEra},
+		{game: "lotto", count: stores.Lotto.CountDrawsByEra},
+		{game: "sflife", count: stores.SFLife.CountDrawsByEra},
+	}
+	samples := []metricops.Sample{}
+	for _, c := range counters {
+		counts, err := c.count(ctx)
+		if err != nil {
+			continue
+		}
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		samples = append(samples, metricops.Sample{Labels: []string{c.game}, Value: float64(total)})
+	}
+	return samples
+}
+
+// Measure wraps the handler of the dashboard and REST API, counting the
+// requests and timing their responses on the registry by method, route
+// and status. The route is the pattern of the mux the request matches, so
+// that paths of draws and jobs share a label, or "unmatched".
+func Measure(h http.Handler, mux *http.ServeMux, reg *metricops.Registry) http.Handler {
+	requests := reg.Counter("ebz_http_requests_total", "HTTP requests by method, route and status.", "method", "route", "status")
+	latency := reg.Histogram("ebz_http_request_duration_seconds", "Duration of HTTP requests by method and route.", metricops.DefaultBuckets, "method", "route")
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		route := "unmatched"
+		if _, pattern := mux.Handler(req); pattern != "" {
+			_, path, found := strings.Cut(pattern, " ")
+			if !found {
+				path = pattern
+			}
+			route = path
+		}
+		rec := &recorder{ResponseWriter: rw}
+		defer func() {
+			latency.Observe(time.Since(start).Seconds(), req.Method, route)
+			requests.Inc(req.Method, route, strconv.Itoa(rec.statusCode()))
+		}()
+		h.ServeHTTP(rec, req)
+	})
+}
@@ -0,0 +1,70 @@
+package ebzrest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	jobs := jobops.NewManager(1)
+	defer jobs.Close()
+	reg := metricops.NewRegistry()
+	mux := http.NewServeMux()
+	ebzrest.New(mux, ebzstore.NewMemory(), ebzrest.WithJobs(jobs), ebzrest.WithMetrics(reg))
+	h := ebzrest.Measure(ebzrest.Harden(mux, ebzrest.DefaultPrefix, ebzrest.Limits{}), mux, reg)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	csv := "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Thunderball,Ball Set,Machine,DrawNumber\n" +
+		"28-Aug-2024,16,4,6,13,28,3,T6,Excalibur 1,3547\n" +
+		"28-Aug-2024,16,4,6,13,28,3,T6,Excalibur 1,3547\n" +
+		"31-Aug-2024,16,4,6,13,99,3,T6,Excalibur 1,3548\n"
+	assert.Equal(t, http.StatusAccepted, serve("POST", "/tball/csv", csv).Code)
+	serve("POST", "/tball/csv", "DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Thunderball,Ball Set,Machine,DrawNumber\n"+
+		"4-Sep-2024,16,4,6,13,99,3,T6,Excalibur 1,3549\n")
+	serve("GET", "/api/v1/tball/draws/3547", "")
+	serve("GET", "/api/v1/tball/draws/1", "")
+	serve("GET", "/missing/page", "")
+
+	scrape := func() string {
+		rr := serve("GET", ebzrest.MetricsPath, "")
+		assert.Equal(t, metricops.ContentType, rr.Header().Get("Content-Type"))
+		return rr.Body.String()
+	}
+	assert.Eventually(t, func() bool {
+		got := scrape()
+		return strings.Contains(got, `ebz_import_jobs_total{state="succeeded"} 1`) && strings.Contains(got, `ebz_import_jobs_total{state="failed"} 1`)
+	}, time.Second, 10*time.Millisecond)
+
+	got := scrape()
+	for _, want := range []string{
+		`ebz_http_requests_total{method="POST",route="/tball/csv",status="202"} 1`,
+		`ebz_http_requests_total{method="GET",route="/api/v1/tball/draws/{drawNo}",status="200"} 1`,
+		`ebz_http_requests_total{method="GET",route="/api/v1/tball/draws/{drawNo}",status="404"} 1`,
+		`ebz_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`ebz_http_request_duration_seconds_count{method="GET",route="/api/v1/tball/draws/{drawNo}"} 2`,
+		`ebz_import_job_duration_seconds_count{state="succeeded"} 1`,
+		`ebz_import_rows_total{game="tball",outcome="persisted",error=""} 1`,
+		`ebz_import_rows_total{game="tball",outcome="rejected",error="invalid ball 5"} 2`,
+		`ebz_import_rows_total{game="tball",outcome="skipped",error=""} 1`,
+		`ebz_stored_draws{game="tball"} 1`,
+		`ebz_stored_draws{game="euro"} 0`,
+	} {
+		assert.Contains(t, got, want)
+	}
+}
@@ -0,0 +1,359 @@
+package ebzrest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/logops"
+)
+
+// Limits configures the middleware of Harden, with values taken from the
+// configuration of the server. A zero limit is not applied.
+type Limits struct {
+	// ReadTimeout is how long a request, including its body, may take to
+	// be read
+	ReadTimeout time.Duration
+	// WriteTimeout is how long a request may take to be handled and its
+	// response written, except for event streams
+	WriteTimeout time.Duration
+	// MaxBody is the largest request body in bytes
+	MaxBody int64
+	// Rate is the number of requests per second a client IP may make to
+	// routes changing draws, after a burst of Burst requests
+	Rate  float64
+	Burst int
+}
+
+// middleware wraps a handler
+type middleware func(http.Handler) http.Handler
+
+// Harden wraps the handler of the dashboard and REST API in middleware
+// recovering from panics, setting security headers, and applying the
+// timeouts, body limit and rate limit of the limits. Failures of routes
+// under the prefix are written as problem details, and otherwise as plain
+// text.
+func Harden(h http.Handler, prefix string, limits Limits) http.Handler {
+	chain := []middleware{
+		recoverPanics(prefix),
+		secureHeaders,
+		timeouts(prefix, limits.ReadTimeout, limits.WriteTimeout),
+		limitBody(prefix, limits.MaxBody),
+		rateLimit(prefix, newRateLimiter(limits.Rate, limits.Burst)),
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	return h
+}
+
+// fail writes the error of the request, as problem details on routes
+// under the prefix
+func fail(rw http.ResponseWriter, req *http.Request, prefix string, err error) {
+	if prefix != "" && strings.HasPrefix(req.URL.Path, prefix+"/") {
+		writeProblem(rw, req, prefix, err)
+		return
+	}
+	writeText(rw, req, err)
+}
+
+// recorder records whether a response was started, its status and the
+// bytes of its body
+type recorder struct {
+	http.ResponseWriter
+	wrote  bool
+	status int
+	bytes  int64
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wrote && status >= 200 {
+		r.wrote = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.wrote {
+		r.wrote = true
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// statusCode returns the status of the response, which is 200 when the
+// handler wrote none
+func (r *recorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Unwrap lets http.ResponseController flush and set deadlines
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestIDPattern matches the request IDs accepted from clients
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// AccessLog wraps the handler of the dashboard and REST API, giving each
+// request an ID and logging it once served with its method, path, status,
+// size and duration. The ID is taken from the X-Request-ID header of the
+// request when valid, so that a proxy can trace requests, and otherwise
+// generated. It is set in the X-Request-ID header of the response and in the
+// context of the request, so records logged with the context carry it.
+func AccessLog(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		id := req.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = logops.NewRequestID()
+		}
+		rw.Header().Set("X-Request-ID", id)
+		req = req.WithContext(logops.WithRequestID(req.Context(), id))
+
+		rec := &recorder{ResponseWriter: rw}
+		defer func() {
+			slog.InfoContext(req.Context(), "request",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"status", rec.statusCode(),
+				"bytes", rec.bytes,
+				"duration", time.Since(start),
+				"client", clientIP(req),
+			)
+		}()
+		h.ServeHTTP(rec, req)
+	})
+}
+
+// recoverPanics logs a panicking handler with its stack and, unless the
+// response was started, responds with ErrInternal. The panic value is not
+// disclosed to the client.
+func recoverPanics(prefix string) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rec := &recorder{ResponseWriter: rw}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				slog.ErrorContext(req.Context(), "panic serving request", "method", req.Method, "path", req.URL.Path, "panic", v, "stack", string(debug.Stack()))
+				if !rec.wrote {
+					fail(rw, req, prefix, ErrInternal)
+				}
+			}()
+			next.ServeHTTP(rec, req)
+		})
+	}
+}
+
+// inlineScripts matches the inline scripts of the embedded pages
+var inlineScripts = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
+// contentSecurityPolicy allows the dashboard, docs and login pages to load
+// scripts, styles and data from the server only. The inline scripts of the
+// docs and login pages are allowed by their hashes; inline styles are
+// allowed as Material UI injects them.
+var contentSecurityPolicy = func() string {
+	scripts := []string{"'self'"}
+	for _, page := range [][]byte{docsPage, loginPage} {
+		for _, m := range inlineScripts.FindAllSubmatch(page, -1) {
+			sum := sha256.Sum256(m[1])
+			scripts = append(scripts, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+		}
+	}
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src " + strings.Join(scripts, " "),
+		"style-src 'self' 'unsafe-inline'",
+		"img-src 'self' data:",
+		"font-src 'self' data:",
+		"connect-src 'self'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors 'none'",
+	}, "; ")
+}()
+
+// secureHeaders sets the headers restricting what browsers do with the
+// responses
+func secureHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		h := rw.Header()
+		h.Set("Content-Security-Policy", contentSecurityPolicy)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "same-origin")
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+		h.Set("Cross-Origin-Resource-Policy", "same-origin")
+		h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=(), payment=()")
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// timeouts sets the deadlines of reading the request and writing the
+// response, and cancels the context of the request at the write deadline
+// so that its queries stop. The job event stream under the prefix lasts
+// until the client leaves, so its write deadline is cleared.
+func timeouts(prefix string, read, write time.Duration) middleware {
+	method, path, _ := strings.Cut(jobEventsPattern, " ")
+	streams := http.NewServeMux()
+	streams.Handle(method+" "+prefix+path, http.NotFoundHandler())
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rc := http.NewResponseController(rw)
+			if read > 0 {
+				rc.SetReadDeadline(time.Now().Add(read))
+			}
+			if _, pattern := streams.Handler(req); pattern != "" {
+				rc.SetWriteDeadline(time.Time{})
+				next.ServeHTTP(rw, req)
+				return
+			}
+			if write > 0 {
+				rc.SetWriteDeadline(time.Now().Add(write))
+				ctx, cancel := context.WithTimeout(req.Context(), write)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// limitBody fails reading request bodies larger than max bytes. Endpoints
+// reading past the limit fail with ErrTooLarge.
+func limitBody(prefix string, max int64) middleware {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.ContentLength > max {
+				fail(rw, req, prefix, &http.MaxBytesError{Limit: max})
+				return
+			}
+			req.Body = http.MaxBytesReader(rw, req.Body, max)
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// rateLimit limits the requests of each client IP to routes changing
+// draws, which are every route of a method other than GET, HEAD or
+// OPTIONS. Limited requests fail with ErrRateLimited and a Retry-After
+// header.
+func rateLimit(prefix string, l *rateLimiter) middleware {
+	return func(next http.Handler) http.Handler {
+		if l == nil {
+			return next
+		}
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(rw, req)
+				return
+			}
+			if ok, wait := l.allow(clientIP(req)); !ok {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				fail(rw, req, prefix, ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// clientIP returns the IP of the client of the request. Forwarding headers
+// are ignored, as the server is not run behind a proxy.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// bucket holds the tokens of a client
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter is a token bucket per client, refilled at rate tokens per
+// second up to burst tokens
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	pruned  time.Time
+	now     func() time.Time
+}
+
+// newRateLimiter returns a rate limiter, or nil when the rate or burst is
+// not positive
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 || burst <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: map[string]*bucket{}, now: time.Now}
+}
+
+// allow takes a token of the client, or returns how long until the client
+// has one
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune removes, once a minute, the buckets refilled since they were last
+// used, as they are the same as new buckets
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < time.Minute {
+		return
+	}
+	l.pruned = now
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.buckets {
+		if now.Sub(b.updated) > full {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package ebzrest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/stretchr/testify/assert"
+)
+
+// testLimits are limits no request of the tests exceeds
+var testLimits = Limits{ReadTimeout: time.Minute, WriteTimeout: time.Minute, MaxBody: 32 << 20, Rate: 1, Burst: 10}
+
+func TestHardenHeaders(t *testing.T) {
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+	h := Harden(mux, DefaultPrefix, testLimits)
+
+	for _, target := range []string{DocsPath, "/api/v1/tball/draws", "/missing"} {
+		t.Run(target, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+
+			assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+			assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'")
+		})
+	}
+
+	csp := contentSecurityPolicy
+	for _, page := range [][]byte{docsPage, loginPage} {
+		m := inlineScripts.FindSubmatch(page)
+		if m == nil {
+			t.Fatal("no inline script")
+		}
+		sum := sha256.Sum256(m[1])
+		assert.Contains(t, csp, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+	}
+	assert.Contains(t, csp, "script-src 'self' 'sha256-")
+}
+
+func TestHardenBodyLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+	h := Harden(mux, DefaultPrefix, Limits{MaxBody: 64})
+
+	large := strings.Repeat("x", 128)
+	multipartBody := func(content string) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "tball.csv")
+		part.Write([]byte(content))
+		writer.Close()
+		return body, writer.FormDataContentType()
+	}
+
+	testcases := []struct {
+		name        string
+		target      string
+		body        func() (*bytes.Buffer, string)
+		unknownSize bool
+		wantStatus  int
+		wantType    string
+	}{
+		{
+			name:   "declared length",
+			target: "/api/v1/tball/csv",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(large), "text/csv"
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantType:   "/api/v1/problems/too-large",
+		},
+		{
+			name:        "streamed body",
+			target:      "/api/v1/tball/csv",
+			body:        func() (*bytes.Buffer, string) { return bytes.NewBufferString(large), "text/csv" },
+			unknownSize: true,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantType:    "/api/v1/problems/too-large",
+		},
+		{
+			name:        "multipart form",
+			target:      "/api/v1/import",
+			body:        func() (*bytes.Buffer, string) { return multipartBody(large) },
+			unknownSize: true,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantType:    "/api/v1/problems/too-large",
+		},
+		{
+			name:       "deprecated route",
+			target:     "/tball/csv",
+			body:       func() (*bytes.Buffer, string) { return bytes.NewBufferString(large), "text/csv" },
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, contentType := tc.body()
+			req := httptest.NewRequest("POST", tc.target, body)
+			req.Header.Set("Content-Type", contentType)
+			if tc.unknownSize {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+
+			h.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantType == "" {
+				return
+			}
+			var got Problem
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, tc.wantType, got.Type)
+		})
+	}
+}
+
+func TestHardenRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+	h := Harden(mux, DefaultPrefix, Limits{Rate: 0.01, Burst: 2})
+
+	send := func(method, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/tball/draws/1", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusNotFound, send("DELETE", "192.0.2.1:1000").Code)
+	}
+	rr := send("DELETE", "192.0.2.1:1001")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "100", rr.Header().Get("Retry-After"))
+	var got Problem
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, "/api/v1/problems/rate-limited", got.Type)
+
+	assert.Equal(t, http.StatusNotFound, send("GET", "192.0.2.1:1002").Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "192.0.2.2:1000").Code)
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, 2)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	testcases := []struct {
+		name     string
+		advance  time.Duration
+		client   string
+		wantOK   bool
+		wantWait time.Duration
+	}{
+		{name: "burst first", client: "a", wantOK: true},
+		{name: "burst second", client: "a", wantOK: true},
+		{name: "empty", client: "a", wantWait: 500 * time.Millisecond},
+		{name: "other client", client: "b", wantOK: true},
+		{name: "partly refilled", advance: 250 * time.Millisecond, client: "a", wantWait: 250 * time.Millisecond},
+		{name: "refilled", advance: 250 * time.Millisecond, client: "a", wantOK: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.advance)
+			ok, wait := l.allow(tc.client)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantWait, wait)
+		})
+	}
+
+	now = now.Add(time.Hour)
+	l.allow("c")
+	assert.Len(t, l.buckets, 1)
+	assert.Nil(t, newRateLimiter(0, 10))
+}
+
+func TestHardenRecover(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/panic", func(rw http.ResponseWriter, req *http.Request) {
+		panic("secret state")
+	})
+	mux.HandleFunc("/panic", func(rw http.ResponseWriter, req *http.Request) {
+		panic("secret state")
+	})
+	h := Harden(mux, DefaultPrefix, Limits{})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	var got Problem
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, "/api/v1/problems/internal-error", got.Type)
+	assert.NotContains(t, got.Detail, "secret")
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+}
+
+func TestHardenTimeouts(t *testing.T) {
+	testcases := []struct {
+		name     string
+		path     string
+		accept   string
+		deadline bool
+	}{
+		{name: "endpoint", path: "/api/v1/tball/draws", deadline: true},
+		{name: "endpoint accepting event stream", path: "/api/v1/tball/draws", accept: "text/event-stream", deadline: true},
+		{name: "job events", path: "/api/v1/jobs/1/events", accept: "text/event-stream", deadline: false},
+		{name: "job events accepting anything", path: "/api/v1/jobs/1/events", deadline: false},
+		{name: "job events outside prefix", path: "/jobs/1/events", accept: "text/event-stream", deadline: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var deadline bool
+			h := Harden(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, deadline = req.Context().Deadline()
+			}), DefaultPrefix, Limits{WriteTimeout: time.Minute})
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tc.deadline, deadline)
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _ := logops.New(buf, "info", "json")
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logger)
+
+	mux := http.NewServeMux()
+	New(mux, ebzstore.NewMemory())
+	h := AccessLog(mux)
+
+	testcases := []struct {
+		name       string
+		requestID  string
+		wantID     string
+		wantStatus int
+	}{
+		{name: "generated", wantStatus: http.StatusOK},
+		{name: "from client", requestID: "trace-42", wantID: "trace-42", wantStatus: http.StatusOK},
+		{name: "invalid from client", requestID: "bad id\n", wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", "/api/v1/tball/draws", nil)
+			if tc.requestID != "" {
+				req.Header.Set("X-Request-ID", tc.requestID)
+			}
+			rr := httptest.NewRecorder()
+
+			h.ServeHTTP(rr, req)
+
+			id := rr.Header().Get("X-Request-ID")
+			if tc.wantID != "" {
+				assert.Equal(t, tc.wantID, id)
+			} else {
+				assert.Len(t, id, 16)
+			}
+			var got map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, "request", got["msg"])
+			assert.Equal(t, id, got["request_id"])
+			assert.Equal(t, "GET", got["method"])
+			assert.Equal(t, "/api/v1/tball/draws", got["path"])
+			assert.Equal(t, float64(tc.wantStatus), got["status"])
+			assert.Equal(t, float64(rr.Body.Len()), got["bytes"])
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package ebzrest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// OpenAPIPath and DocsPath are the paths the OpenAPI document of the API
+// and its docs page are served at, whatever the prefix of the API
+const (
+	OpenAPIPath = "/api/openapi.json"
+	DocsPath    = "/api/docs"
+)
+
+// openAPI is the OpenAPI 3.1 document of the routes registered by New. The
+// routes of RESTFul.routes are relative to the server URL /api/v1, and the
+// docs, auth and metrics routes to the server URL / of their path items.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// openAPIDoc returns the OpenAPI document with the prefix as its server URL
+func openAPIDoc(prefix string) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(openAPI, &doc); err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		prefix = "/"
+	}
+	doc["servers"] = []map[string]string{{"url": prefix}}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// serveOpenAPI serves the OpenAPI document of the API at the prefix
+func serveOpenAPI(prefix string) http.HandlerFunc {
+	doc, err := openAPIDoc(prefix)
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if err != nil {
+			writeText(rw, req, fmt.Errorf("%w: %w", ErrInternal, err))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write(doc)
+	}
+}
+
+// serveDocs serves a page listing the operations and schemas of the
+// OpenAPI document
+func serveDocs(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Write(docsPage)
+}
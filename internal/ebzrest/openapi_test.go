@@ -0,0 +1,219 @@
+package ebzrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/authops"
+	"github.com/paulwizviz/lotterystat/internal/cacheops"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/metricops"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+}
+
+type openAPISchema struct {
+	Properties map[string]any `json:"properties"`
+}
+
+type openAPIDocument struct {
+	Servers    []struct{ URL string } `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// jsonFields returns the names the exported fields of a struct are encoded
+// as in JSON, with the fields of embedded structs in place of the struct
+func jsonFields(typ reflect.Type) []string {
+	names := []string{}
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" {
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// undocumented lists the patterns registered by New that openapi.json
+// leaves out on purpose, with the reason
+var undocumented = map[string]string{}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	// Every option registering routes is enabled
+	rest := newRESTFul(ebzstore.NewMemory(),
+		WithTokens(authops.NewMemStore()),
+		WithMetrics(metricops.NewRegistry()),
+		WithIntegrityReject(true),
+		WithCache(cacheops.New(cacheops.DefaultTTL)))
+	defer rest.jobs.Close()
+	patterns := rest.register(http.NewServeMux())
+
+	// documented returns the operation of openapi.json of a pattern, whose
+	// path is relative to the prefix unless its path item has the server /
+	documented := func(pattern string) (json.RawMessage, bool) {
+		method, path, _ := strings.Cut(pattern, " ")
+		if rel, ok := strings.CutPrefix(path, DefaultPrefix); ok {
+			item, found := doc.Paths[rel]
+			if !found || item["servers"] != nil {
+				return nil, false
+			}
+			raw, ok := item[strings.ToLower(method)]
+			return raw, ok
+		}
+		item, found := doc.Paths[path]
+		if !found || item["servers"] == nil {
+			return nil, false
+		}
+		raw, ok := item[strings.ToLower(method)]
+		return raw, ok
+	}
+
+	registered := map[string]bool{}
+	for _, pattern := range patterns {
+		registered[pattern] = true
+	}
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			if _, ok := undocumented[pattern]; ok {
+				return
+			}
+			raw, ok := documented(pattern)
+			if !ok {
+				// A deprecated alias is documented by the operation of the
+				// route it aliases under the prefix
+				method, path, _ := strings.Cut(pattern, " ")
+				if registered[method+" "+DefaultPrefix+path] {
+					raw, ok = documented(method + " " + DefaultPrefix + path)
+				}
+			}
+			if !ok {
+				t.Fatalf("Unmatch route. Want: %s in openapi.json Got: missing", pattern)
+			}
+			var op openAPIOperation
+			assert.NoError(t, json.Unmarshal(raw, &op))
+			assert.NotEmpty(t, op.OperationID)
+		})
+	}
+
+	for path, item := range doc.Paths {
+		prefix := DefaultPrefix
+		if item["servers"] != nil {
+			prefix = ""
+		}
+		for method := range item {
+			if method == "parameters" || method == "servers" {
+				continue
+			}
+			pattern := strings.ToUpper(method) + " " + prefix + path
+			assert.True(t, registered[pattern], "operation %s %s of openapi.json is not registered", method, path)
+		}
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	testcases := []struct {
+		schema string
+		typ    reflect.Type
+	}{
+		{schema: "TBallDraw", typ: reflect.TypeFor[tball.Draw]()},
+		{schema: "TBallBallFrequency", typ: reflect.TypeFor[tball.BallFrequency]()},
+		{schema: "TBallTBallFrequency", typ: reflect.TypeFor[tball.TBallFrequency]()},
+		{schema: "EuroDraw", typ: reflect.TypeFor[euro.Draw]()},
+		{schema: "EuroBallFrequency", typ: reflect.TypeFor[euro.BallFrequency]()},
+		{schema: "EuroStarFrequency", typ: reflect.TypeFor[euro.StarFrequency]()},
+		{schema: "LottoDraw", typ: reflect.TypeFor[lotto.Draw]()},
+		{schema: "LottoBallFrequency", typ: reflect.TypeFor[lotto.BallFrequency]()},
+		{schema: "LottoBonusFrequency", typ: reflect.TypeFor[lotto.BonusFrequency]()},
+		{schema: "SFLifeDraw", typ: reflect.TypeFor[sflife.Draw]()},
+		{schema: "SFLifeBallFrequency", typ: reflect.TypeFor[sflife.BallFrequency]()},
+		{schema: "SFLifeLBallFrequency", typ: reflect.TypeFor[sflife.LBallFrequency]()},
+		{schema: "Meta", typ: reflect.TypeFor[Meta]()},
+		{schema: "ImportResult", typ: reflect.TypeFor[ImportResult]()},
+		{schema: "Job", typ: reflect.TypeFor[jobops.Status]()},
+		{schema: "Problem", typ: reflect.TypeFor[Problem]()},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.schema, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[tc.schema]
+			if !ok {
+				t.Fatalf("Unmatch schema. Want: %s in openapi.json Got: missing", tc.schema)
+			}
+			got := []string{}
+			for name := range schema.Properties {
+				got = append(got, name)
+			}
+			slices.Sort(got)
+			assert.Equal(t, jsonFields(tc.typ), got)
+		})
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	testcases := []struct {
+		name       string
+		opts       []Option
+		wantServer string
+	}{
+		{name: "default prefix", opts: nil, wantServer: "/api/v1"},
+		{name: "custom prefix", opts: []Option{WithPrefix("/lottery")}, wantServer: "/lottery"},
+		{name: "empty prefix", opts: []Option{WithPrefix("")}, wantServer: "/"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := New(http.NewServeMux(), ebzstore.NewMemory(), tc.opts...)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", OpenAPIPath, nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var doc openAPIDocument
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&doc))
+			assert.Equal(t, tc.wantServer, doc.Servers[0].URL)
+
+			rr = httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", DocsPath, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), OpenAPIPath)
+		})
+	}
+}
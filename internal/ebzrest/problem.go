@@ -0,0 +1,170 @@
+package ebzrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+)
+
+var (
+	ErrRequest      = errors.New("invalid request")
+	ErrImport       = errors.New("no draw imported")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrReadOnly     = errors.New("server is read-only")
+	ErrTooLarge     = errors.New("request body too large")
+	ErrRateLimited  = errors.New("too many requests")
+	ErrInternal     = errors.New("internal server error")
+)
+
+// Problem is the RFC 9457 problem details of a failed /api/v1 request
+type Problem struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty"`
+	Errors   []string `json:"errors,omitempty"` // Errors of the records of a failed import
+}
+
+// problemKind is a kind of problem and the errors it is reported for
+type problemKind struct {
+	slug   string
+	title  string
+	status int
+	errs   []error
+}
+
+// problemKinds maps errors to problems. The first kind with an error the
+// failure wraps is reported.
+var problemKinds = []problemKind{
+	{slug: "unauthorized", title: "Unauthorized", status: http.StatusUnauthorized, errs: []error{ErrUnauthorized}},
+	{slug: "read-only", title: "Server is read-only", status: http.StatusForbidden, errs: []error{ErrReadOnly}},
+	{slug: "draw-not-found", title: "Draw not found", status: http.StatusNotFound, errs: []error{drawops.ErrNoDraw}},
+	{slug: "job-not-found", title: "Job not found", status: http.StatusNotFound, errs: []error{jobops.ErrNoJob}},
+	{slug: "draw-stored", title: "Draw already stored", status: http.StatusConflict, errs: []error{drawops.ErrStored}},
+	{slug: "invalid-filter", title: "Invalid filter", status: http.StatusBadRequest, errs: []error{
+		drawops.ErrSortField, drawops.ErrDateRange, drawops.ErrDrawRange, drawops.ErrBall,
+		drawops.ErrLastDraws, drawops.ErrPage, drawops.ErrCursor, drawops.ErrPeriod,
+	}},
+	{slug: "too-large", title: "Request body too large", status: http.StatusRequestEntityTooLarge, errs: []error{ErrTooLarge}},
+	{slug: "rate-limited", title: "Too many requests", status: http.StatusTooManyRequests, errs: []error{ErrRateLimited}},
+	{slug: "invalid-request", title: "Invalid request", status: http.StatusBadRequest, errs: []error{ErrRequest}},
+	{slug: "unsupported-format", title: "Unsupported format", status: http.StatusUnsupportedMediaType, errs: []error{csvops.ErrFormat}},
+	{slug: "unknown-game", title: "No game matches the file", status: http.StatusUnprocessableEntity, errs: []error{csvops.ErrGame}},
+	{slug: "import-failed", title: "No draw imported", status: http.StatusUnprocessableEntity, errs: []error{ErrImport}},
+	{slug: "invalid-draw", title: "Invalid draw", status: http.StatusUnprocessableEntity, errs: []error{
+		drawops.ErrRefused,
+		tball.ErrDrawDate, tball.ErrBall1, tball.ErrBall2, tball.ErrBall3, tball.ErrBall4, tball.ErrBall5, tball.ErrTBall,
+		tball.ErrNoEra, tball.ErrDuplicateBall, tball.ErrBallRange, tball.ErrTBallRange, tball.ErrDrawDay, tball.ErrDrawOrder, tball.ErrDrawConflict,
+		euro.ErrDrawDate, euro.ErrBall1, euro.ErrBall2, euro.ErrBall3, euro.ErrBall4, euro.ErrBall5, euro.ErrStar1, euro.ErrStar2,
+		euro.ErrNoEra, euro.ErrDuplicateBall, euro.ErrDuplicateStar, euro.ErrBallRange, euro.ErrStarRange, euro.ErrDrawDay, euro.ErrDrawOrder, euro.ErrDrawConflict,
+		lotto.ErrDrawDate, lotto.ErrBall1, lotto.ErrBall2, lotto.ErrBall3, lotto.ErrBall4, lotto.ErrBall5, lotto.ErrBall6, lotto.ErrBonus,
+		lotto.ErrNoEra, lotto.ErrDuplicateBall, lotto.ErrDuplicateBonus, lotto.ErrBallRange, lotto.ErrDrawDay, lotto.ErrDrawOrder, lotto.ErrDrawConflict,
+		sflife.ErrDrawDate, sflife.ErrBall1, sflife.ErrBall2, sflife.ErrBall3, sflife.ErrBall4, sflife.ErrBall5, sflife.ErrLBall,
+		sflife.ErrNoEra, sflife.ErrDuplicateBall, sflife.ErrBallRange, sflife.ErrLBallRange, sflife.ErrDrawDay, sflife.ErrDrawOrder, sflife.ErrDrawConflict,
+	}},
+	{slug: "database-busy", title: "Database busy", status: http.StatusServiceUnavailable, errs: []error{sqlops.ErrLocked, sqlops.ErrQueueClosed}},
+	{slug: "shutting-down", title: "Server shutting down", status: http.StatusServiceUnavailable, errs: []error{jobops.ErrClosed}},
+	{slug: "timeout", title: "Request timed out", status: http.StatusGatewayTimeout, errs: []error{context.DeadlineExceeded}},
+	{slug: "internal-error", title: "Internal server error", status: http.StatusInternalServerError, errs: []error{ErrInternal}},
+	{slug: "database-error", title: "Database error", status: http.StatusInternalServerError, errs: []error{
+		sqlops.ErrExecuteQuery, sqlops.ErrExecuteWriter, sqlops.ErrPrepareStmt, sqlops.ErrScanRow, sqlops.ErrDBConn, sqlops.ErrCreateTxn,
+	}},
+}
+
+// importError is the failure of an import persisting no draw
+type importError struct {
+	result ImportResult
+}
+
+func (e importError) Error() string {
+	return ErrImport.Error()
+}
+
+func (e importError) Unwrap() error {
+	return ErrImport
+}
+
+// problemOf returns the problem reported for the error, without its
+// instance and with its type relative to the prefix of the API. Bodies
+// read past the limit of LimitBody are reported as ErrTooLarge. Server
+// errors have no detail, so that database and internal errors are not
+// disclosed to the client.
+func problemOf(err error) Problem {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		err = fmt.Errorf("%w: limit of %d bytes", ErrTooLarge, tooLarge.Limit)
+	}
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+	}
+	for _, kind := range problemKinds {
+		if slices.ContainsFunc(kind.errs, func(e error) bool { return errors.Is(err, e) }) {
+			p.Type = "/problems/" + kind.slug
+			p.Title = kind.title
+			p.Status = kind.status
+			var ie importError
+			if errors.As(err, &ie) {
+				p.Errors = ie.result.Errors
+			}
+			break
+		}
+	}
+	if p.Status < http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+	return p
+}
+
+// reportProblem returns the problem reported for the error of the request,
+// logging server errors with the context of the request so that its
+// request ID ties the record to the response
+func reportProblem(req *http.Request, err error) Problem {
+	p := problemOf(err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(req.Context(), "request failed", "method", req.Method, "path", req.URL.Path, "status", p.Status, logops.Err(err))
+	}
+	return p
+}
+
+// writeProblem writes the problem reported for the error of the request as
+// application/problem+json
+func writeProblem(rw http.ResponseWriter, req *http.Request, prefix string, err error) {
+	p := reportProblem(req, err)
+	if p.Type != "about:blank" {
+		p.Type = prefix + p.Type
+	}
+	p.Instance = req.URL.Path
+	challenge(rw, p.Status)
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(p.Status)
+	json.NewEncoder(rw).Encode(p)
+}
+
+// writeText writes the problem reported for the error of the request as
+// plain text, its detail or otherwise its title
+func writeText(rw http.ResponseWriter, req *http.Request, err error) {
+	p := reportProblem(req, err)
+	text := p.Detail
+	if text == "" {
+		text = p.Title
+	}
+	challenge(rw, p.Status)
+	http.Error(rw, text, p.Status)
+}
@@ -1,52 +1,159 @@
 package ebzrest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/sflife"
 )
 
-// SFLifeUploadCSV handles the upload of a Set For Life CSV file and persists the draws.
-func (r RESTFul) SFLifeUploadCSV(rw http.ResponseWriter, req *http.Request) {
-	file, _, err := req.FormFile("file")
-	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
+// sflifeUploadCSV starts a job persisting the draws of an uploaded Set For Life CSV, JSON,
+// NDJSON or XLSX file.
+func (r RESTFul) sflifeUploadCSV(req *http.Request) (response, error) {
+	return r.startImport(req, r.persistSFLife)
+}
 
-	recs := csvops.ExtractRec(req.Context(), file)
+// persistSFLife persists the Set For Life draws of an uploaded file in the format. The
+// cached responses of the game are invalidated once it returns.
+func (r RESTFul) persistSFLife(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error) {
+	defer r.cache.Invalidate("sflife")
+	recs := csvops.Extract(ctx, file, format, sflife.RecordOf)
 	drawChans := sflife.ProcessCSV(recs, 1)
 
+	res := ImportResult{
+		Game:    "sflife",
+		Format:  string(format),
+		Records: len(drawChans),
+	}
+	draws := []sflife.Draw{}
 	for _, dc := range drawChans {
 		if dc.Err != nil {
+			slog.DebugContext(ctx, "skipping record", "game", "sflife", "line", dc.Line, logops.Err(dc.Err))
+			res.fail(dc.Err)
 			continue
 		}
-		_ = sflife.PersistsDraw(req.Context(), r.db, dc.Draw)
+		draws = append(draws, dc.Draw)
+	}
+	report(res.progress(res.Failed))
+
+	draws, violations, err := sflife.CheckImport(ctx, r.stores.SFLife, draws, r.reject)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res.Violations = len(violations)
+	done := res.Records - len(draws)
+	report(res.progress(done))
+	for _, d := range draws {
+		err := r.stores.SFLife.PersistDraw(ctx, d)
+		switch {
+		case err == nil:
+			res.Persisted++
+		case errors.Is(err, drawops.ErrStored):
+			res.Skipped++
+		case errors.Is(err, drawops.ErrRefused):
+			slog.DebugContext(ctx, "draw refused", "game", "sflife", "draw_no", d.DrawNo, logops.Err(err))
+			res.fail(err)
+		default:
+			return ImportResult{}, err
+		}
+		done++
+		report(res.progress(done))
+	}
+	return res, nil
+}
+
+// sflifeDrawFrequencies returns the frequencies of Set For Life draw balls.
+func (r RESTFul) sflifeDrawFrequencies(req *http.Request) (response, error) {
+	freqs, err := sflife.CalculateBallFreq(req.Context(), r.stores.SFLife)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: freqs}, nil
+}
+
+// sflifeLBallFrequencies returns the frequencies of Set For Life life balls.
+func (r RESTFul) sflifeLBallFrequencies(req *http.Request) (response, error) {
+	freqs, err := sflife.CalculateLBallFreq(req.Context(), r.stores.SFLife)
+	if err != nil {
+		return response{}, err
 	}
-	rw.WriteHeader(http.StatusAccepted)
+	return response{status: http.StatusOK, data: freqs}, nil
 }
 
-// SFLifeDrawFrequencies returns the frequencies of Set For Life draw balls.
-func (r RESTFul) SFLifeDrawFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := sflife.CalculateBallFreq(req.Context(), r.db)
+// sflifeDraws returns a page of the stored Set For Life draws selected by the query
+// parameters.
+func (r RESTFul) sflifeDraws(req *http.Request) (response, error) {
+	return listDraws(req, r.stores.SFLife.ListDraws, func(d sflife.Draw) drawops.Cursor {
+		return drawops.Cursor{Date: d.DrawDate, DrawNo: d.DrawNo}
+	})
+}
+
+// sflifeLatestDraw returns the stored Set For Life draw with the highest draw number.
+func (r RESTFul) sflifeLatestDraw(req *http.Request) (response, error) {
+	d, err := sflife.LatestDraw(req.Context(), r.stores.SFLife)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	return response{status: http.StatusOK, data: d}, nil
 }
 
-// SFLifeLBallFrequencies returns the frequencies of Set For Life life balls.
-func (r RESTFul) SFLifeLBallFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := sflife.CalculateLBallFreq(req.Context(), r.db)
+// sflifeDraw returns the stored Set For Life draw with the draw number of the path.
+func (r RESTFul) sflifeDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
+	}
+	d, err := r.stores.SFLife.GetDraw(req.Context(), no)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// sflifeUpdateDraw replaces the stored Set For Life draw with the draw number of the
+// path by the draw of the JSON body, once the draw passes sflife.CheckDraw.
+func (r RESTFul) sflifeUpdateDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	var d sflife.Draw
+	if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	if err := checkDrawNo(&d.DrawNo, no); err != nil {
+		return response{}, err
+	}
+	d.DayOfWeek = d.DrawDate.Weekday()
+	if err := sflife.CheckDraw(d); err != nil {
+		return response{}, err
+	}
+	if err := r.stores.SFLife.UpdateDraw(req.Context(), d); err != nil {
+		return response{}, err
+	}
+	r.cache.Invalidate("sflife")
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// sflifeDeleteDraw removes the stored Set For Life draw with the draw number of the
+// path.
+func (r RESTFul) sflifeDeleteDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	if err := r.stores.SFLife.DeleteDraw(req.Context(), no); err != nil {
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	r.cache.Invalidate("sflife")
+	return response{status: http.StatusNoContent}, nil
 }
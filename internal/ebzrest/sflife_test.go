@@ -8,9 +8,12 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/paulwizviz/lotterystat/internal/ebzrest"
+	"github.com/paulwizviz/lotterystat/internal/ebzstore"
 	"github.com/paulwizviz/lotterystat/internal/sflife"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +31,9 @@ func TestSFLifeHandlers(t *testing.T) {
 	}
 
 	mux := http.NewServeMux()
-	ebzrest.New(mux, db)
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	ebzrest.New(mux, stores)
 
 	// Test CSV Upload
 	t.Run("Upload CSV", func(t *testing.T) {
@@ -53,6 +58,20 @@ func TestSFLifeHandlers(t *testing.T) {
 		assert.Equal(t, http.StatusAccepted, rr.Code)
 	})
 
+	// Test JSON Upload
+	t.Run("Upload JSON", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/sflife/csv", strings.NewReader(`[{"draw_date":"2026-02-23T00:00:00Z","ball1":1,"ball2":2,"ball3":3,"ball4":4,"ball5":5,"lball":6,"ball_set":"SFL1","machine":"Excalibur1","draw_no":725}]`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		latest, err := sflife.LatestDraw(context.TODO(), sflife.NewSQLiteStore(db))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(725), latest.DrawNo)
+	})
+
 	// Test Ball Frequencies
 	t.Run("Get Ball Frequencies", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/sflife/draw/frequency", nil)
@@ -85,3 +104,122 @@ func TestSFLifeHandlers(t *testing.T) {
 		assert.NotEmpty(t, freqs)
 	})
 }
+
+func TestSFLifeDrawResources(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, sflife.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	stores := ebzstore.NewSQLite(db)
+	defer stores.Close()
+	ebzrest.New(mux, stores)
+
+	for i, date := range []time.Time{time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, time.February, 23, 0, 0, 0, 0, time.UTC)} {
+		d := sflife.Draw{DrawDate: date, DayOfWeek: date.Weekday(), Ball1: uint8(i + 1), Ball2: 10, Ball3: 11, Ball4: 12, Ball5: 13, LBall: 1, DrawNo: uint64(i + 1)}
+		if err := stores.SFLife.PersistDraw(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Paginate draws", func(t *testing.T) {
+		var page ebzrest.Envelope[[]sflife.Draw]
+		target := "/api/v1/sflife/draws?limit=2"
+		for _, want := range [][]uint64{{1, 2}, {3}} {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			page = ebzrest.Envelope[[]sflife.Draw]{}
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+			assert.Equal(t, want, drawNumbers(page.Data, func(d sflife.Draw) uint64 { return d.DrawNo }))
+			target = "/api/v1/sflife/draws?limit=2&cursor=" + page.Meta.NextCursor
+		}
+		assert.Empty(t, page.Meta.NextCursor)
+	})
+
+	testcases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantPage   []uint64
+		wantDraw   uint64
+	}{
+		{name: "list draws", method: "GET", target: "/api/v1/sflife/draws", wantStatus: http.StatusOK, wantPage: []uint64{1, 2, 3}},
+		{name: "filter by date descending", method: "GET", target: "/api/v1/sflife/draws?from=2026-02-19&order=desc", wantStatus: http.StatusOK, wantPage: []uint64{3, 2}},
+		{name: "filter by draw number", method: "GET", target: "/api/v1/sflife/draws?from_draw=2&to_draw=2", wantStatus: http.StatusOK, wantPage: []uint64{2}},
+		{name: "filter by balls", method: "GET", target: "/api/v1/sflife/draws?contains=1,10", wantStatus: http.StatusOK, wantPage: []uint64{1}},
+		{name: "sort by draw number with offset", method: "GET", target: "/api/v1/sflife/draws?sort=draw_no&order=desc&offset=1", wantStatus: http.StatusOK, wantPage: []uint64{2, 1}},
+		{name: "invalid sort", method: "GET", target: "/api/v1/sflife/draws?sort=ball", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: "GET", target: "/api/v1/sflife/draws?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "invalid cursor", method: "GET", target: "/api/v1/sflife/draws?cursor=!!", wantStatus: http.StatusBadRequest},
+		{name: "latest draw", method: "GET", target: "/api/v1/sflife/draws/latest", wantStatus: http.StatusOK, wantDraw: 3},
+		{name: "draw", method: "GET", target: "/api/v1/sflife/draws/2", wantStatus: http.StatusOK, wantDraw: 2},
+		{name: "missing draw", method: "GET", target: "/api/v1/sflife/draws/99", wantStatus: http.StatusNotFound},
+		{name: "invalid draw number", method: "GET", target: "/api/v1/sflife/draws/two", wantStatus: http.StatusBadRequest},
+		{name: "update draw", method: "PUT", target: "/api/v1/sflife/draws/2", body: `{"draw_date":"2026-02-19T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":1,"draw_no":0}`, wantStatus: http.StatusOK, wantDraw: 2},
+		{name: "update other draw number", method: "PUT", target: "/api/v1/sflife/draws/2", body: `{"draw_date":"2026-02-19T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":1,"draw_no":3}`, wantStatus: http.StatusBadRequest},
+		{name: "update repeated balls", method: "PUT", target: "/api/v1/sflife/draws/2", body: `{"draw_date":"2026-02-19T00:00:00Z","ball1":10,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":1,"draw_no":2}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "update ball outside pool", method: "PUT", target: "/api/v1/sflife/draws/2", body: `{"draw_date":"2026-02-19T00:00:00Z","ball1":48,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":1,"draw_no":2}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "update missing draw", method: "PUT", target: "/api/v1/sflife/draws/99", body: `{"draw_date":"2026-02-19T00:00:00Z","ball1":20,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":1,"draw_no":0}`, wantStatus: http.StatusNotFound},
+		{name: "delete draw", method: "DELETE", target: "/api/v1/sflife/draws/1", wantStatus: http.StatusNoContent},
+		{name: "delete missing draw", method: "DELETE", target: "/api/v1/sflife/draws/1", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantStatus >= http.StatusBadRequest {
+				assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			}
+			if tc.wantPage != nil {
+				var page ebzrest.Envelope[[]sflife.Draw]
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+				assert.Equal(t, tc.wantPage, drawNumbers(page.Data, func(d sflife.Draw) uint64 { return d.DrawNo }))
+			}
+			if tc.wantDraw > 0 {
+				var d ebzrest.Envelope[sflife.Draw]
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&d))
+				assert.Equal(t, tc.wantDraw, d.Data.DrawNo)
+			}
+		})
+	}
+}
+
+func TestSFLifeUpdateDrawEraRange(t *testing.T) {
+	ctx := context.TODO()
+	mux := http.NewServeMux()
+	stores := ebzstore.NewMemory()
+	ebzrest.New(mux, stores)
+
+	date := time.Date(2019, time.March, 21, 0, 0, 0, 0, time.UTC)
+	want := sflife.Draw{DrawDate: date, DayOfWeek: date.Weekday(), Ball1: 1, Ball2: 10, Ball3: 11, Ball4: 12, Ball5: 13, LBall: 3, DrawNo: 1}
+	if err := stores.SFLife.PersistDraw(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+
+	// The largest life ball is 10
+	body := `{"draw_date":"2019-03-21T00:00:00Z","ball1":1,"ball2":10,"ball3":11,"ball4":12,"ball5":13,"lball":11,"draw_no":1}`
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("PUT", "/api/v1/sflife/draws/1", strings.NewReader(body)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+
+	got, err := stores.SFLife.GetDraw(ctx, 1)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, want, got)
+}
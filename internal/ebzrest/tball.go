@@ -1,52 +1,159 @@
 package ebzrest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/jobops"
+	"github.com/paulwizviz/lotterystat/internal/logops"
 	"github.com/paulwizviz/lotterystat/internal/tball"
 )
 
-// TBallUploadCSV handles the upload of a Thunderball CSV file and persists the draws.
-func (r RESTFul) TBallUploadCSV(rw http.ResponseWriter, req *http.Request) {
-	file, _, err := req.FormFile("file")
-	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
+// tballUploadCSV starts a job persisting the draws of an uploaded Thunderball CSV, JSON,
+// NDJSON or XLSX file.
+func (r RESTFul) tballUploadCSV(req *http.Request) (response, error) {
+	return r.startImport(req, r.persistTBall)
+}
 
-	recs := csvops.ExtractRec(req.Context(), file)
+// persistTBall persists the Thunderball draws of an uploaded file in the format. The
+// cached responses of the game are invalidated once it returns.
+func (r RESTFul) persistTBall(ctx context.Context, file io.Reader, format csvops.Format, report func(jobops.Progress)) (ImportResult, error) {
+	defer r.cache.Invalidate("tball")
+	recs := csvops.Extract(ctx, file, format, tball.RecordOf)
 	drawChans := tball.ProcessCSV(recs, 1)
 
+	res := ImportResult{
+		Game:    "tball",
+		Format:  string(format),
+		Records: len(drawChans),
+	}
+	draws := []tball.Draw{}
 	for _, dc := range drawChans {
 		if dc.Err != nil {
+			slog.DebugContext(ctx, "skipping record", "game", "tball", "line", dc.Line, logops.Err(dc.Err))
+			res.fail(dc.Err)
 			continue
 		}
-		_ = tball.PersistsDraw(req.Context(), r.db, dc.Draw)
+		draws = append(draws, dc.Draw)
+	}
+	report(res.progress(res.Failed))
+
+	draws, violations, err := tball.CheckImport(ctx, r.stores.TBall, draws, r.reject)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res.Violations = len(violations)
+	done := res.Records - len(draws)
+	report(res.progress(done))
+	for _, d := range draws {
+		err := r.stores.TBall.PersistDraw(ctx, d)
+		switch {
+		case err == nil:
+			res.Persisted++
+		case errors.Is(err, drawops.ErrStored):
+			res.Skipped++
+		case errors.Is(err, drawops.ErrRefused):
+			slog.DebugContext(ctx, "draw refused", "game", "tball", "draw_no", d.DrawNo, logops.Err(err))
+			res.fail(err)
+		default:
+			return ImportResult{}, err
+		}
+		done++
+		report(res.progress(done))
+	}
+	return res, nil
+}
+
+// tballDrawFrequencies returns the frequencies of Thunderball draw balls.
+func (r RESTFul) tballDrawFrequencies(req *http.Request) (response, error) {
+	freqs, err := tball.CalculateBallFreq(req.Context(), r.stores.TBall)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: freqs}, nil
+}
+
+// tballFrequencies returns the frequencies of Thunderball thunderballs.
+func (r RESTFul) tballFrequencies(req *http.Request) (response, error) {
+	freqs, err := tball.CalculateTBallFreq(req.Context(), r.stores.TBall)
+	if err != nil {
+		return response{}, err
 	}
-	rw.WriteHeader(http.StatusAccepted)
+	return response{status: http.StatusOK, data: freqs}, nil
 }
 
-// TBallDrawFrequencies returns the frequencies of Thunderball draw balls.
-func (r RESTFul) TBallDrawFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := tball.CalculateBallFreq(req.Context(), r.db)
+// tballDraws returns a page of the stored Thunderball draws selected by the query
+// parameters.
+func (r RESTFul) tballDraws(req *http.Request) (response, error) {
+	return listDraws(req, r.stores.TBall.ListDraws, func(d tball.Draw) drawops.Cursor {
+		return drawops.Cursor{Date: d.DrawDate, DrawNo: d.DrawNo}
+	})
+}
+
+// tballLatestDraw returns the stored Thunderball draw with the highest draw number.
+func (r RESTFul) tballLatestDraw(req *http.Request) (response, error) {
+	d, err := tball.LatestDraw(req.Context(), r.stores.TBall)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	return response{status: http.StatusOK, data: d}, nil
 }
 
-// TBallFrequencies returns the frequencies of Thunderball thunderballs.
-func (r RESTFul) TBallFrequencies(rw http.ResponseWriter, req *http.Request) {
-	freqs, err := tball.CalculateTBallFreq(req.Context(), r.db)
+// tballDraw returns the stored Thunderball draw with the draw number of the path.
+func (r RESTFul) tballDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
-		return
+		return response{}, err
+	}
+	d, err := r.stores.TBall.GetDraw(req.Context(), no)
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// tballUpdateDraw replaces the stored Thunderball draw with the draw number of the
+// path by the draw of the JSON body, once the draw passes tball.CheckDraw.
+func (r RESTFul) tballUpdateDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	var d tball.Draw
+	if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+		return response{}, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	if err := checkDrawNo(&d.DrawNo, no); err != nil {
+		return response{}, err
+	}
+	d.DayOfWeek = d.DrawDate.Weekday()
+	if err := tball.CheckDraw(d); err != nil {
+		return response{}, err
+	}
+	if err := r.stores.TBall.UpdateDraw(req.Context(), d); err != nil {
+		return response{}, err
+	}
+	r.cache.Invalidate("tball")
+	return response{status: http.StatusOK, data: d}, nil
+}
+
+// tballDeleteDraw removes the stored Thunderball draw with the draw number of the
+// path.
+func (r RESTFul) tballDeleteDraw(req *http.Request) (response, error) {
+	no, err := parseDrawNo(req)
+	if err != nil {
+		return response{}, err
+	}
+	if err := r.stores.TBall.DeleteDraw(req.Context(), no); err != nil {
+		return response{}, err
 	}
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(freqs)
+	r.cache.Invalidate("tball")
+	return response{status: http.StatusNoContent}, nil
 }
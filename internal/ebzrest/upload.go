@@ -0,0 +1,40 @@
+package ebzrest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/paulwizviz/lotterystat/internal/csvops"
+)
+
+// upload reads an uploaded file, possibly after bytes inspected to detect
+// its format, and closes the file
+type upload struct {
+	io.Reader
+	io.Closer
+}
+
+// openUpload returns the uploaded file and its format. The file is the form
+// file named file of a multipart request, otherwise the request body. The
+// format is given by the content type of the file, failing that by the name
+// or content of the file.
+func openUpload(req *http.Request) (io.ReadCloser, csvops.Format, error) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data") {
+		if format, ok := csvops.FormatOfContentType(req.Header.Get("Content-Type")); ok {
+			return req.Body, format, nil
+		}
+		format, r := csvops.DetectFormat("", req.Body)
+		return upload{Reader: r, Closer: req.Body}, format, nil
+	}
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	if format, ok := csvops.FormatOfContentType(header.Header.Get("Content-Type")); ok {
+		return file, format, nil
+	}
+	format, r := csvops.DetectFormat(header.Filename, file)
+	return upload{Reader: r, Closer: file}, format, nil
+}
@@ -0,0 +1,2 @@
+// Package ebzstore contains the draw stores of every game used by the ebz commands and RESTFul endpoints.
+package ebzstore
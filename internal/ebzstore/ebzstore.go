@@ -0,0 +1,62 @@
+package ebzstore
+
+import (
+	"database/sql"
+
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+)
+
+// writeQueueSize is the number of writes that wait for the writer
+const writeQueueSize = 64
+
+// Stores are the draw stores of every game
+type Stores struct {
+	TBall  tball.DrawStore
+	Euro   euro.DrawStore
+	Lotto  lotto.DrawStore
+	SFLife sflife.DrawStore
+
+	close func() error
+}
+
+// NewSQLite returns the stores of the draws in the tables of a SQLite
+// database. The stores share prepared statements and a queue running their
+// writes one at a time, released by Close. The options configure the cache
+// of prepared statements.
+func NewSQLite(db *sql.DB, opts ...sqlops.CacheOption) Stores {
+	queue := sqlops.NewWriteQueue(writeQueueSize)
+	stmts := sqlops.NewStmtCache(db, queue, opts...)
+	return Stores{
+		TBall:  tball.NewSQLiteStore(stmts),
+		Euro:   euro.NewSQLiteStore(stmts),
+		Lotto:  lotto.NewSQLiteStore(stmts),
+		SFLife: sflife.NewSQLiteStore(stmts),
+		close: func() error {
+			queue.Close()
+			return stmts.Close()
+		},
+	}
+}
+
+// NewMemory returns empty stores of draws in memory
+func NewMemory() Stores {
+	return Stores{
+		TBall:  tball.NewMemStore(),
+		Euro:   euro.NewMemStore(),
+		Lotto:  lotto.NewMemStore(),
+		SFLife: sflife.NewMemStore(),
+	}
+}
+
+// Close stops the write queue and closes the statements of SQLite stores.
+// The database itself is left open.
+func (s Stores) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
@@ -3,6 +3,7 @@ package euro
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
@@ -31,7 +32,7 @@ func ProcessCSV(recs chan csvops.CSVRec, numWorkers int) []DrawChan {
 
 func csvWorker(jobs chan csvops.CSVRec, results chan DrawChan) {
 	for j := range jobs {
-		drawChan := DrawChan{}
+		drawChan := DrawChan{Line: j.Line}
 		if errors.Is(j.Err, csvops.ErrLine) {
 			drawChan.Draw = Draw{}
 			drawChan.Err = ErrRec
@@ -51,7 +52,6 @@ func csvWorker(jobs chan csvops.CSVRec, results chan DrawChan) {
 }
 
 func processRecord(rec []string) (Draw, error) {
-	maxValue := 50
 	draw := Draw{}
 
 	if len(rec) < 10 {
@@ -65,6 +65,12 @@ func processRecord(rec []string) (Draw, error) {
 	draw.DrawDate = dt
 	draw.DayOfWeek = dt.Weekday()
 
+	era, err := EraAt(dt)
+	if err != nil {
+		return draw, fmt.Errorf("%w-%v", ErrDrawDate, err)
+	}
+	maxValue := era.MaxBall
+
 	ball1, err := csvops.ParseDrawNum(rec[1], maxValue)
 	if err != nil {
 		return draw, fmt.Errorf("%w-%v", ErrBall1, err)
@@ -95,13 +101,13 @@ func processRecord(rec []string) (Draw, error) {
 	}
 	draw.Ball5 = ball5
 
-	star1, err := csvops.ParseDrawNum(rec[6], 13)
+	star1, err := csvops.ParseDrawNum(rec[6], era.MaxStar)
 	if err != nil {
 		return draw, fmt.Errorf("%w-%v", ErrStar1, err)
 	}
 	draw.Star1 = star1
 
-	star2, err := csvops.ParseDrawNum(rec[7], 13)
+	star2, err := csvops.ParseDrawNum(rec[7], era.MaxStar)
 	if err != nil {
 		return draw, fmt.Errorf("%w-%v", ErrStar2, err)
 	}
@@ -127,3 +133,44 @@ func processRecord(rec []string) (Draw, error) {
 
 	return draw, nil
 }
+
+// CSVHeader is the header of the National Lottery CSV layout
+var CSVHeader = []string{"DrawDate", "Ball 1", "Ball 2", "Ball 3", "Ball 4", "Ball 5", "Lucky Star 1", "Lucky Star 2", "UK Millionaire Maker", "European Millionaire Maker", "Ball Set", "Machine", "DrawNumber"}
+
+// FormatRecord converts a draw to a record of the National Lottery CSV
+// layout, which ProcessCSV converts back to the draw
+func FormatRecord(d Draw) []string {
+	return []string{d.DrawDate.Format(csvops.DateLayout), fmt.Sprint(d.Ball1), fmt.Sprint(d.Ball2), fmt.Sprint(d.Ball3), fmt.Sprint(d.Ball4), fmt.Sprint(d.Ball5),
+		fmt.Sprint(d.Star1), fmt.Sprint(d.Star2), d.UKMaker, d.EUMaker, d.BallSet, d.Machine, fmt.Sprint(d.DrawNo)}
+}
+
+// RecordOf converts the fields of a draw, named by the JSON tags of Draw, to
+// a record of the National Lottery CSV layout. It is the csvops.RecordFunc
+// of JSON, NDJSON and XLSX sources.
+func RecordOf(fields map[string]string) []string {
+	rec := []string{csvops.CSVDate(fields["draw_date"])}
+	for _, name := range []string{"ball1", "ball2", "ball3", "ball4", "ball5", "star1", "star2", "uk_maker", "eu_maker", "ball_set", "machine"} {
+		rec = append(rec, fields[name])
+	}
+	return append(rec, fields["draw_no"])
+}
+
+// MatchHeader reports whether the header of a National Lottery CSV file is
+// of EuroMillions draws
+func MatchHeader(header []string) bool {
+	return slices.Contains(header, "Lucky Star 1")
+}
+
+// Match reports whether a record is a draw of EuroMillions: the header, if the
+// record has one, is a EuroMillions header and the values of the record are
+// within the ranges of the EuroMillions era of its draw date
+func Match(rec csvops.CSVRec) bool {
+	if rec.Err != nil {
+		return false
+	}
+	if rec.Header != nil && !MatchHeader(rec.Header) {
+		return false
+	}
+	_, err := processRecord(rec.Record)
+	return err == nil
+}
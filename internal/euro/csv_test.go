@@ -3,13 +3,16 @@ package euro
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
+	"github.com/paulwizviz/lotterystat/internal/exportops"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -63,7 +66,8 @@ var (
 						Machine:   "13",
 						DrawNo:    1922,
 					},
-					Err: nil,
+					Line: 1,
+					Err:  nil,
 				},
 			},
 		},
@@ -128,6 +132,36 @@ var (
 				},
 			},
 		},
+		{
+			name: fmt.Sprintf("%s-historic lucky star pool", testUnappyPath),
+			input: func() io.Reader {
+				b := []byte(`DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Lucky Star 1,Lucky Star 2,UK Millionaire Maker,European Millionaire Maker,Ball Set,Machine,DrawNumber
+10-Dec-2010,13,24,28,33,35,5,10,ZDTF34718,,21,13,360
+`)
+				return bytes.NewReader(b)
+			}(),
+			expected: []DrawChan{
+				{
+					Draw: Draw{},
+					Err:  ErrStar2,
+				},
+			},
+		},
+		{
+			name: fmt.Sprintf("%s-before first draw", testUnappyPath),
+			input: func() io.Reader {
+				b := []byte(`DrawDate,Ball 1,Ball 2,Ball 3,Ball 4,Ball 5,Lucky Star 1,Lucky Star 2,UK Millionaire Maker,European Millionaire Maker,Ball Set,Machine,DrawNumber
+6-Feb-2004,13,24,28,33,35,5,9,ZDTF34718,,21,13,1
+`)
+				return bytes.NewReader(b)
+			}(),
+			expected: []DrawChan{
+				{
+					Draw: Draw{},
+					Err:  ErrDrawDate,
+				},
+			},
+		},
 	}
 )
 
@@ -140,3 +174,87 @@ func TestProcessCSV(t *testing.T) {
 		}
 	}
 }
+
+// testRecordDraw is a draw converted to and from records
+var testRecordDraw = Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Friday, Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, UKMaker: "ZDTF34718", EUMaker: "EU123", BallSet: "21", Machine: "13", DrawNo: 1922}
+
+func TestFormatRecord(t *testing.T) {
+	earlier := testRecordDraw
+	earlier.DrawDate = earlier.DrawDate.AddDate(0, 0, -7)
+	earlier.DrawNo--
+	want := []Draw{earlier, testRecordDraw}
+
+	store := NewMemStore()
+	for _, d := range want {
+		if err := store.PersistDraw(context.TODO(), d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tables, err := ExportTables(context.TODO(), store, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := exportops.Write(&buf, exportops.CSV, tables...); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+
+	got := []Draw{}
+	for _, dc := range ProcessCSV(csvops.ExtractRec(context.TODO(), &buf), 1) {
+		if dc.Err != nil {
+			t.Fatalf("Unmatch error. Want: %v Got: %v", nil, dc.Err)
+		}
+		got = append(got, dc.Draw)
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestRecordOf(t *testing.T) {
+	want := testRecordDraw
+	array, _ := json.Marshal([]Draw{want})
+	line, _ := json.Marshal(want)
+
+	testcases := []struct {
+		name   string
+		format csvops.Format
+		input  []byte
+	}{
+		{name: "json", format: csvops.JSON, input: array},
+		{name: "ndjson", format: csvops.NDJSON, input: append(line, '\n')},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recs := csvops.Extract(context.TODO(), bytes.NewReader(tc.input), tc.format, RecordOf)
+			got := ProcessCSV(recs, 1)
+			assert.Equal(t, []DrawChan{{Draw: want, Line: 1}}, got)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rec := FormatRecord(testRecordDraw)
+	outOfRange := slices.Clone(rec)
+	outOfRange[6] = "99"
+	otherHeader := slices.Clone(CSVHeader)
+	otherHeader[6] = "Other"
+
+	testcases := []struct {
+		name  string
+		input csvops.CSVRec
+		want  bool
+	}{
+		{name: "EuroMillions header", input: csvops.CSVRec{Header: CSVHeader, Record: rec}, want: true},
+		{name: "no header", input: csvops.CSVRec{Record: rec}, want: true},
+		{name: "other header", input: csvops.CSVRec{Header: otherHeader, Record: rec}, want: false},
+		{name: "out of range", input: csvops.CSVRec{Header: CSVHeader, Record: outOfRange}, want: false},
+		{name: "short record", input: csvops.CSVRec{Header: CSVHeader, Record: rec[:5]}, want: false},
+		{name: "line error", input: csvops.CSVRec{Header: CSVHeader, Record: rec, Err: csvops.ErrLine}, want: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Match(tc.input))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package euro
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEraAt(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   time.Time
+		want    Era
+		wantErr error
+	}{
+		{
+			name:    "launch",
+			input:   time.Date(2004, time.February, 13, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "nine stars",
+			input:   time.Date(2011, time.May, 6, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "eleven stars",
+			input:   time.Date(2011, time.May, 10, 0, 0, 0, 0, time.UTC),
+			want:    Eras[1],
+			wantErr: nil,
+		},
+		{
+			name:    "twelve stars",
+			input:   time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC),
+			want:    Eras[2],
+			wantErr: nil,
+		},
+		{
+			name:    "before launch",
+			input:   time.Date(2004, time.February, 6, 0, 0, 0, 0, time.UTC),
+			want:    Era{},
+			wantErr: ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := EraAt(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExpectedFreq(t *testing.T) {
+	// One draw in each era
+	drawsPerEra := make([]int, len(Eras))
+	for i := range drawsPerEra {
+		drawsPerEra[i] = 1
+	}
+	got := expectedFreq(drawsPerEra, MaxStar(),
+		func(e Era) int { return e.MaxStar },
+		func(e Era) int { return e.StarCount })
+
+	if len(got) != MaxStar() {
+		t.Fatalf("expected %d frequencies, got %d", MaxStar(), len(got))
+	}
+
+	// A lucky star is only expected in the eras whose pool contains it
+	for b, want := range got {
+		sum := 0.0
+		for _, e := range Eras {
+			if b < e.MaxStar {
+				sum += float64(e.StarCount) / float64(e.MaxStar)
+			}
+		}
+		assert.InDelta(t, sum, want, 1e-9, fmt.Sprintf("lucky star %d", b+1))
+	}
+	assert.Greater(t, got[0], got[MaxStar()-1])
+}
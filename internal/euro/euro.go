@@ -2,9 +2,11 @@ package euro
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"regexp"
 	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/logops"
 )
 
 const (
@@ -22,36 +24,73 @@ var (
 	ErrStar2    = errors.New("invalid lucky star 2")
 	ErrSeq      = errors.New("invalid seq")
 	ErrRec      = errors.New("invalid record")
+	ErrNoEra    = errors.New("no game rules for draw date")
 )
 
+var (
+	// Integrity
+	ErrDuplicateBall = errors.New("duplicate main ball")
+	ErrDuplicateStar = errors.New("duplicate lucky star")
+	ErrBallRange     = errors.New("main ball out of range of era")
+	ErrStarRange     = errors.New("lucky star out of range of era")
+	ErrDrawDay       = errors.New("no draw on day of week")
+	ErrDrawOrder     = errors.New("draw number out of order with draw date")
+	ErrDrawConflict  = errors.New("draw number repeated with different contents")
+)
+
+// Era represents the EuroMillions rules effective from a draw date
+type Era struct {
+	From      time.Time      // First draw date the rules apply to
+	BallCount int            // Number of main balls drawn
+	MaxBall   int            // Size of the main ball pool
+	StarCount int            // Number of lucky stars drawn
+	MaxStar   int            // Size of the lucky star pool
+	DrawDays  []time.Weekday // Days of the week the game is drawn
+}
+
+// Eras lists the EuroMillions rules in chronological order
+var Eras = []Era{
+	{From: time.Date(2004, time.February, 13, 0, 0, 0, 0, time.UTC), BallCount: 5, MaxBall: 50, StarCount: 2, MaxStar: 9, DrawDays: []time.Weekday{time.Friday}},
+	{From: time.Date(2011, time.May, 10, 0, 0, 0, 0, time.UTC), BallCount: 5, MaxBall: 50, StarCount: 2, MaxStar: 11, DrawDays: []time.Weekday{time.Tuesday, time.Friday}},
+	{From: time.Date(2016, time.September, 24, 0, 0, 0, 0, time.UTC), BallCount: 5, MaxBall: 50, StarCount: 2, MaxStar: 12, DrawDays: []time.Weekday{time.Tuesday, time.Friday}},
+}
+
 // Draw represents a line from euro draw results
 type Draw struct {
-	DrawDate  time.Time    `json:"draw_date"`
-	DayOfWeek time.Weekday `json:"day_of_week"`
-	Ball1     uint8        `json:"ball1"`
-	Ball2     uint8        `json:"ball2"`
-	Ball3     uint8        `json:"ball3"`
-	Ball4     uint8        `json:"ball4"`
-	Ball5     uint8        `json:"ball5"`
-	Star1     uint8        `json:"star1"`
-	Star2     uint8        `json:"star2"`
-	UKMaker   string       `json:"uk_maker"`
-	EUMaker   string       `json:"eu_maker"`
-	BallSet   string       `json:"ball_set"`
-	Machine   string       `json:"machine"`
-	DrawNo    uint64       `json:"draw_no"`
+	DrawDate  time.Time    `json:"draw_date" parquet:"draw_date,timestamp(millisecond)"`
+	DayOfWeek time.Weekday `json:"day_of_week" parquet:"day_of_week"`
+	Ball1     uint8        `json:"ball1" parquet:"ball1"`
+	Ball2     uint8        `json:"ball2" parquet:"ball2"`
+	Ball3     uint8        `json:"ball3" parquet:"ball3"`
+	Ball4     uint8        `json:"ball4" parquet:"ball4"`
+	Ball5     uint8        `json:"ball5" parquet:"ball5"`
+	Star1     uint8        `json:"star1" parquet:"star1"`
+	Star2     uint8        `json:"star2" parquet:"star2"`
+	UKMaker   string       `json:"uk_maker" parquet:"uk_maker"`
+	EUMaker   string       `json:"eu_maker" parquet:"eu_maker"`
+	BallSet   string       `json:"ball_set" parquet:"ball_set"`
+	Machine   string       `json:"machine" parquet:"machine"`
+	DrawNo    uint64       `json:"draw_no" parquet:"draw_no"`
 }
 
 type DrawChan struct {
 	Draw Draw
+	Line uint // Line of the record in its file, 0 if unknown
 	Err  error
 }
 
+// Violation represents a draw failing an integrity check
+type Violation struct {
+	DrawNo  uint64 // Draw failing the check
+	Related uint64 // Other draw involved in a check across draws, otherwise 0
+	Err     error
+}
+
 func IsValidBall(arg string) bool {
 	pattern := `^\b([1-9]|[1-4][0-9]|50)\b(,\b([1-9]|[1-4][0-9]|50)\b)*$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 		return matched
 	}
 	return matched
@@ -61,7 +100,7 @@ func IsValidStars(arg string) bool {
 	pattern := `^\b([1-9]|1[0-3])\b(,\b([1-9]|1[0-3])\b)*$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 	}
 	return matched
 }
@@ -0,0 +1,131 @@
+package euro_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDraw(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   euro.Draw
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			input:   euro.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921},
+			wantErr: nil,
+		},
+		{
+			name:    "duplicate main ball",
+			input:   euro.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 13, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921},
+			wantErr: euro.ErrDuplicateBall,
+		},
+		{
+			name:    "duplicate lucky star",
+			input:   euro.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 5, DrawNo: 1922},
+			wantErr: euro.ErrDuplicateStar,
+		},
+		{
+			name:    "no draw on day",
+			input:   euro.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921},
+			wantErr: euro.ErrDrawDay,
+		},
+		{
+			name:    "main ball outside era",
+			input:   euro.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 51, Star1: 5, Star2: 9, DrawNo: 1921},
+			wantErr: euro.ErrBallRange,
+		},
+		{
+			name:    "lucky star outside era",
+			input:   euro.Draw{DrawDate: time.Date(2005, time.March, 4, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 12, DrawNo: 56},
+			wantErr: euro.ErrStarRange,
+		},
+		{
+			name:    "before first draw",
+			input:   euro.Draw{DrawDate: time.Date(1990, time.January, 6, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921},
+			wantErr: euro.ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := euro.CheckDraw(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d1 := euro.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921}
+	d2 := euro.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Star1: 1, Star2: 2, DrawNo: 1922}
+
+	t.Run("consistent draws", func(t *testing.T) {
+		assert.Empty(t, euro.Verify([]euro.Draw{d2, d1, d1}))
+	})
+
+	t.Run("repeated draw number", func(t *testing.T) {
+		conflict := d2
+		conflict.DrawNo = d1.DrawNo
+		conflict.DrawDate = d1.DrawDate
+		got := euro.Verify([]euro.Draw{d1, conflict})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, euro.ErrDrawConflict)
+	})
+
+	t.Run("draw number out of order", func(t *testing.T) {
+		late := d1
+		late.DrawNo = d2.DrawNo + 1
+		got := euro.Verify([]euro.Draw{d1, d2, late})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, euro.ErrDrawOrder)
+		assert.Equal(t, late.DrawNo, got[0].DrawNo)
+		assert.Equal(t, d2.DrawNo, got[0].Related)
+	})
+}
+
+func TestCheckImport(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, euro.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	d1 := euro.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1921}
+	if err := euro.PersistsDraw(ctx, db, d1); err != nil {
+		t.Fatal(err)
+	}
+
+	d2 := euro.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Star1: 1, Star2: 2, DrawNo: 1922}
+	conflict := d2
+	conflict.DrawNo = d1.DrawNo
+	conflict.DrawDate = d1.DrawDate
+
+	t.Run("warn", func(t *testing.T) {
+		draws, violations, err := euro.CheckImport(ctx, euro.NewSQLiteStore(db), []euro.Draw{d2, conflict}, false)
+		assert.NoError(t, err)
+		assert.Len(t, draws, 2)
+		assert.Len(t, violations, 1)
+		assert.ErrorIs(t, violations[0].Err, euro.ErrDrawConflict)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		draws, violations, err := euro.CheckImport(ctx, euro.NewSQLiteStore(db), []euro.Draw{d2, conflict}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []euro.Draw{d2}, draws)
+		assert.Len(t, violations, 1)
+	})
+}
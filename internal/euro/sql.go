@@ -3,9 +3,12 @@ package euro
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 )
 
@@ -25,146 +28,237 @@ const (
 	ballset   = "ball_set"
 	machine   = "machine"
 	drawNo    = "draw_no"
+
+	dateLayout = time.DateOnly
+)
+
+var (
+	// tableSQL creates the table, named by its %s verb, at the latest version.
+	// Ball ranges are those of the largest pools of Eras, since the pools of
+	// each era are checked by the integrity checks of imported draws.
+	tableSQL = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %%s (
+        %[1]s TEXT NOT NULL CHECK (%[1]s GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]'),
+        %[2]s INTEGER NOT NULL CHECK (%[2]s BETWEEN 0 AND 6),
+        %[3]s INTEGER NOT NULL CHECK (%[3]s BETWEEN 1 AND 50),
+        %[4]s INTEGER NOT NULL CHECK (%[4]s BETWEEN 1 AND 50),
+        %[5]s INTEGER NOT NULL CHECK (%[5]s BETWEEN 1 AND 50),
+        %[6]s INTEGER NOT NULL CHECK (%[6]s BETWEEN 1 AND 50),
+        %[7]s INTEGER NOT NULL CHECK (%[7]s BETWEEN 1 AND 50),
+        %[8]s INTEGER NOT NULL CHECK (%[8]s BETWEEN 1 AND 12),
+        %[9]s INTEGER NOT NULL CHECK (%[9]s BETWEEN 1 AND 12),
+        %[10]s TEXT, %[11]s TEXT, %[12]s TEXT, %[13]s TEXT, %[14]s INTEGER PRIMARY KEY,
+        CHECK (%[3]s NOT IN (%[4]s, %[5]s, %[6]s, %[7]s) AND %[4]s NOT IN (%[5]s, %[6]s, %[7]s) AND %[5]s NOT IN (%[6]s, %[7]s) AND %[6]s <> %[7]s),
+        CHECK (%[8]s <> %[9]s))`,
+		drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo)
+
+	createTableSQL = fmt.Sprintf(tableSQL, tblName)
+	createIndexSQL = fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %[1]s_%[2]s ON %[1]s (%[2]s)`, tblName, drawDate)
+
+	// CreateTableFn creates the table and its index at the latest version
+	CreateTableFn sqlops.TblCreator = func(ctx context.Context, tx *sql.Tx) error {
+		for _, stmt := range []string{createTableSQL, createIndexSQL} {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 )
 
 var (
-	createTableSQL = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	// createTableV1SQL creates the table with dates stored as written by the
+	// SQLite driver for time.Time
+	createTableV1SQL = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
         %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s INTEGER, %s TEXT, %s TEXT, %s TEXT, %s TEXT, %s INTEGER PRIMARY KEY)`,
 		tblName, drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo)
 
-	CreateTableFn sqlops.TblCreator = func(ctx context.Context, tx *sql.Tx) error {
-		_, err := tx.ExecContext(ctx, createTableSQL)
-		if err != nil {
+	createTableV1Fn sqlops.TblCreator = func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, createTableV1SQL)
+		return err
+	}
+
+	columnsSQL   = strings.Join([]string{drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo}, ", ")
+	columnsV1SQL = strings.Join([]string{fmt.Sprintf("substr(%s, 1, 10)", drawDate), dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo}, ", ")
+
+	// migrateV2Fn rebuilds the table with dates stored as YYYY-MM-DD and with
+	// constraints. Rows failing the constraints are kept in euro_invalid.
+	migrateV2Fn sqlops.TblCreator = func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := sqlops.RebuildTable(ctx, tx, tblName, tableSQL, columnsSQL, columnsV1SQL); err != nil {
 			return err
 		}
-		return nil
+		_, err := tx.ExecContext(ctx, createIndexSQL)
+		return err
 	}
 )
 
+// Schema lists the migrations of the EuroMillions table in order of version
+var Schema = sqlops.Schema{
+	Name: tblName,
+	Migrations: []sqlops.Migration{
+		{Version: 1, Description: "create euro table", Up: createTableV1Fn},
+		{Version: 2, Description: "store draw_date as YYYY-MM-DD with constraints and index", Up: migrateV2Fn},
+	},
+}
+
 var (
 	writeDrawSQL = fmt.Sprintf(`INSERT INTO %s (
-	    %s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
-		tblName, drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo)
+	    %s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+	    ON CONFLICT (%s) DO NOTHING`,
+		tblName, drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo, drawNo)
 
-	writeDrawRowFn = func(ctx context.Context, stmt *sql.Stmt, data any) error {
-		d, ok := data.(Draw)
-		if !ok {
-			return fmt.Errorf("%w: invalid argument type", sqlops.ErrExecuteWriter)
+	writeDrawRowFn sqlops.RowWriter[Draw] = func(ctx context.Context, stmt *sql.Stmt, d Draw) error {
+		result, err := stmt.ExecContext(ctx, d.DrawDate.Format(dateLayout), d.DayOfWeek, d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Star1, d.Star2, d.UKMaker, d.EUMaker, d.BallSet, d.Machine, d.DrawNo)
+		if sqlops.IsConstraint(err) {
+			return fmt.Errorf("%w: draw %d: %v", drawops.ErrRefused, d.DrawNo, err)
 		}
-		_, err := stmt.ExecContext(ctx, d.DrawDate, d.DayOfWeek, d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Star1, d.Star2, d.UKMaker, d.EUMaker, d.BallSet, d.Machine, d.DrawNo)
 		if err != nil {
 			return fmt.Errorf("%w:%w", sqlops.ErrExecuteWriter, err)
 		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("%w: %d", drawops.ErrStored, d.DrawNo)
+		}
 		return nil
 	}
 )
 
-func PersistsDraw(ctx context.Context, db *sql.DB, data Draw) error {
-	return sqlops.Writer(ctx, db, writeDrawSQL, []any{data}, writeDrawRowFn)
+// PersistsDraw stores the draw, or returns drawops.ErrStored if a draw with
+// its draw number is stored
+func PersistsDraw(ctx context.Context, db sqlops.DB, data Draw) error {
+	return sqlops.Writer(ctx, db, writeDrawSQL, []Draw{data}, writeDrawRowFn)
 }
 
 var (
-	selectAllDrawSQL = fmt.Sprintf(`SELECT * FROM %s`, tblName)
-)
-
-func ListAllDraws(ctx context.Context, db *sql.DB) ([]Draw, error) {
+	updateDrawSQL = fmt.Sprintf(`UPDATE %s SET
+	    %s=$1,%s=$2,%s=$3,%s=$4,%s=$5,%s=$6,%s=$7,%s=$8,%s=$9,%s=$10,%s=$11,%s=$12,%s=$13 WHERE %s=$14`,
+		tblName, drawDate, dayOfWeek, ball1, ball2, ball3, ball4, ball5, star1, star2, ukmaker, eumaker, ballset, machine, drawNo)
 
-	result, err := sqlops.Query(ctx, db, func(rows *sql.Rows) (any, error) {
-		d := Draw{}
-		var drawDate string
-		err := rows.Scan(&drawDate, &d.DayOfWeek, &d.Ball1, &d.Ball2, &d.Ball3, &d.Ball4, &d.Ball5, &d.Star1, &d.Star2, &d.UKMaker, &d.EUMaker, &d.BallSet, &d.Machine, &d.DrawNo)
-		if err != nil {
-			return nil, fmt.Errorf("%w:%w", sqlops.ErrExecuteQuery, err)
+	updateDrawRowFn sqlops.RowWriter[Draw] = func(ctx context.Context, stmt *sql.Stmt, d Draw) error {
+		result, err := stmt.ExecContext(ctx, d.DrawDate.Format(dateLayout), d.DayOfWeek, d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Star1, d.Star2, d.UKMaker, d.EUMaker, d.BallSet, d.Machine, d.DrawNo)
+		if sqlops.IsConstraint(err) {
+			return fmt.Errorf("%w: draw %d: %v", drawops.ErrRefused, d.DrawNo, err)
 		}
-		d.DrawDate, err = time.Parse("2006-01-02 15:04:05 -0700 MST", drawDate)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("%w:%w", sqlops.ErrExecuteWriter, err)
 		}
-		return d, nil
-	}, selectAllDrawSQL)
-	if err != nil {
-		return nil, err
-	}
-
-	draws := []Draw{}
-	for _, item := range result {
-		d := item.(Draw)
-		draws = append(draws, d)
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("%w: %d", drawops.ErrNoDraw, d.DrawNo)
+		}
+		return nil
 	}
+)
 
-	return draws, nil
+// UpdateDraw replaces the stored draw with the draw number of d, or returns
+// drawops.ErrNoDraw if none is stored
+func UpdateDraw(ctx context.Context, db sqlops.DB, d Draw) error {
+	return sqlops.Writer(ctx, db, updateDrawSQL, []Draw{d}, updateDrawRowFn)
 }
 
-var (
-	countBallSQL = fmt.Sprintf(`SELECT COUNT(*) FROM %[1]s 
-	    WHERE %[2]s=$1 OR %[3]s=$1 OR %[4]s=$1 OR %[5]s=$1 OR %[6]s=$1;`,
-		tblName, ball1, ball2, ball3, ball4, ball5)
-)
+var deleteAllDrawSQL = fmt.Sprintf(`DELETE FROM %s`, tblName)
 
-type BallFrequency struct {
-	Ball      uint
-	Frequency uint
+// DeleteAllDraws removes every stored EuroMillions draw and returns the number of
+// draws removed
+func DeleteAllDraws(ctx context.Context, db sqlops.DB) (int64, error) {
+	return sqlops.Exec(ctx, db, deleteAllDrawSQL)
 }
 
-func CalculateBallFreq(ctx context.Context, db *sql.DB) ([]BallFrequency, error) {
+var deleteDrawSQL = fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, tblName, drawNo)
 
-	ballFreqs := []BallFrequency{}
-	ball := 0
-	for range 50 {
-		ball = ball + 1
-
-		bf := BallFrequency{
-			Ball: uint(ball),
-		}
+// DeleteDraw removes the stored draw with the draw number, or returns
+// drawops.ErrNoDraw if none is stored
+func DeleteDraw(ctx context.Context, db sqlops.DB, no uint64) error {
+	n, err := sqlops.Exec(ctx, db, deleteDrawSQL, no)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", drawops.ErrNoDraw, no)
+	}
+	return nil
+}
 
-		result, err := sqlops.Query(ctx, db, func(r *sql.Rows) (any, error) {
-			var count int
-			if err := r.Scan(&count); err != nil {
-				return nil, fmt.Errorf("%w: %v", sqlops.ErrExecuteQuery, err)
-			}
-			return count, nil
-		}, countBallSQL, bf.Ball)
+var (
+	selectAllDrawSQL = fmt.Sprintf(`SELECT * FROM %s`, tblName)
 
+	scanDraw sqlops.QueryScanner[Draw] = func(rows *sql.Rows) (Draw, error) {
+		d := Draw{}
+		var drawDate string
+		err := rows.Scan(&drawDate, &d.DayOfWeek, &d.Ball1, &d.Ball2, &d.Ball3, &d.Ball4, &d.Ball5, &d.Star1, &d.Star2, &d.UKMaker, &d.EUMaker, &d.BallSet, &d.Machine, &d.DrawNo)
 		if err != nil {
-			return nil, err
+			return Draw{}, err
 		}
+		d.DrawDate, err = time.Parse(dateLayout, drawDate)
+		return d, err
+	}
+)
+
+// ListAllDraws returns every stored draw
+func ListAllDraws(ctx context.Context, db sqlops.DB) ([]Draw, error) {
+	return sqlops.Query(ctx, db, scanDraw, selectAllDrawSQL)
+}
 
-		bf.Frequency = uint(result[0].(int))
-		ballFreqs = append(ballFreqs, bf)
+// ListDraws returns the stored draws selected and ordered by the filter
+func ListDraws(ctx context.Context, db sqlops.DB, filter drawops.Filter) ([]Draw, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
 	}
 
-	return ballFreqs, nil
+	query, args := filter.SelectSQL(tblName, drawDate, drawNo, ball1, ball2, ball3, ball4, ball5)
+	return sqlops.Query(ctx, db, scanDraw, query, args...)
+}
+
+var selectDrawSQL = fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1`, tblName, drawNo)
+
+// GetDraw returns the stored draw with the draw number, or
+// drawops.ErrNoDraw if none is stored
+func GetDraw(ctx context.Context, db sqlops.DB, no uint64) (Draw, error) {
+	d, err := sqlops.QueryOne(ctx, db, scanDraw, selectDrawSQL, no)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Draw{}, fmt.Errorf("%w: %d", drawops.ErrNoDraw, no)
+	}
+	return d, err
 }
 
 var (
-	countStarSQL = fmt.Sprintf("SELECT COUNT(*) FROM %[1]s WHERE %[2]s=$1 OR %[3]s=$1;", tblName, star1, star2)
+	selectDrawDateSQL = fmt.Sprintf(`SELECT %s FROM %s`, drawDate, tblName)
 )
 
-type StarFrequency struct {
-	Star      uint
-	Frequency uint
+// countDrawsByEra returns the number of stored draws in each of Eras
+func countDrawsByEra(ctx context.Context, db sqlops.DB) ([]int, error) {
+	dates, err := sqlops.Query(ctx, db, func(rows *sql.Rows) (time.Time, error) {
+		var dt string
+		if err := rows.Scan(&dt); err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(dateLayout, dt)
+	}, selectDrawDateSQL)
+	if err != nil {
+		return nil, err
+	}
+	return countByEra(dates), nil
 }
 
-func CalculateStarFreq(ctx context.Context, db *sql.DB) ([]StarFrequency, error) {
-	starFreqs := []StarFrequency{}
-	star := 0
-	for range 13 {
-		star = star + 1
-		starFreq := StarFrequency{
-			Star: uint(star),
-		}
-		result, err := sqlops.Query(ctx, db, func(r *sql.Rows) (any, error) {
-			var count int
-			if err := r.Scan(&count); err != nil {
-				return nil, fmt.Errorf("%w: %v", sqlops.ErrExecuteQuery, err)
-			}
-			return count, nil
-		}, countStarSQL, starFreq.Star)
+var (
+	countBallSQL = fmt.Sprintf(`SELECT COUNT(*) FROM %[1]s 
+	    WHERE %[2]s=$1 OR %[3]s=$1 OR %[4]s=$1 OR %[5]s=$1 OR %[6]s=$1;`,
+		tblName, ball1, ball2, ball3, ball4, ball5)
+
+	countStarSQL = fmt.Sprintf("SELECT COUNT(*) FROM %[1]s WHERE %[2]s=$1 OR %[3]s=$1;", tblName, star1, star2)
+)
+
+// countBalls returns the number of stored draws containing each ball from 1
+// to maxBall, counted by the query
+func countBalls(ctx context.Context, db sqlops.DB, query string, maxBall int) ([]uint, error) {
+	counts := make([]uint, maxBall)
+	for i := range counts {
+		count, err := sqlops.QueryOne(ctx, db, func(r *sql.Rows) (uint, error) {
+			var count uint
+			err := r.Scan(&count)
+			return count, err
+		}, query, i+1)
 		if err != nil {
 			return nil, err
 		}
-		starFreq.Frequency = uint(result[0].(int))
-		starFreqs = append(starFreqs, starFreq)
+		counts[i] = count
 	}
-	return starFreqs, nil
+	return counts, nil
 }
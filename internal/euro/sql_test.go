@@ -2,10 +2,13 @@ package euro_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/euro"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 )
@@ -44,7 +47,7 @@ func TestCalculateBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := euro.CalculateBallFreq(ctx, db)
+	freqs, err := euro.CalculateBallFreq(ctx, euro.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -113,7 +116,7 @@ func TestCalculateStarFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := euro.CalculateStarFreq(ctx, db)
+	freqs, err := euro.CalculateStarFreq(ctx, euro.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -142,6 +145,82 @@ func TestCalculateStarFreq(t *testing.T) {
 	}
 }
 
+func TestListDraws(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, euro.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := euro.LatestDraw(ctx, euro.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+
+	draws := []euro.Draw{
+		{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 24, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := euro.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name    string
+		input   drawops.Filter
+		wantNos []uint64
+	}{
+		{name: "all draws", input: drawops.Filter{}, wantNos: []uint64{1, 2, 3}},
+		{name: "descending", input: drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true}, wantNos: []uint64{3, 2, 1}},
+		{name: "last draws", input: drawops.Filter{Last: 2}, wantNos: []uint64{2, 3}},
+		{name: "date range", input: drawops.Filter{From: draws[1].DrawDate, To: draws[1].DrawDate}, wantNos: []uint64{2}},
+		{name: "from date", input: drawops.Filter{From: draws[1].DrawDate}, wantNos: []uint64{2, 3}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := euro.ListDraws(ctx, db, tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotNos := []uint64{}
+			for _, d := range got {
+				gotNos = append(gotNos, d.DrawNo)
+			}
+			if !slices.Equal(tc.wantNos, gotNos) {
+				t.Fatalf("Unmatch draws. Want: %v Got: %v", tc.wantNos, gotNos)
+			}
+		})
+	}
+
+	latest, err := euro.LatestDraw(ctx, euro.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.DrawNo != 3 {
+		t.Fatalf("expected latest draw 3, got %d", latest.DrawNo)
+	}
+
+	deleted, err := euro.DeleteAllDraws(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 draws deleted, got %d", deleted)
+	}
+	if _, err := euro.LatestDraw(ctx, euro.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+}
+
 func Example_insertListDraw() {
 
 	db, err := sqlops.NewSQLiteMem()
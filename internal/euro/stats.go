@@ -0,0 +1,140 @@
+package euro
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+type BallFrequency struct {
+	Ball      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateBallFreq returns the frequency of every main ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateBallFreq(ctx context.Context, store DrawStore) ([]BallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BallCount })
+
+	counts, err := store.CountBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ballFreqs := []BallFrequency{}
+	for i, count := range counts {
+		ballFreqs = append(ballFreqs, BallFrequency{
+			Ball:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return ballFreqs, nil
+}
+
+type StarFrequency struct {
+	Star      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateStarFreq returns the frequency of every lucky star across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateStarFreq(ctx context.Context, store DrawStore) ([]StarFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxStar(),
+		func(e Era) int { return e.MaxStar },
+		func(e Era) int { return e.StarCount })
+
+	counts, err := store.CountStars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	starFreqs := []StarFrequency{}
+	for i, count := range counts {
+		starFreqs = append(starFreqs, StarFrequency{
+			Star:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return starFreqs, nil
+}
+
+// CalculateBallGaps returns the gaps between appearances of every main ball
+// in the stored draws. A draw only counts towards the balls in its era's pool.
+func CalculateBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxBall(), mainBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxBall, era.BallCount
+	}), nil
+}
+
+// CalculateStarGaps returns the gaps between appearances of every lucky star
+// in the stored draws. A draw only counts towards the lucky stars in its era's pool.
+func CalculateStarGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxStar(), stars, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxStar, era.StarCount
+	}), nil
+}
+
+// CalculateBallTrend returns the frequency of every main ball in each period
+// of the stored draws
+func CalculateBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxBall(), period, drawDateOf, mainBalls)
+}
+
+// CalculateStarTrend returns the frequency of every lucky star in each period
+// of the stored draws
+func CalculateStarTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxStar(), period, drawDateOf, stars)
+}
+
+func mainBalls(d Draw) []uint8 {
+	return []uint8{d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5}
+}
+
+func stars(d Draw) []uint8 {
+	return []uint8{d.Star1, d.Star2}
+}
+
+func drawDateOf(d Draw) time.Time {
+	return d.DrawDate
+}
+
+func drawNoOf(d Draw) uint64 {
+	return d.DrawNo
+}
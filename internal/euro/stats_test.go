@@ -0,0 +1,78 @@
+package euro_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGapsAndTrend(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, euro.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	draws := []euro.Draw{
+		{DrawDate: time.Date(2026, time.January, 30, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 13, Ball2: 24, Ball3: 28, Ball4: 33, Ball5: 35, Star1: 5, Star2: 9, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := euro.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gaps, err := euro.CalculateBallGaps(ctx, euro.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, gaps, 50)
+	assert.Equal(t, drawops.Gap{Ball: 13, Current: 0, Longest: 1, Average: 1, Expected: gaps[0].Expected}, gaps[12])
+	assert.Equal(t, drawops.Gap{Ball: 1, Current: 1, Longest: 1, Average: 0, Expected: gaps[0].Expected}, gaps[0])
+
+	specialGaps, err := euro.CalculateStarGaps(ctx, euro.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, specialGaps, 12)
+	assert.Equal(t, uint(0), specialGaps[4].Longest)
+
+	trend, err := euro.CalculateBallTrend(ctx, euro.NewSQLiteStore(db), drawops.Month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trend, err = trend.Select(13, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026-01", Draws: 1, Frequency: []uint{1, 0}},
+		{Period: "2026-02", Draws: 2, Frequency: []uint{1, 1}},
+	}, trend.Periods)
+
+	specialTrend, err := euro.CalculateStarTrend(ctx, euro.NewSQLiteStore(db), drawops.Year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specialTrend, err = specialTrend.Select(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026", Draws: 3, Frequency: []uint{3}},
+	}, specialTrend.Periods)
+}
@@ -0,0 +1,121 @@
+package euro_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/euro"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawStore(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, euro.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name  string
+		store euro.DrawStore
+	}{
+		{name: "sqlite", store: euro.NewSQLiteStore(db)},
+		{name: "memory", store: euro.NewMemStore()},
+	}
+
+	d1 := euro.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Friday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Star1: 1, Star2: 2, DrawNo: 1}
+	d2 := euro.Draw{DrawDate: time.Date(2026, time.February, 24, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Tuesday, Ball1: 46, Ball2: 47, Ball3: 48, Ball4: 49, Ball5: 50, Star1: 11, Star2: 12, DrawNo: 2}
+	refused := []euro.Draw{
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Tuesday, Ball1: 1, Ball2: 1, Ball3: 3, Ball4: 4, Ball5: 5, Star1: 1, Star2: 2, DrawNo: 3},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Tuesday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Star1: 2, Star2: 2, DrawNo: 4},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Tuesday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 51, Star1: 1, Star2: 2, DrawNo: 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, d := range []euro.Draw{d1, d2} {
+				if err := tc.store.PersistDraw(ctx, d); err != nil {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+				}
+			}
+			if err := tc.store.PersistDraw(ctx, d1); !errors.Is(err, drawops.ErrStored) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrStored, err)
+			}
+			for _, d := range refused {
+				if err := tc.store.PersistDraw(ctx, d); !errors.Is(err, drawops.ErrRefused) {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+				}
+			}
+
+			draws, err := tc.store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true})
+			assert.NoError(t, err)
+			assert.Equal(t, []euro.Draw{d2, d1}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{From: d2.DrawDate})
+			assert.NoError(t, err)
+			assert.Equal(t, []euro.Draw{d2}, draws)
+			latest, err := euro.LatestDraw(ctx, tc.store)
+			assert.NoError(t, err)
+			assert.Equal(t, d2, latest)
+
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Contains: []uint8{d2.Ball1}})
+			assert.NoError(t, err)
+			assert.Equal(t, []euro.Draw{d2}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Offset: 1, Limit: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, []euro.Draw{d2}, draws)
+			got, err := tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, d1, got)
+			if _, err := tc.store.GetDraw(ctx, 99); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			eras, err := tc.store.CountDrawsByEra(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, eras[len(euro.Eras)-1])
+			balls, err := tc.store.CountBalls(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, balls, euro.MaxBall())
+			assert.Equal(t, []uint{1, 1}, []uint{balls[0], balls[49]})
+			starCounts, err := tc.store.CountStars(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, starCounts, euro.MaxStar())
+			assert.Equal(t, []uint{1, 0, 1}, []uint{starCounts[0], starCounts[2], starCounts[11]})
+
+			updated := d1
+			updated.Ball1 = 6
+			assert.NoError(t, tc.store.UpdateDraw(ctx, updated))
+			got, err = tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, updated, got)
+			updated.Ball2 = updated.Ball1
+			if err := tc.store.UpdateDraw(ctx, updated); !errors.Is(err, drawops.ErrRefused) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+			}
+			missing := d1
+			missing.DrawNo = 99
+			if err := tc.store.UpdateDraw(ctx, missing); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			assert.NoError(t, tc.store.DeleteDraw(ctx, d1.DrawNo))
+			if err := tc.store.DeleteDraw(ctx, d1.DrawNo); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+			deleted, err := tc.store.DeleteAllDraws(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), deleted)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{})
+			assert.NoError(t, err)
+			assert.Empty(t, draws)
+		})
+	}
+}
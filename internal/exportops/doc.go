@@ -0,0 +1,2 @@
+// Package exportops contains operations to export draws and statistics as CSV, JSON, NDJSON, Parquet or XLSX.
+package exportops
@@ -0,0 +1,224 @@
+package exportops
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+)
+
+// Write writes tables to w in the format. XLSX writes each table to a sheet
+// of one workbook, other formats write exactly one table.
+func Write(w io.Writer, format Format, tables ...Table) error {
+	if format == XLSX {
+		return writeXLSX(w, tables)
+	}
+	if len(tables) != 1 {
+		return fmt.Errorf("%w: %s writes one table, got %d", ErrExport, format, len(tables))
+	}
+	t := tables[0]
+	switch format {
+	case CSV:
+		return writeCSV(w, t)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(t.Rows); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+		return nil
+	case NDJSON:
+		return writeNDJSON(w, t)
+	case Parquet:
+		return writeParquet(w, t)
+	default:
+		return fmt.Errorf("%w: %s", ErrFormat, format)
+	}
+}
+
+// TablePath returns the path of a table exported alongside the file at
+// path, in formats writing one table per file. For example the table
+// ball_frequency alongside euro.csv is written to euro_ball_frequency.csv.
+func TablePath(path string, name string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + name + ext
+}
+
+// Records returns the header and records of the table in its CSV layout
+func Records(t Table) ([]string, [][]string, error) {
+	if r, ok := t.Rows.(Recorder); ok {
+		header, records := r.Records()
+		return header, records, nil
+	}
+
+	header, values, err := fields(t.Rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([][]string, len(values))
+	for i, row := range values {
+		records[i] = make([]string, len(row))
+		for j, v := range row {
+			switch v := v.(type) {
+			case float64:
+				records[i][j] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				records[i][j] = fmt.Sprint(v)
+			}
+		}
+	}
+	return header, records, nil
+}
+
+func writeCSV(w io.Writer, t Table) error {
+	header, records, err := Records(t)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("%w: %w", ErrExport, err)
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("%w: %w", ErrExport, err)
+	}
+	return nil
+}
+
+func writeNDJSON(w io.Writer, t Table) error {
+	rows, err := rowsOf(t.Rows)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for i := range rows.Len() {
+		if err := enc.Encode(rows.Index(i).Interface()); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+	}
+	return nil
+}
+
+func writeParquet(w io.Writer, t Table) error {
+	rows, err := rowsOf(t.Rows)
+	if err != nil {
+		return err
+	}
+	pw := parquet.NewWriter(w, parquet.SchemaOf(reflect.Zero(rows.Type().Elem()).Interface()))
+	for i := range rows.Len() {
+		if err := pw.Write(rows.Index(i).Interface()); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrExport, err)
+	}
+	return nil
+}
+
+func writeXLSX(w io.Writer, tables []Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	defaultSheet := f.GetSheetName(0)
+	for _, t := range tables {
+		header, values, err := fields(t.Rows)
+		if err != nil {
+			return err
+		}
+		if _, err := f.NewSheet(t.Name); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+		row := make([]any, len(header))
+		for i, h := range header {
+			row[i] = h
+		}
+		if err := f.SetSheetRow(t.Name, "A1", &row); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+		for i, v := range values {
+			if err := f.SetSheetRow(t.Name, fmt.Sprintf("A%d", i+2), &v); err != nil {
+				return fmt.Errorf("%w: %w", ErrExport, err)
+			}
+		}
+	}
+	if len(tables) > 0 {
+		if err := f.DeleteSheet(defaultSheet); err != nil {
+			return fmt.Errorf("%w: %w", ErrExport, err)
+		}
+	}
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("%w: %w", ErrExport, err)
+	}
+	return nil
+}
+
+// rowsOf returns rows as a slice of structs
+func rowsOf(rows any) (reflect.Value, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: %T", ErrRows, rows)
+	}
+	return v, nil
+}
+
+// fields returns the column names of rows from their json tags and the
+// value of each column. Dates are formatted YYYY-MM-DD and other values
+// are kept as numbers or strings.
+func fields(rows any) ([]string, [][]any, error) {
+	v, err := rowsOf(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elem := v.Type().Elem()
+	header := []string{}
+	index := []int{}
+	for i := range elem.NumField() {
+		f := elem.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		header = append(header, name)
+		index = append(index, i)
+	}
+
+	values := make([][]any, v.Len())
+	for i := range v.Len() {
+		values[i] = make([]any, len(index))
+		for j, k := range index {
+			values[i][j] = cell(v.Index(i).Field(k))
+		}
+	}
+	return header, values, nil
+}
+
+func cell(v reflect.Value) any {
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.DateOnly)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
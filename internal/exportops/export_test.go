@@ -0,0 +1,158 @@
+package exportops
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type testRow struct {
+	Date  time.Time    `json:"date" parquet:"date,timestamp(millisecond)"`
+	Day   time.Weekday `json:"day" parquet:"day"`
+	Ball  uint8        `json:"ball" parquet:"ball"`
+	Ratio float64      `json:"ratio" parquet:"ratio"`
+	Name  string       `json:"name" parquet:"name"`
+}
+
+var testRows = []testRow{
+	{Date: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Day: time.Friday, Ball: 13, Ratio: 1.5, Name: "a"},
+	{Date: time.Date(2026, time.February, 24, 0, 0, 0, 0, time.UTC), Day: time.Tuesday, Ball: 7, Ratio: 2, Name: "b"},
+}
+
+type testRecorder []testRow
+
+func (r testRecorder) Records() ([]string, [][]string) {
+	return []string{"Name"}, [][]string{{r[0].Name}, {r[1].Name}}
+}
+
+func TestWrite(t *testing.T) {
+	testcases := []struct {
+		name    string
+		format  Format
+		tables  []Table
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "csv",
+			format: CSV,
+			tables: []Table{{Name: "rows", Rows: testRows}},
+			want:   "date,day,ball,ratio,name\n2026-02-20,5,13,1.5,a\n2026-02-24,2,7,2,b\n",
+		},
+		{
+			name:   "csv of recorder",
+			format: CSV,
+			tables: []Table{{Name: "rows", Rows: testRecorder(testRows)}},
+			want:   "Name\na\nb\n",
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			tables: []Table{{Name: "rows", Rows: testRecorder(testRows)}},
+			want: `{"date":"2026-02-20T00:00:00Z","day":5,"ball":13,"ratio":1.5,"name":"a"}` + "\n" +
+				`{"date":"2026-02-24T00:00:00Z","day":2,"ball":7,"ratio":2,"name":"b"}` + "\n",
+		},
+		{
+			name:    "several tables",
+			format:  JSON,
+			tables:  []Table{{Name: "a", Rows: testRows}, {Name: "b", Rows: testRows}},
+			wantErr: ErrExport,
+		},
+		{
+			name:    "rows not structs",
+			format:  CSV,
+			tables:  []Table{{Name: "rows", Rows: []int{1}}},
+			wantErr: ErrRows,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			tables:  []Table{{Name: "rows", Rows: testRows}},
+			wantErr: ErrFormat,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tc.format, tc.tables...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Parquet, Table{Name: "rows", Rows: testRows}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parquet.Read[testRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testRows, got)
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, XLSX, Table{Name: "draws", Rows: testRecorder(testRows)}, Table{Name: "empty", Rows: []testRow{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.Equal(t, []string{"draws", "empty"}, f.GetSheetList())
+
+	rows, err := f.GetRows("draws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [][]string{
+		{"date", "day", "ball", "ratio", "name"},
+		{"2026-02-20", "5", "13", "1.5", "a"},
+		{"2026-02-24", "2", "7", "2", "b"},
+	}, rows)
+}
+
+func TestFormatOf(t *testing.T) {
+	testcases := []struct {
+		path    string
+		want    Format
+		wantErr error
+	}{
+		{path: "euro.csv", want: CSV},
+		{path: "out/euro.PARQUET", want: Parquet},
+		{path: "euro.ndjson", want: NDJSON},
+		{path: "euro", wantErr: ErrFormat},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := FormatOf(tc.path)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTablePath(t *testing.T) {
+	assert.Equal(t, "out/euro_ball_gaps.csv", TablePath("out/euro.csv", "ball_gaps"))
+	assert.Equal(t, "euro_ball_gaps", TablePath("euro", "ball_gaps"))
+}
@@ -0,0 +1,74 @@
+package exportops
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrFormat = errors.New("unsupported export format")
+	ErrRows   = errors.New("invalid export rows")
+	ErrExport = errors.New("unable to export")
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	CSV     Format = "csv"
+	JSON    Format = "json"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+	XLSX    Format = "xlsx"
+)
+
+// Formats lists the supported export formats
+var Formats = []Format{CSV, JSON, NDJSON, Parquet, XLSX}
+
+// ParseFormat converts the name of a format to Format
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s, expected one of %v", ErrFormat, name, Formats)
+}
+
+// FormatOf returns the format named by the extension of a file
+func FormatOf(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+}
+
+// Table is a named slice of rows. Rows are structs whose json tags name the
+// columns of CSV and XLSX, and whose parquet tags name the columns of Parquet.
+type Table struct {
+	Name string
+	Rows any
+}
+
+// Recorder is implemented by rows with a CSV layout of their own, such as
+// the layout of the National Lottery
+type Recorder interface {
+	// Records returns the header and records of the CSV layout
+	Records() (header []string, records [][]string)
+}
+
+// Frequency is the frequency of a ball in the export schema
+type Frequency struct {
+	Ball      uint    `json:"ball" parquet:"ball"`
+	Frequency uint    `json:"frequency" parquet:"frequency"`
+	Expected  float64 `json:"expected" parquet:"expected"`
+}
+
+// Len returns the number of rows of the table
+func (t Table) Len() int {
+	v := reflect.ValueOf(t.Rows)
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+	return v.Len()
+}
@@ -0,0 +1,2 @@
+// Package jobops runs import jobs in the background and reports their progress.
+package jobops
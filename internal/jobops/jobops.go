@@ -0,0 +1,56 @@
+package jobops
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoJob  = errors.New("no job found")
+	ErrClosed = errors.New("job manager closed")
+)
+
+// State is the stage of the life of a job
+type State string
+
+const (
+	Queued    State = "queued"
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+	Cancelled State = "cancelled"
+)
+
+// Done reports whether the job has finished in the state
+func (s State) Done() bool {
+	return s == Succeeded || s == Failed || s == Cancelled
+}
+
+// Progress counts the records of an import job handled so far
+type Progress struct {
+	Records    int      `json:"records"` // Records read
+	Done       int      `json:"done"`    // Records persisted, skipped, failed or rejected
+	Persisted  int      `json:"persisted"`
+	Skipped    int      `json:"skipped"` // Draws already stored
+	Failed     int      `json:"failed"`
+	Violations int      `json:"violations"`
+	Errors     []string `json:"errors,omitempty"` // Errors of the first failed records
+}
+
+// Status reports the state and progress of a job. Result is the value
+// returned by the job once it has finished.
+type Status struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	State State  `json:"state"`
+	Progress
+	Result  any       `json:"result,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Run is the work of a job. It reports its progress as it goes, and
+// returns its result or error when finished.
+type Run func(ctx context.Context, report func(Progress)) (any, error)
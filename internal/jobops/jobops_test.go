@@ -0,0 +1,268 @@
+package jobops
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errJob = errors.New("job error")
+
+func TestManagerWait(t *testing.T) {
+	testcases := []struct {
+		name       string
+		run        Run
+		wantState  State
+		wantResult any
+		wantErr    error
+	}{
+		{
+			name: "succeeded",
+			run: func(ctx context.Context, report func(Progress)) (any, error) {
+				report(Progress{Records: 2, Done: 2, Persisted: 2})
+				return "result", nil
+			},
+			wantState:  Succeeded,
+			wantResult: "result",
+		},
+		{
+			name: "failed with result",
+			run: func(ctx context.Context, report func(Progress)) (any, error) {
+				return "result", errJob
+			},
+			wantState:  Failed,
+			wantResult: "result",
+			wantErr:    errJob,
+		},
+		{
+			name: "cancelled",
+			run: func(ctx context.Context, report func(Progress)) (any, error) {
+				return nil, context.Canceled
+			},
+			wantState: Cancelled,
+			wantErr:   context.Canceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager(1)
+			defer m.Close()
+
+			started := m.Start("test", tc.run)
+			assert.Equal(t, "test", started.Kind)
+			assert.NotEmpty(t, started.ID)
+
+			got, err := m.Wait(context.Background(), started.ID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.wantState, got.State)
+			assert.Equal(t, tc.wantResult, got.Result)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr.Error(), got.Error)
+			}
+
+			status, err := m.Status(started.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, got, status)
+		})
+	}
+}
+
+func TestManagerNoJob(t *testing.T) {
+	m := NewManager(1)
+	defer m.Close()
+
+	_, err := m.Status("missing")
+	if !errors.Is(err, ErrNoJob) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrNoJob, err)
+	}
+	_, err = m.Watch(context.Background(), "missing")
+	if !errors.Is(err, ErrNoJob) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrNoJob, err)
+	}
+	_, err = m.Wait(context.Background(), "missing")
+	if !errors.Is(err, ErrNoJob) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrNoJob, err)
+	}
+}
+
+func TestManagerWatch(t *testing.T) {
+	m := NewManager(1)
+	defer m.Close()
+
+	proceed := make(chan struct{})
+	status := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		<-proceed
+		for i := 1; i <= 3; i++ {
+			report(Progress{Records: 3, Done: i})
+		}
+		return nil, nil
+	})
+
+	statuses, err := m.Watch(context.Background(), status.ID)
+	assert.NoError(t, err)
+	close(proceed)
+
+	var got []Status
+	for s := range statuses {
+		got = append(got, s)
+	}
+	assert.NotEmpty(t, got)
+	last := got[len(got)-1]
+	assert.Equal(t, Succeeded, last.State)
+	assert.Equal(t, 3, last.Done)
+	for i := 1; i < len(got); i++ {
+		assert.GreaterOrEqual(t, got[i].Done, got[i-1].Done)
+	}
+}
+
+func TestManagerQueue(t *testing.T) {
+	m := NewManager(1)
+	defer m.Close()
+
+	proceed := make(chan struct{})
+	first := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		<-proceed
+		return nil, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statuses, err := m.Watch(ctx, first.ID)
+	assert.NoError(t, err)
+	for s := range statuses {
+		if s.State == Running {
+			break
+		}
+	}
+	second := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		return nil, nil
+	})
+
+	status, err := m.Status(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, Queued, status.State)
+
+	close(proceed)
+	for _, id := range []string{first.ID, second.ID} {
+		status, err := m.Wait(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, Succeeded, status.State)
+	}
+}
+
+func TestManagerClose(t *testing.T) {
+	m := NewManager(1)
+	running := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	queued := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	m.Close()
+
+	for _, id := range []string{running.ID, queued.ID} {
+		status, err := m.Wait(context.Background(), id)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Unmatch error. Want: %v Got: %v", context.Canceled, err)
+		}
+		assert.Equal(t, Cancelled, status.State)
+	}
+
+	closed := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		return nil, nil
+	})
+	_, err := m.Wait(context.Background(), closed.ID)
+	if !errors.Is(err, ErrClosed) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrClosed, err)
+	}
+}
+
+func TestManagerRetention(t *testing.T) {
+	m := NewManager(1)
+	defer m.Close()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	done := m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		return nil, nil
+	})
+	_, err := m.Wait(context.Background(), done.ID)
+	assert.NoError(t, err)
+
+	now = now.Add(Retention + time.Second)
+	m.Start("test", func(ctx context.Context, report func(Progress)) (any, error) {
+		return nil, nil
+	})
+
+	_, err = m.Status(done.ID)
+	if !errors.Is(err, ErrNoJob) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", ErrNoJob, err)
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	testcases := []struct {
+		name      string
+		timeout   time.Duration
+		wantState State
+		wantErr   error
+	}{
+		{
+			name:      "drained",
+			timeout:   time.Minute,
+			wantState: Succeeded,
+		},
+		{
+			name:      "timed out",
+			timeout:   10 * time.Millisecond,
+			wantState: Cancelled,
+			wantErr:   context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager(1)
+			proceed := make(chan struct{})
+			defer close(proceed)
+			wait := func(ctx context.Context, report func(Progress)) (any, error) {
+				if tc.wantErr == nil {
+					return nil, nil
+				}
+				select {
+				case <-proceed:
+					return nil, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			running := m.Start("test", wait)
+			queued := m.Start("test", wait)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := m.Shutdown(ctx)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			for _, id := range []string{running.ID, queued.ID} {
+				status, _ := m.Status(id)
+				assert.Equal(t, tc.wantState, status.State)
+			}
+
+			late := m.Start("test", wait)
+			_, err = m.Wait(context.Background(), late.ID)
+			if !errors.Is(err, ErrClosed) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", ErrClosed, err)
+			}
+		})
+	}
+}
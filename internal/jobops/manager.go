@@ -0,0 +1,263 @@
+package jobops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers is the number of jobs a manager runs at once unless
+	// NewManager is given another
+	DefaultWorkers = 2
+	// Retention is how long a finished job is kept for its status to be
+	// read
+	Retention = time.Hour
+)
+
+// job is a job and the watchers of its status
+type job struct {
+	status   Status
+	err      error
+	done     chan struct{}
+	watchers map[chan struct{}]struct{}
+}
+
+// Manager runs jobs in the background, up to a number of jobs at once, and
+// keeps their status until Retention after they finish
+type Manager struct {
+	mu     sync.Mutex
+	jobs   map[string]*job
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	closed bool
+	now    func() time.Time
+}
+
+// NewManager returns a manager running up to workers jobs at once. Jobs
+// started while every worker is busy are queued.
+func NewManager(workers int) *Manager {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		jobs:   map[string]*job{},
+		slots:  make(chan struct{}, workers),
+		ctx:    ctx,
+		cancel: cancel,
+		now:    time.Now,
+	}
+}
+
+// Start queues a job of the kind running run, and returns its status. The
+// context of run is cancelled when the manager is closed.
+func (m *Manager) Start(kind string, run Run) Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+
+	now := m.now()
+	j := &job{
+		status:   Status{ID: newID(), Kind: kind, State: Queued, Created: now, Updated: now},
+		done:     make(chan struct{}),
+		watchers: map[chan struct{}]struct{}{},
+	}
+	if m.closed {
+		j.status.State, j.status.Error, j.err = Cancelled, ErrClosed.Error(), ErrClosed
+		close(j.done)
+		m.jobs[j.status.ID] = j
+		return j.status
+	}
+	m.jobs[j.status.ID] = j
+
+	m.wg.Add(1)
+	go m.run(j, run)
+	return j.status
+}
+
+// run runs the job once a worker is free
+func (m *Manager) run(j *job, run Run) {
+	defer m.wg.Done()
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-m.ctx.Done():
+	}
+	if err := m.ctx.Err(); err != nil {
+		m.finish(j, nil, err)
+		return
+	}
+
+	m.update(j, func(s *Status) { s.State = Running })
+	result, err := run(m.ctx, func(p Progress) {
+		m.update(j, func(s *Status) { s.Progress = p })
+	})
+	m.finish(j, result, err)
+}
+
+// update changes the status of the job and notifies its watchers
+func (m *Manager) update(j *job, change func(*Status)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	change(&j.status)
+	j.status.Updated = m.now()
+	for w := range j.watchers {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// finish records the result or error of the job
+func (m *Manager) finish(j *job, result any, err error) {
+	m.update(j, func(s *Status) {
+		s.Result = result
+		switch {
+		case err == nil:
+			s.State = Succeeded
+		case errors.Is(err, context.Canceled):
+			s.State, s.Error = Cancelled, err.Error()
+		default:
+			s.State, s.Error = Failed, err.Error()
+		}
+		j.err = err
+	})
+	close(j.done)
+}
+
+// prune removes the jobs finished more than Retention ago
+func (m *Manager) prune() {
+	for id, j := range m.jobs {
+		if j.status.State.Done() && m.now().Sub(j.status.Updated) > Retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Status returns the status of the job, or ErrNoJob if the manager has
+// no job with the ID
+func (m *Manager) Status(id string) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrNoJob, id)
+	}
+	return j.status, nil
+}
+
+// Watch returns a channel receiving the status of the job when watched and
+// whenever it changes, closed after the status of the finished job or when
+// the context is done. Changes made while a status is being received are
+// coalesced into the next status.
+func (m *Manager) Watch(ctx context.Context, id string) (<-chan Status, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoJob, id)
+	}
+	notify := make(chan struct{}, 1)
+	notify <- struct{}{}
+	j.watchers[notify] = struct{}{}
+	m.mu.Unlock()
+
+	statuses := make(chan Status)
+	go func() {
+		defer close(statuses)
+		defer func() {
+			m.mu.Lock()
+			delete(j.watchers, notify)
+			m.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+			m.mu.Lock()
+			status := j.status
+			m.mu.Unlock()
+			select {
+			case statuses <- status:
+			case <-ctx.Done():
+				return
+			}
+			if status.State.Done() {
+				return
+			}
+		}
+	}()
+	return statuses, nil
+}
+
+// Wait returns the status of the job once it has finished, with the error
+// the job failed with
+func (m *Manager) Wait(ctx context.Context, id string) (Status, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrNoJob, id)
+	}
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return Status{}, ctx.Err()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return j.status, j.err
+}
+
+// Close cancels the running and queued jobs and waits for them to finish.
+// Jobs started after Close are cancelled at once.
+func (m *Manager) Close() {
+	m.stop()
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Shutdown waits for the running and queued jobs to finish, cancelling
+// them if the context is done first, in which case it returns the error of
+// the context. Jobs started after Shutdown are cancelled at once.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.stop()
+	drained := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		m.cancel()
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+// stop refuses the jobs started from now on
+func (m *Manager) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+}
+
+// newID returns a random job ID
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
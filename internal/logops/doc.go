@@ -0,0 +1,2 @@
+// Package logops configures structured logging with log/slog and the attributes shared by its records.
+package logops
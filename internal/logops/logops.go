@@ -0,0 +1,140 @@
+package logops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var (
+	ErrLevel  = errors.New("invalid log level")
+	ErrFormat = errors.New("invalid log format")
+)
+
+// Format is the format of log records
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+)
+
+const (
+	// DefaultLevel and DefaultFormat configure logging unless set in
+	// ebz.yaml or by flags
+	DefaultLevel  = "info"
+	DefaultFormat = Text
+)
+
+// ParseLevel returns the level named debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("%w: %s, expected debug, info, warn or error", ErrLevel, name)
+	}
+	return level, nil
+}
+
+// ParseFormat returns the format named text or json
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case Text, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %s, expected text or json", ErrFormat, name)
+}
+
+// New returns a logger writing records of the level and above to w in the
+// format of its name. Records logged with a context carry its request ID.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: l}
+	var h slog.Handler = slog.NewTextHandler(w, opts)
+	if f == JSON {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// Err returns the attribute of an error, grouping its message and the
+// sentinel it wraps, so that records of errors alike can be matched
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Group("error", "msg", err.Error(), "sentinel", Sentinel(err))
+}
+
+// Sentinel returns the message of the error at the end of the chain of the
+// first errors the error wraps, which is the sentinel of errors wrapped
+// with fmt.Errorf("%w: ...")
+func Sentinel(err error) string {
+	for {
+		var next error
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			next = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			if errs := e.Unwrap(); len(errs) > 0 {
+				next = errs[0]
+			}
+		}
+		if next == nil {
+			return err.Error()
+		}
+		err = next
+	}
+}
+
+// requestIDKey is the context key of a request ID
+type requestIDKey struct{}
+
+// NewRequestID returns a random ID of 16 hex digits
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns the context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of the context, or "" if it has none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID of the context of a record to it
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
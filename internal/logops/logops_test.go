@@ -0,0 +1,86 @@
+package logops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errSentinel = errors.New("invalid ball 5")
+
+func TestNew(t *testing.T) {
+	testcases := []struct {
+		name    string
+		level   string
+		format  string
+		wantErr error
+	}{
+		{name: "text", level: "info", format: "text"},
+		{name: "json", level: "DEBUG", format: "JSON"},
+		{name: "invalid level", level: "loud", format: "text", wantErr: ErrLevel},
+		{name: "invalid format", level: "info", format: "xml", wantErr: ErrFormat},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tc.level, tc.format)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, "warn", "json")
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	ctx := WithRequestID(context.Background(), "0123456789abcdef")
+
+	logger.InfoContext(ctx, "below level")
+	logger.WarnContext(ctx, "skipping record", "game", "tball", "line", 3, Err(fmt.Errorf("%w: 99", errSentinel)))
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "skipping record", got["msg"])
+	assert.Equal(t, "tball", got["game"])
+	assert.Equal(t, 3.0, got["line"])
+	assert.Equal(t, "0123456789abcdef", got["request_id"])
+	assert.Equal(t, map[string]any{"msg": "invalid ball 5: 99", "sentinel": "invalid ball 5"}, got["error"])
+}
+
+func TestSentinel(t *testing.T) {
+	testcases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "sentinel", err: errSentinel, want: "invalid ball 5"},
+		{name: "wrapped", err: fmt.Errorf("%w: 99", errSentinel), want: "invalid ball 5"},
+		{name: "wrapped twice", err: fmt.Errorf("unable to check draws: %w", fmt.Errorf("%w: 99", errSentinel)), want: "invalid ball 5"},
+		{name: "wrapping two", err: fmt.Errorf("%w:%w", errSentinel, errors.New("database locked")), want: "invalid ball 5"},
+		{name: "joined", err: errors.Join(errSentinel, errors.New("other")), want: "invalid ball 5"},
+		{name: "not wrapped", err: fmt.Errorf("failed: %v", errSentinel), want: "failed: invalid ball 5"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Sentinel(tc.err))
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	id := NewRequestID()
+	assert.Len(t, id, 16)
+	assert.NotEqual(t, id, NewRequestID())
+	assert.Equal(t, id, RequestID(WithRequestID(context.Background(), id)))
+	assert.Empty(t, RequestID(context.Background()))
+}
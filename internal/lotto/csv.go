@@ -3,6 +3,7 @@ package lotto
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/paulwizviz/lotterystat/internal/csvops"
@@ -31,7 +32,7 @@ func ProcessCSV(recs chan csvops.CSVRec, numWorkers int) []DrawChan {
 
 func csvWorker(jobs chan csvops.CSVRec, results chan DrawChan) {
 	for j := range jobs {
-		drawChan := DrawChan{}
+		drawChan := DrawChan{Line: j.Line}
 		if errors.Is(j.Err, csvops.ErrLine) {
 			drawChan.Draw = Draw{}
 			drawChan.Err = ErrRec
@@ -51,7 +52,6 @@ func csvWorker(jobs chan csvops.CSVRec, results chan DrawChan) {
 }
 
 func processRecord(rec []string) (Draw, error) {
-	maxValue := 59
 	draw := Draw{}
 
 	dt, err := csvops.ParseDate(rec[0])
@@ -61,6 +61,12 @@ func processRecord(rec []string) (Draw, error) {
 	draw.DrawDate = dt
 	draw.DayOfWeek = dt.Weekday()
 
+	era, err := EraAt(dt)
+	if err != nil {
+		return draw, fmt.Errorf("%w-%v", ErrDrawDate, err)
+	}
+	maxValue := era.MaxBall
+
 	ball1, err := csvops.ParseDrawNum(rec[1], maxValue)
 	if err != nil {
 		return draw, fmt.Errorf("%w-%v", ErrBall1, err)
@@ -113,3 +119,47 @@ func processRecord(rec []string) (Draw, error) {
 	draw.DrawNo = seq
 	return draw, nil
 }
+
+// CSVHeader is the header of the National Lottery CSV layout
+var CSVHeader = []string{"DrawDate", "Ball 1", "Ball 2", "Ball 3", "Ball 4", "Ball 5", "Ball 6", "Bonus Ball", "Ball Set", "Machine", "DrawNumber"}
+
+// FormatRecord converts a draw to a record of the National Lottery CSV
+// layout, which ProcessCSV converts back to the draw
+func FormatRecord(d Draw) []string {
+	return []string{d.DrawDate.Format(csvops.DateLayout), fmt.Sprint(d.Ball1), fmt.Sprint(d.Ball2), fmt.Sprint(d.Ball3), fmt.Sprint(d.Ball4), fmt.Sprint(d.Ball5), fmt.Sprint(d.Ball6),
+		fmt.Sprint(d.BonusBall), d.BallSet, d.Machine, fmt.Sprint(d.DrawNo)}
+}
+
+// RecordOf converts the fields of a draw, named by the JSON tags of Draw, to
+// a record of the National Lottery CSV layout. It is the csvops.RecordFunc
+// of JSON, NDJSON and XLSX sources.
+func RecordOf(fields map[string]string) []string {
+	rec := []string{csvops.CSVDate(fields["draw_date"])}
+	for _, name := range []string{"ball1", "ball2", "ball3", "ball4", "ball5", "ball6", "bonus_ball", "ball_set", "machine"} {
+		rec = append(rec, fields[name])
+	}
+	return append(rec, fields["draw_no"])
+}
+
+// MatchHeader reports whether the header of a National Lottery CSV file is
+// of Lotto draws
+func MatchHeader(header []string) bool {
+	return slices.Contains(header, "Bonus Ball")
+}
+
+// Match reports whether a record is a draw of Lotto: the header, if the
+// record has one, is a Lotto header and the values of the record are
+// within the ranges of the Lotto era of its draw date
+func Match(rec csvops.CSVRec) bool {
+	if rec.Err != nil {
+		return false
+	}
+	if rec.Header != nil && !MatchHeader(rec.Header) {
+		return false
+	}
+	if len(rec.Record) < len(CSVHeader) {
+		return false
+	}
+	_, err := processRecord(rec.Record)
+	return err == nil
+}
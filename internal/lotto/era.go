@@ -0,0 +1,36 @@
+package lotto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+// EraAt returns the rules in effect on the draw date
+func EraAt(date time.Time) (Era, error) {
+	i := drawops.EraIndex(Eras, eraFrom, date)
+	if i < 0 {
+		return Era{}, fmt.Errorf("%w: %s", ErrNoEra, date.Format(time.DateOnly))
+	}
+	return Eras[i], nil
+}
+
+// MaxBall returns the largest main ball pool across all eras
+func MaxBall() int {
+	return drawops.MaxPool(Eras, func(e Era) int { return e.MaxBall })
+}
+
+// expectedFreq returns the expected number of times each ball from 1 to
+// maxBall is drawn, given the number of draws stored in each era.
+// pool and count select the pool size and number drawn of an era.
+func expectedFreq(drawsPerEra []int, maxBall int, pool, count func(Era) int) []float64 {
+	return drawops.ExpectedFreq(Eras, drawsPerEra, maxBall, pool, count)
+}
+
+// countByEra returns the number of draw dates in each of Eras
+func countByEra(dates []time.Time) []int {
+	return drawops.CountByEra(Eras, eraFrom, dates)
+}
+
+func eraFrom(e Era) time.Time { return e.From }
@@ -0,0 +1,93 @@
+package lotto
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEraAt(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   time.Time
+		want    Era
+		wantErr error
+	}{
+		{
+			name:    "launch",
+			input:   time.Date(1994, time.November, 19, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "last saturday only draw",
+			input:   time.Date(1997, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "first wednesday draw",
+			input:   time.Date(1997, time.February, 5, 0, 0, 0, 0, time.UTC),
+			want:    Eras[1],
+			wantErr: nil,
+		},
+		{
+			name:    "49 balls",
+			input:   time.Date(2015, time.October, 7, 0, 0, 0, 0, time.UTC),
+			want:    Eras[1],
+			wantErr: nil,
+		},
+		{
+			name:    "59 balls",
+			input:   time.Date(2015, time.October, 10, 0, 0, 0, 0, time.UTC),
+			want:    Eras[2],
+			wantErr: nil,
+		},
+		{
+			name:    "before launch",
+			input:   time.Date(1994, time.November, 12, 0, 0, 0, 0, time.UTC),
+			want:    Era{},
+			wantErr: ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := EraAt(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExpectedFreq(t *testing.T) {
+	// One draw in each era
+	drawsPerEra := make([]int, len(Eras))
+	for i := range drawsPerEra {
+		drawsPerEra[i] = 1
+	}
+	got := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BallCount })
+
+	if len(got) != MaxBall() {
+		t.Fatalf("expected %d frequencies, got %d", MaxBall(), len(got))
+	}
+
+	// A main ball is only expected in the eras whose pool contains it
+	for b, want := range got {
+		sum := 0.0
+		for _, e := range Eras {
+			if b < e.MaxBall {
+				sum += float64(e.BallCount) / float64(e.MaxBall)
+			}
+		}
+		assert.InDelta(t, sum, want, 1e-9, fmt.Sprintf("main ball %d", b+1))
+	}
+	assert.Greater(t, got[0], got[MaxBall()-1])
+}
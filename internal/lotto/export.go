@@ -0,0 +1,65 @@
+package lotto
+
+import (
+	"context"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/exportops"
+)
+
+// nationalDraws are draws exported as CSV in the National Lottery layout
+type nationalDraws []Draw
+
+func (n nationalDraws) Records() ([]string, [][]string) {
+	records := make([][]string, len(n))
+	for i, d := range n {
+		records[i] = FormatRecord(d)
+	}
+	return CSVHeader, records
+}
+
+// ExportTables returns the stored draws ordered by draw number and, if stats
+// is set, the frequencies and gaps of every ball and bonus ball
+func ExportTables(ctx context.Context, store DrawStore, stats bool) ([]exportops.Table, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	tables := []exportops.Table{{Name: "draws", Rows: nationalDraws(draws)}}
+	if !stats {
+		return tables, nil
+	}
+
+	ballFreqs, err := CalculateBallFreq(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	rows := []exportops.Frequency{}
+	for _, f := range ballFreqs {
+		rows = append(rows, exportops.Frequency{Ball: f.Ball, Frequency: f.Frequency, Expected: f.Expected})
+	}
+	tables = append(tables, exportops.Table{Name: "ball_frequency", Rows: rows})
+
+	bonusFreqs, err := CalculateBonusFreq(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	rows = []exportops.Frequency{}
+	for _, f := range bonusFreqs {
+		rows = append(rows, exportops.Frequency{Ball: f.Ball, Frequency: f.Frequency, Expected: f.Expected})
+	}
+	tables = append(tables, exportops.Table{Name: "bonus_frequency", Rows: rows})
+
+	ballGaps, err := CalculateBallGaps(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	bonusGaps, err := CalculateBonusGaps(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	tables = append(tables,
+		exportops.Table{Name: "ball_gaps", Rows: ballGaps},
+		exportops.Table{Name: "bonus_gaps", Rows: bonusGaps})
+	return tables, nil
+}
@@ -0,0 +1,156 @@
+package lotto
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+// CheckDraw verifies the draw has distinct main balls, a bonus ball not
+// among them, all within the pool of its era, and falls on a draw day of its
+// era
+func CheckDraw(d Draw) error {
+	era, err := EraAt(d.DrawDate)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	balls := []uint8{d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Ball6}
+	sorted := slices.Clone(balls)
+	slices.Sort(sorted)
+	if len(slices.Compact(sorted)) != len(balls) {
+		errs = append(errs, fmt.Errorf("%w: %d,%d,%d,%d,%d,%d", ErrDuplicateBall, d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Ball6))
+	}
+	if slices.Contains(balls, d.BonusBall) {
+		errs = append(errs, fmt.Errorf("%w: %d", ErrDuplicateBonus, d.BonusBall))
+	}
+	if err := checkRange(append(balls, d.BonusBall), era.MaxBall, ErrBallRange); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(era.DrawDays, d.DrawDate.Weekday()) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrDrawDay, d.DrawDate.Weekday()))
+	}
+	return errors.Join(errs...)
+}
+
+// checkRange returns err with the balls outside 1 to max, if any
+func checkRange(balls []uint8, max int, err error) error {
+	var out []string
+	for _, b := range balls {
+		if b < 1 || int(b) > max {
+			out = append(out, fmt.Sprint(b))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s, max %d", err, strings.Join(out, ","), max)
+}
+
+// Verify checks every draw with CheckDraw and across draws, that each draw
+// number identifies one draw and that draw numbers follow draw dates
+func Verify(draws []Draw) []Violation {
+	violations := []Violation{}
+	seen := map[uint64]Draw{}
+	for _, d := range draws {
+		if prev, ok := seen[d.DrawNo]; ok {
+			if !sameDraw(prev, d) {
+				violations = append(violations, Violation{
+					DrawNo:  d.DrawNo,
+					Related: prev.DrawNo,
+					Err:     fmt.Errorf("%w: %d", ErrDrawConflict, d.DrawNo),
+				})
+			}
+			continue
+		}
+		seen[d.DrawNo] = d
+		if err := CheckDraw(d); err != nil {
+			violations = append(violations, Violation{DrawNo: d.DrawNo, Err: err})
+		}
+	}
+
+	ordered := slices.SortedFunc(maps.Values(seen), func(a, b Draw) int {
+		return cmp.Compare(a.DrawNo, b.DrawNo)
+	})
+	for i := 1; i < len(ordered); i++ {
+		prev, d := ordered[i-1], ordered[i]
+		if !d.DrawDate.After(prev.DrawDate) {
+			violations = append(violations, Violation{
+				DrawNo:  d.DrawNo,
+				Related: prev.DrawNo,
+				Err: fmt.Errorf("%w: draw %d on %s, draw %d on %s", ErrDrawOrder,
+					prev.DrawNo, prev.DrawDate.Format(time.DateOnly), d.DrawNo, d.DrawDate.Format(time.DateOnly)),
+			})
+		}
+	}
+	return violations
+}
+
+// CheckImport verifies draws to be imported against each other and the
+// stored draws, ignoring violations among stored draws only. When reject is
+// true, draws involved in a violation are removed from the returned draws.
+func CheckImport(ctx context.Context, store DrawStore, draws []Draw, reject bool) ([]Draw, []Violation, error) {
+	stored, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	incoming := map[uint64]bool{}
+	for _, d := range draws {
+		incoming[d.DrawNo] = true
+	}
+
+	violations := []Violation{}
+	for _, v := range Verify(append(stored, draws...)) {
+		if incoming[v.DrawNo] || incoming[v.Related] {
+			violations = append(violations, v)
+		}
+	}
+	if !reject {
+		return draws, violations, nil
+	}
+
+	rejected := map[uint64]bool{}
+	for _, v := range violations {
+		rejected[v.DrawNo] = true
+		rejected[v.Related] = true
+	}
+	accepted := []Draw{}
+	for _, d := range draws {
+		if !rejected[d.DrawNo] {
+			accepted = append(accepted, d)
+		}
+	}
+	return accepted, violations, nil
+}
+
+// MarshalJSON encodes the violation with the message of its error
+func (v Violation) MarshalJSON() ([]byte, error) {
+	msg := ""
+	if v.Err != nil {
+		msg = v.Err.Error()
+	}
+	return json.Marshal(struct {
+		DrawNo  uint64 `json:"draw_no"`
+		Related uint64 `json:"related,omitempty"`
+		Err     string `json:"error"`
+	}{
+		DrawNo:  v.DrawNo,
+		Related: v.Related,
+		Err:     msg,
+	})
+}
+
+func sameDraw(a, b Draw) bool {
+	a.DrawDate, b.DrawDate = a.DrawDate.UTC(), b.DrawDate.UTC()
+	return a == b
+}
@@ -0,0 +1,141 @@
+package lotto_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDraw(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   lotto.Draw
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			input:   lotto.Draw{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146},
+			wantErr: nil,
+		},
+		{
+			name:    "duplicate main ball",
+			input:   lotto.Draw{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 11, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146},
+			wantErr: lotto.ErrDuplicateBall,
+		},
+		{
+			name:    "bonus repeats main ball",
+			input:   lotto.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 49, DrawNo: 3147},
+			wantErr: lotto.ErrDuplicateBonus,
+		},
+		{
+			name:    "no draw on day",
+			input:   lotto.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146},
+			wantErr: lotto.ErrDrawDay,
+		},
+		{
+			name:    "main ball outside era",
+			input:   lotto.Draw{DrawDate: time.Date(1995, time.January, 7, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 59, BonusBall: 33, DrawNo: 8},
+			wantErr: lotto.ErrBallRange,
+		},
+		{
+			name:    "bonus ball outside era",
+			input:   lotto.Draw{DrawDate: time.Date(1995, time.January, 7, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 50, DrawNo: 8},
+			wantErr: lotto.ErrBallRange,
+		},
+		{
+			name:    "wednesday before wednesday draws",
+			input:   lotto.Draw{DrawDate: time.Date(1997, time.January, 29, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 138},
+			wantErr: lotto.ErrDrawDay,
+		},
+		{
+			name:    "first wednesday draw",
+			input:   lotto.Draw{DrawDate: time.Date(1997, time.February, 5, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 139},
+			wantErr: nil,
+		},
+		{
+			name:    "before first draw",
+			input:   lotto.Draw{DrawDate: time.Date(1990, time.January, 6, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146},
+			wantErr: lotto.ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := lotto.CheckDraw(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d1 := lotto.Draw{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146}
+	d2 := lotto.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 7, DrawNo: 3147}
+
+	t.Run("consistent draws", func(t *testing.T) {
+		assert.Empty(t, lotto.Verify([]lotto.Draw{d2, d1, d1}))
+	})
+
+	t.Run("repeated draw number", func(t *testing.T) {
+		conflict := d2
+		conflict.DrawNo = d1.DrawNo
+		conflict.DrawDate = d1.DrawDate
+		got := lotto.Verify([]lotto.Draw{d1, conflict})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, lotto.ErrDrawConflict)
+	})
+
+	t.Run("draw number out of order", func(t *testing.T) {
+		late := d1
+		late.DrawNo = d2.DrawNo + 1
+		got := lotto.Verify([]lotto.Draw{d1, d2, late})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, lotto.ErrDrawOrder)
+		assert.Equal(t, late.DrawNo, got[0].DrawNo)
+		assert.Equal(t, d2.DrawNo, got[0].Related)
+	})
+}
+
+func TestCheckImport(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, lotto.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	d1 := lotto.Draw{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3146}
+	if err := lotto.PersistsDraw(ctx, db, d1); err != nil {
+		t.Fatal(err)
+	}
+
+	d2 := lotto.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 7, DrawNo: 3147}
+	conflict := d2
+	conflict.DrawNo = d1.DrawNo
+	conflict.DrawDate = d1.DrawDate
+
+	t.Run("warn", func(t *testing.T) {
+		draws, violations, err := lotto.CheckImport(ctx, lotto.NewSQLiteStore(db), []lotto.Draw{d2, conflict}, false)
+		assert.NoError(t, err)
+		assert.Len(t, draws, 2)
+		assert.Len(t, violations, 1)
+		assert.ErrorIs(t, violations[0].Err, lotto.ErrDrawConflict)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		draws, violations, err := lotto.CheckImport(ctx, lotto.NewSQLiteStore(db), []lotto.Draw{d2, conflict}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []lotto.Draw{d2}, draws)
+		assert.Len(t, violations, 1)
+	})
+}
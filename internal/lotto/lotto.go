@@ -2,9 +2,11 @@ package lotto
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"regexp"
 	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/logops"
 )
 
 const (
@@ -22,34 +24,69 @@ var (
 	ErrBonus    = errors.New("invalid bonus ball")
 	ErrSeq      = errors.New("invalid seq")
 	ErrRec      = errors.New("invalid record")
+	ErrNoEra    = errors.New("no game rules for draw date")
 )
 
+var (
+	// Integrity
+	ErrDuplicateBall  = errors.New("duplicate main ball")
+	ErrBallRange      = errors.New("main ball out of range of era")
+	ErrDuplicateBonus = errors.New("bonus ball repeats a main ball")
+	ErrDrawDay        = errors.New("no draw on day of week")
+	ErrDrawOrder      = errors.New("draw number out of order with draw date")
+	ErrDrawConflict   = errors.New("draw number repeated with different contents")
+)
+
+// Era represents the Lotto rules effective from a draw date
+type Era struct {
+	From       time.Time      // First draw date the rules apply to
+	BallCount  int            // Number of main balls drawn
+	MaxBall    int            // Size of the main ball pool
+	BonusCount int            // Number of bonus balls drawn from the main ball pool
+	DrawDays   []time.Weekday // Days of the week the game is drawn
+}
+
+// Eras lists the Lotto rules in chronological order
+var Eras = []Era{
+	{From: time.Date(1994, time.November, 19, 0, 0, 0, 0, time.UTC), BallCount: 6, MaxBall: 49, BonusCount: 1, DrawDays: []time.Weekday{time.Saturday}},
+	{From: time.Date(1997, time.February, 5, 0, 0, 0, 0, time.UTC), BallCount: 6, MaxBall: 49, BonusCount: 1, DrawDays: []time.Weekday{time.Wednesday, time.Saturday}},
+	{From: time.Date(2015, time.October, 10, 0, 0, 0, 0, time.UTC), BallCount: 6, MaxBall: 59, BonusCount: 1, DrawDays: []time.Weekday{time.Wednesday, time.Saturday}},
+}
+
 // Draw represents a line from lotto draw results
 type Draw struct {
-	DrawDate  time.Time    `json:"draw_date"`
-	DayOfWeek time.Weekday `json:"day_of_week"`
-	Ball1     uint8        `json:"ball1"`
-	Ball2     uint8        `json:"ball2"`
-	Ball3     uint8        `json:"ball3"`
-	Ball4     uint8        `json:"ball4"`
-	Ball5     uint8        `json:"ball5"`
-	Ball6     uint8        `json:"ball6"`
-	BonusBall uint8        `json:"bonus_ball"`
-	BallSet   string       `json:"ball_set"`
-	Machine   string       `json:"machine"`
-	DrawNo    uint64       `json:"draw_no"`
+	DrawDate  time.Time    `json:"draw_date" parquet:"draw_date,timestamp(millisecond)"`
+	DayOfWeek time.Weekday `json:"day_of_week" parquet:"day_of_week"`
+	Ball1     uint8        `json:"ball1" parquet:"ball1"`
+	Ball2     uint8        `json:"ball2" parquet:"ball2"`
+	Ball3     uint8        `json:"ball3" parquet:"ball3"`
+	Ball4     uint8        `json:"ball4" parquet:"ball4"`
+	Ball5     uint8        `json:"ball5" parquet:"ball5"`
+	Ball6     uint8        `json:"ball6" parquet:"ball6"`
+	BonusBall uint8        `json:"bonus_ball" parquet:"bonus_ball"`
+	BallSet   string       `json:"ball_set" parquet:"ball_set"`
+	Machine   string       `json:"machine" parquet:"machine"`
+	DrawNo    uint64       `json:"draw_no" parquet:"draw_no"`
 }
 
 type DrawChan struct {
 	Draw Draw
+	Line uint // Line of the record in its file, 0 if unknown
 	Err  error
 }
 
+// Violation represents a draw failing an integrity check
+type Violation struct {
+	DrawNo  uint64 // Draw failing the check
+	Related uint64 // Other draw involved in a check across draws, otherwise 0
+	Err     error
+}
+
 func IsValidBall(arg string) bool {
 	pattern := `^\b([1-9]|[1-4][0-9]|5[0-9])\b(,\b([1-9]|[1-4][0-9]|5[0-9])\b)*$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 		return matched
 	}
 	return matched
@@ -59,7 +96,7 @@ func IsValidBonus(arg string) bool {
 	pattern := `^\b([1-9]|[1-4][0-9]|5[0-9])\b$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 	}
 	return matched
 }
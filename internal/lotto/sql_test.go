@@ -2,10 +2,13 @@ package lotto_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/lotto"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 )
@@ -44,7 +47,7 @@ func TestCalculateBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := lotto.CalculateBallFreq(ctx, db)
+	freqs, err := lotto.CalculateBallFreq(ctx, lotto.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -98,12 +101,12 @@ func TestCalculateBonusFreq(t *testing.T) {
 	draws := []lotto.Draw{
 		{
 			DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC),
-			Ball1:    1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 1,
+			Ball1:    2, Ball2: 3, Ball3: 4, Ball4: 5, Ball5: 6, Ball6: 7, BonusBall: 1,
 			DrawNo: 1,
 		},
 		{
 			DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC),
-			Ball1:    1, Ball2: 10, Ball3: 20, Ball4: 30, Ball5: 40, Ball6: 59, BonusBall: 59,
+			Ball1:    1, Ball2: 10, Ball3: 20, Ball4: 30, Ball5: 40, Ball6: 58, BonusBall: 59,
 			DrawNo: 2,
 		},
 	}
@@ -115,7 +118,7 @@ func TestCalculateBonusFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := lotto.CalculateBonusFreq(ctx, db)
+	freqs, err := lotto.CalculateBonusFreq(ctx, lotto.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -143,6 +146,82 @@ func TestCalculateBonusFreq(t *testing.T) {
 	}
 }
 
+func TestListDraws(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, lotto.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lotto.LatestDraw(ctx, lotto.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+
+	draws := []lotto.Draw{
+		{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 21, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := lotto.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name    string
+		input   drawops.Filter
+		wantNos []uint64
+	}{
+		{name: "all draws", input: drawops.Filter{}, wantNos: []uint64{1, 2, 3}},
+		{name: "descending", input: drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true}, wantNos: []uint64{3, 2, 1}},
+		{name: "last draws", input: drawops.Filter{Last: 2}, wantNos: []uint64{2, 3}},
+		{name: "date range", input: drawops.Filter{From: draws[1].DrawDate, To: draws[1].DrawDate}, wantNos: []uint64{2}},
+		{name: "from date", input: drawops.Filter{From: draws[1].DrawDate}, wantNos: []uint64{2, 3}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := lotto.ListDraws(ctx, db, tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotNos := []uint64{}
+			for _, d := range got {
+				gotNos = append(gotNos, d.DrawNo)
+			}
+			if !slices.Equal(tc.wantNos, gotNos) {
+				t.Fatalf("Unmatch draws. Want: %v Got: %v", tc.wantNos, gotNos)
+			}
+		})
+	}
+
+	latest, err := lotto.LatestDraw(ctx, lotto.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.DrawNo != 3 {
+		t.Fatalf("expected latest draw 3, got %d", latest.DrawNo)
+	}
+
+	deleted, err := lotto.DeleteAllDraws(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 draws deleted, got %d", deleted)
+	}
+	if _, err := lotto.LatestDraw(ctx, lotto.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+}
+
 func Example_insertListDraw() {
 
 	db, err := sqlops.NewSQLiteMem()
@@ -0,0 +1,140 @@
+package lotto
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+type BallFrequency struct {
+	Ball      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateBallFreq returns the frequency of every main ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateBallFreq(ctx context.Context, store DrawStore) ([]BallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BallCount })
+
+	counts, err := store.CountBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ballFreqs := []BallFrequency{}
+	for i, count := range counts {
+		ballFreqs = append(ballFreqs, BallFrequency{
+			Ball:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return ballFreqs, nil
+}
+
+type BonusFrequency struct {
+	Ball      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateBonusFreq returns the frequency of every bonus ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateBonusFreq(ctx context.Context, store DrawStore) ([]BonusFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BonusCount })
+
+	counts, err := store.CountBonusBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freqs := []BonusFrequency{}
+	for i, count := range counts {
+		freqs = append(freqs, BonusFrequency{
+			Ball:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return freqs, nil
+}
+
+// CalculateBallGaps returns the gaps between appearances of every main ball
+// in the stored draws. A draw only counts towards the balls in its era's pool.
+func CalculateBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxBall(), mainBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxBall, era.BallCount
+	}), nil
+}
+
+// CalculateBonusGaps returns the gaps between appearances of every bonus ball
+// in the stored draws. A draw only counts towards the bonus balls in its era's pool.
+func CalculateBonusGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxBall(), bonusBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxBall, era.BonusCount
+	}), nil
+}
+
+// CalculateBallTrend returns the frequency of every main ball in each period
+// of the stored draws
+func CalculateBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxBall(), period, drawDateOf, mainBalls)
+}
+
+// CalculateBonusTrend returns the frequency of every bonus ball in each period
+// of the stored draws
+func CalculateBonusTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxBall(), period, drawDateOf, bonusBalls)
+}
+
+func mainBalls(d Draw) []uint8 {
+	return []uint8{d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5, d.Ball6}
+}
+
+func bonusBalls(d Draw) []uint8 {
+	return []uint8{d.BonusBall}
+}
+
+func drawDateOf(d Draw) time.Time {
+	return d.DrawDate
+}
+
+func drawNoOf(d Draw) uint64 {
+	return d.DrawNo
+}
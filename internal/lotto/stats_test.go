@@ -0,0 +1,78 @@
+package lotto_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGapsAndTrend(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, lotto.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	draws := []lotto.Draw{
+		{DrawDate: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 14, 0, 0, 0, 0, time.UTC), Ball1: 2, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 11, Ball3: 12, Ball4: 13, Ball5: 18, Ball6: 49, BonusBall: 33, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := lotto.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gaps, err := lotto.CalculateBallGaps(ctx, lotto.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, gaps, 59)
+	assert.Equal(t, drawops.Gap{Ball: 1, Current: 0, Longest: 1, Average: 1, Expected: gaps[0].Expected}, gaps[0])
+	assert.Equal(t, drawops.Gap{Ball: 2, Current: 1, Longest: 1, Average: 0, Expected: gaps[0].Expected}, gaps[1])
+
+	specialGaps, err := lotto.CalculateBonusGaps(ctx, lotto.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, specialGaps, 59)
+	assert.Equal(t, uint(0), specialGaps[32].Longest)
+
+	trend, err := lotto.CalculateBallTrend(ctx, lotto.NewSQLiteStore(db), drawops.Month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trend, err = trend.Select(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026-01", Draws: 1, Frequency: []uint{1, 0}},
+		{Period: "2026-02", Draws: 2, Frequency: []uint{1, 1}},
+	}, trend.Periods)
+
+	specialTrend, err := lotto.CalculateBonusTrend(ctx, lotto.NewSQLiteStore(db), drawops.Year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specialTrend, err = specialTrend.Select(33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026", Draws: 3, Frequency: []uint{3}},
+	}, specialTrend.Periods)
+}
@@ -0,0 +1,121 @@
+package lotto_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/lotto"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawStore(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, lotto.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name  string
+		store lotto.DrawStore
+	}{
+		{name: "sqlite", store: lotto.NewSQLiteStore(db)},
+		{name: "memory", store: lotto.NewMemStore()},
+	}
+
+	d1 := lotto.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Wednesday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 7, DrawNo: 1}
+	d2 := lotto.Draw{DrawDate: time.Date(2026, time.February, 21, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Saturday, Ball1: 53, Ball2: 54, Ball3: 55, Ball4: 56, Ball5: 57, Ball6: 58, BonusBall: 59, DrawNo: 2}
+	refused := []lotto.Draw{
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Saturday, Ball1: 1, Ball2: 1, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 7, DrawNo: 3},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Saturday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 6, BonusBall: 6, DrawNo: 4},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Saturday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, Ball6: 60, BonusBall: 7, DrawNo: 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, d := range []lotto.Draw{d1, d2} {
+				if err := tc.store.PersistDraw(ctx, d); err != nil {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+				}
+			}
+			if err := tc.store.PersistDraw(ctx, d1); !errors.Is(err, drawops.ErrStored) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrStored, err)
+			}
+			for _, d := range refused {
+				if err := tc.store.PersistDraw(ctx, d); !errors.Is(err, drawops.ErrRefused) {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+				}
+			}
+
+			draws, err := tc.store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true})
+			assert.NoError(t, err)
+			assert.Equal(t, []lotto.Draw{d2, d1}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{From: d2.DrawDate})
+			assert.NoError(t, err)
+			assert.Equal(t, []lotto.Draw{d2}, draws)
+			latest, err := lotto.LatestDraw(ctx, tc.store)
+			assert.NoError(t, err)
+			assert.Equal(t, d2, latest)
+
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Contains: []uint8{d2.Ball1}})
+			assert.NoError(t, err)
+			assert.Equal(t, []lotto.Draw{d2}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Offset: 1, Limit: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, []lotto.Draw{d2}, draws)
+			got, err := tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, d1, got)
+			if _, err := tc.store.GetDraw(ctx, 99); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			eras, err := tc.store.CountDrawsByEra(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, eras[len(lotto.Eras)-1])
+			balls, err := tc.store.CountBalls(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, balls, lotto.MaxBall())
+			assert.Equal(t, []uint{1, 1}, []uint{balls[0], balls[57]})
+			bonusCounts, err := tc.store.CountBonusBalls(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, bonusCounts, lotto.MaxBall())
+			assert.Equal(t, []uint{0, 1, 1}, []uint{bonusCounts[0], bonusCounts[6], bonusCounts[58]})
+
+			updated := d1
+			updated.Ball1 = 8
+			assert.NoError(t, tc.store.UpdateDraw(ctx, updated))
+			got, err = tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, updated, got)
+			updated.Ball2 = updated.Ball1
+			if err := tc.store.UpdateDraw(ctx, updated); !errors.Is(err, drawops.ErrRefused) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+			}
+			missing := d1
+			missing.DrawNo = 99
+			if err := tc.store.UpdateDraw(ctx, missing); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			assert.NoError(t, tc.store.DeleteDraw(ctx, d1.DrawNo))
+			if err := tc.store.DeleteDraw(ctx, d1.DrawNo); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+			deleted, err := tc.store.DeleteAllDraws(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), deleted)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{})
+			assert.NoError(t, err)
+			assert.Empty(t, draws)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package metricops records counters, histograms and gauges and writes them in the Prometheus text format.
+package metricops
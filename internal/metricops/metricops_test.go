@@ -0,0 +1,77 @@
+package metricops
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.Counter("requests_total", "Requests by route.", "method", "route")
+	latency := reg.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	reg.GaugeFunc("stored", "Stored draws.", []string{"game"}, func() []Sample {
+		return []Sample{
+			{Labels: []string{"tball"}, Value: 2},
+			{Labels: []string{"euro"}, Value: 1.5},
+		}
+	})
+	reg.Counter("empty_total", "Nothing counted.")
+
+	requests.Inc("GET", "/draws")
+	requests.Add(2, "GET", "/draws")
+	requests.Inc("POST", `/say "hi"\`)
+	latency.Observe(0.05, "/draws")
+	latency.Observe(0.5, "/draws")
+	latency.Observe(5, "/draws")
+
+	want := `# HELP requests_total Requests by route.
+# TYPE requests_total counter
+requests_total{method="GET",route="/draws"} 3
+requests_total{method="POST",route="/say \"hi\"\\"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/draws",le="0.1"} 1
+latency_seconds_bucket{route="/draws",le="1"} 2
+latency_seconds_bucket{route="/draws",le="+Inf"} 3
+latency_seconds_sum{route="/draws"} 5.55
+latency_seconds_count{route="/draws"} 3
+# HELP stored Stored draws.
+# TYPE stored gauge
+stored{game="euro"} 1.5
+stored{game="tball"} 2
+# HELP empty_total Nothing counted.
+# TYPE empty_total counter
+`
+	got := &strings.Builder{}
+	if err := reg.WriteText(got); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Equal(t, want, got.String())
+	assert.Equal(t, 3.0, requests.Value("GET", "/draws"))
+	assert.Equal(t, uint64(3), latency.Count("/draws"))
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("up_total", "Up.").Inc()
+
+	rr := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	body, _ := io.ReadAll(rr.Body)
+	assert.Contains(t, string(body), "up_total 1\n")
+}
+
+func TestRegistryPanics(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("requests_total", "Requests.", "route")
+
+	assert.Panics(t, func() { reg.Counter("requests_total", "Again.") })
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { c.Inc("/draws", "extra") })
+}
@@ -0,0 +1,47 @@
+package sflife
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEraAt(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   time.Time
+		want    Era
+		wantErr error
+	}{
+		{
+			name:    "launch",
+			input:   time.Date(2019, time.March, 18, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "current",
+			input:   time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC),
+			want:    Eras[0],
+			wantErr: nil,
+		},
+		{
+			name:    "before launch",
+			input:   time.Date(2019, time.March, 11, 0, 0, 0, 0, time.UTC),
+			want:    Era{},
+			wantErr: ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := EraAt(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
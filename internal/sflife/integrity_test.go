@@ -0,0 +1,126 @@
+package sflife_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDraw(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   sflife.Draw
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			input:   sflife.Draw{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 723},
+			wantErr: nil,
+		},
+		{
+			name:    "duplicate main ball",
+			input:   sflife.Draw{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 45, Ball5: 45, LBall: 8, DrawNo: 723},
+			wantErr: sflife.ErrDuplicateBall,
+		},
+		{
+			name:    "no draw on day",
+			input:   sflife.Draw{DrawDate: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 723},
+			wantErr: sflife.ErrDrawDay,
+		},
+		{
+			name:    "main ball outside era",
+			input:   sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 48, LBall: 8, DrawNo: 724},
+			wantErr: sflife.ErrBallRange,
+		},
+		{
+			name:    "life ball outside era",
+			input:   sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 11, DrawNo: 724},
+			wantErr: sflife.ErrLBallRange,
+		},
+		{
+			name:    "before first draw",
+			input:   sflife.Draw{DrawDate: time.Date(1990, time.January, 6, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 723},
+			wantErr: sflife.ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := sflife.CheckDraw(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d1 := sflife.Draw{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 723}
+	d2 := sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, LBall: 1, DrawNo: 724}
+
+	t.Run("consistent draws", func(t *testing.T) {
+		assert.Empty(t, sflife.Verify([]sflife.Draw{d2, d1, d1}))
+	})
+
+	t.Run("repeated draw number", func(t *testing.T) {
+		conflict := d2
+		conflict.DrawNo = d1.DrawNo
+		conflict.DrawDate = d1.DrawDate
+		got := sflife.Verify([]sflife.Draw{d1, conflict})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, sflife.ErrDrawConflict)
+	})
+
+	t.Run("draw number out of order", func(t *testing.T) {
+		late := d1
+		late.DrawNo = d2.DrawNo + 1
+		got := sflife.Verify([]sflife.Draw{d1, d2, late})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, sflife.ErrDrawOrder)
+		assert.Equal(t, late.DrawNo, got[0].DrawNo)
+		assert.Equal(t, d2.DrawNo, got[0].Related)
+	})
+}
+
+func TestCheckImport(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, sflife.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	d1 := sflife.Draw{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 723}
+	if err := sflife.PersistsDraw(ctx, db, d1); err != nil {
+		t.Fatal(err)
+	}
+
+	d2 := sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, LBall: 1, DrawNo: 724}
+	conflict := d2
+	conflict.DrawNo = d1.DrawNo
+	conflict.DrawDate = d1.DrawDate
+
+	t.Run("warn", func(t *testing.T) {
+		draws, violations, err := sflife.CheckImport(ctx, sflife.NewSQLiteStore(db), []sflife.Draw{d2, conflict}, false)
+		assert.NoError(t, err)
+		assert.Len(t, draws, 2)
+		assert.Len(t, violations, 1)
+		assert.ErrorIs(t, violations[0].Err, sflife.ErrDrawConflict)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		draws, violations, err := sflife.CheckImport(ctx, sflife.NewSQLiteStore(db), []sflife.Draw{d2, conflict}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []sflife.Draw{d2}, draws)
+		assert.Len(t, violations, 1)
+	})
+}
@@ -2,9 +2,11 @@ package sflife
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"regexp"
 	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/logops"
 )
 
 const (
@@ -21,33 +23,67 @@ var (
 	ErrLBall    = errors.New("invalid life ball")
 	ErrSeq      = errors.New("invalid seq")
 	ErrRec      = errors.New("invalid record")
+	ErrNoEra    = errors.New("no game rules for draw date")
 )
 
+var (
+	// Integrity
+	ErrDuplicateBall = errors.New("duplicate main ball")
+	ErrBallRange     = errors.New("main ball out of range of era")
+	ErrLBallRange    = errors.New("life ball out of range of era")
+	ErrDrawDay       = errors.New("no draw on day of week")
+	ErrDrawOrder     = errors.New("draw number out of order with draw date")
+	ErrDrawConflict  = errors.New("draw number repeated with different contents")
+)
+
+// Era represents the Set For Life rules effective from a draw date
+type Era struct {
+	From       time.Time      // First draw date the rules apply to
+	BallCount  int            // Number of main balls drawn
+	MaxBall    int            // Size of the main ball pool
+	LBallCount int            // Number of life balls drawn
+	MaxLBall   int            // Size of the life ball pool
+	DrawDays   []time.Weekday // Days of the week the game is drawn
+}
+
+// Eras lists the Set For Life rules in chronological order
+var Eras = []Era{
+	{From: time.Date(2019, time.March, 18, 0, 0, 0, 0, time.UTC), BallCount: 5, MaxBall: 47, LBallCount: 1, MaxLBall: 10, DrawDays: []time.Weekday{time.Monday, time.Thursday}},
+}
+
 // Draw represents a line from set for life draw results
 type Draw struct {
-	DrawDate  time.Time    `json:"draw_date"`
-	DayOfWeek time.Weekday `json:"day_of_week"`
-	Ball1     uint8        `json:"ball1"`
-	Ball2     uint8        `json:"ball2"`
-	Ball3     uint8        `json:"ball3"`
-	Ball4     uint8        `json:"ball4"`
-	Ball5     uint8        `json:"ball5"`
-	LBall     uint8        `json:"lball"`
-	BallSet   string       `json:"ball_set"`
-	Machine   string       `json:"machine"`
-	DrawNo    uint64       `json:"draw_no"`
+	DrawDate  time.Time    `json:"draw_date" parquet:"draw_date,timestamp(millisecond)"`
+	DayOfWeek time.Weekday `json:"day_of_week" parquet:"day_of_week"`
+	Ball1     uint8        `json:"ball1" parquet:"ball1"`
+	Ball2     uint8        `json:"ball2" parquet:"ball2"`
+	Ball3     uint8        `json:"ball3" parquet:"ball3"`
+	Ball4     uint8        `json:"ball4" parquet:"ball4"`
+	Ball5     uint8        `json:"ball5" parquet:"ball5"`
+	LBall     uint8        `json:"lball" parquet:"lball"`
+	BallSet   string       `json:"ball_set" parquet:"ball_set"`
+	Machine   string       `json:"machine" parquet:"machine"`
+	DrawNo    uint64       `json:"draw_no" parquet:"draw_no"`
 }
 
 type DrawChan struct {
 	Draw Draw
+	Line uint // Line of the record in its file, 0 if unknown
 	Err  error
 }
 
+// Violation represents a draw failing an integrity check
+type Violation struct {
+	DrawNo  uint64 // Draw failing the check
+	Related uint64 // Other draw involved in a check across draws, otherwise 0
+	Err     error
+}
+
 func IsValidBall(arg string) bool {
 	pattern := `^\b([1-9]|[1-3][0-9]|4[0-7])\b(,\b([1-9]|[1-3][0-9]|4[0-7])\b)*$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 		return matched
 	}
 	return matched
@@ -57,7 +93,7 @@ func IsValidLifeBall(arg string) bool {
 	pattern := `^\b([1-9]|10)\b$`
 	matched, err := regexp.MatchString(pattern, arg)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to match pattern", logops.Err(err))
 	}
 	return matched
 }
@@ -2,10 +2,13 @@ package sflife_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/sflife"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 )
@@ -44,7 +47,7 @@ func TestCalculateBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := sflife.CalculateBallFreq(ctx, db)
+	freqs, err := sflife.CalculateBallFreq(ctx, sflife.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -113,7 +116,7 @@ func TestCalculateLBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := sflife.CalculateLBallFreq(ctx, db)
+	freqs, err := sflife.CalculateLBallFreq(ctx, sflife.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -141,6 +144,82 @@ func TestCalculateLBallFreq(t *testing.T) {
 	}
 }
 
+func TestListDraws(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, sflife.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sflife.LatestDraw(ctx, sflife.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+
+	draws := []sflife.Draw{
+		{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 23, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := sflife.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name    string
+		input   drawops.Filter
+		wantNos []uint64
+	}{
+		{name: "all draws", input: drawops.Filter{}, wantNos: []uint64{1, 2, 3}},
+		{name: "descending", input: drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true}, wantNos: []uint64{3, 2, 1}},
+		{name: "last draws", input: drawops.Filter{Last: 2}, wantNos: []uint64{2, 3}},
+		{name: "date range", input: drawops.Filter{From: draws[1].DrawDate, To: draws[1].DrawDate}, wantNos: []uint64{2}},
+		{name: "from date", input: drawops.Filter{From: draws[1].DrawDate}, wantNos: []uint64{2, 3}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sflife.ListDraws(ctx, db, tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotNos := []uint64{}
+			for _, d := range got {
+				gotNos = append(gotNos, d.DrawNo)
+			}
+			if !slices.Equal(tc.wantNos, gotNos) {
+				t.Fatalf("Unmatch draws. Want: %v Got: %v", tc.wantNos, gotNos)
+			}
+		})
+	}
+
+	latest, err := sflife.LatestDraw(ctx, sflife.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.DrawNo != 3 {
+		t.Fatalf("expected latest draw 3, got %d", latest.DrawNo)
+	}
+
+	deleted, err := sflife.DeleteAllDraws(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 draws deleted, got %d", deleted)
+	}
+	if _, err := sflife.LatestDraw(ctx, sflife.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+}
+
 func Example_insertListDraw() {
 
 	db, err := sqlops.NewSQLiteMem()
@@ -0,0 +1,140 @@
+package sflife
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+type BallFrequency struct {
+	Ball      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateBallFreq returns the frequency of every main ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateBallFreq(ctx context.Context, store DrawStore) ([]BallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BallCount })
+
+	counts, err := store.CountBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ballFreqs := []BallFrequency{}
+	for i, count := range counts {
+		ballFreqs = append(ballFreqs, BallFrequency{
+			Ball:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return ballFreqs, nil
+}
+
+type LBallFrequency struct {
+	LBall     uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateLBallFreq returns the frequency of every life ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateLBallFreq(ctx context.Context, store DrawStore) ([]LBallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxLBall(),
+		func(e Era) int { return e.MaxLBall },
+		func(e Era) int { return e.LBallCount })
+
+	counts, err := store.CountLBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freqs := []LBallFrequency{}
+	for i, count := range counts {
+		freqs = append(freqs, LBallFrequency{
+			LBall:     uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return freqs, nil
+}
+
+// CalculateBallGaps returns the gaps between appearances of every main ball
+// in the stored draws. A draw only counts towards the balls in its era's pool.
+func CalculateBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxBall(), mainBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxBall, era.BallCount
+	}), nil
+}
+
+// CalculateLBallGaps returns the gaps between appearances of every life ball
+// in the stored draws. A draw only counts towards the life balls in its era's pool.
+func CalculateLBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxLBall(), lBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxLBall, era.LBallCount
+	}), nil
+}
+
+// CalculateBallTrend returns the frequency of every main ball in each period
+// of the stored draws
+func CalculateBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxBall(), period, drawDateOf, mainBalls)
+}
+
+// CalculateLBallTrend returns the frequency of every life ball in each period
+// of the stored draws
+func CalculateLBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxLBall(), period, drawDateOf, lBalls)
+}
+
+func mainBalls(d Draw) []uint8 {
+	return []uint8{d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5}
+}
+
+func lBalls(d Draw) []uint8 {
+	return []uint8{d.LBall}
+}
+
+func drawDateOf(d Draw) time.Time {
+	return d.DrawDate
+}
+
+func drawNoOf(d Draw) uint64 {
+	return d.DrawNo
+}
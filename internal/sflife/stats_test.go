@@ -0,0 +1,78 @@
+package sflife_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGapsAndTrend(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, sflife.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	draws := []sflife.Draw{
+		{DrawDate: time.Date(2026, time.January, 29, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 5, Ball2: 9, Ball3: 13, Ball4: 34, Ball5: 45, LBall: 8, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := sflife.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gaps, err := sflife.CalculateBallGaps(ctx, sflife.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, gaps, 47)
+	assert.Equal(t, drawops.Gap{Ball: 5, Current: 0, Longest: 1, Average: 1, Expected: gaps[0].Expected}, gaps[4])
+	assert.Equal(t, drawops.Gap{Ball: 1, Current: 1, Longest: 1, Average: 0, Expected: gaps[0].Expected}, gaps[0])
+
+	specialGaps, err := sflife.CalculateLBallGaps(ctx, sflife.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, specialGaps, 10)
+	assert.Equal(t, uint(0), specialGaps[7].Longest)
+
+	trend, err := sflife.CalculateBallTrend(ctx, sflife.NewSQLiteStore(db), drawops.Month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trend, err = trend.Select(5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026-01", Draws: 1, Frequency: []uint{1, 0}},
+		{Period: "2026-02", Draws: 2, Frequency: []uint{1, 1}},
+	}, trend.Periods)
+
+	specialTrend, err := sflife.CalculateLBallTrend(ctx, sflife.NewSQLiteStore(db), drawops.Year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specialTrend, err = specialTrend.Select(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026", Draws: 3, Frequency: []uint{3}},
+	}, specialTrend.Periods)
+}
@@ -0,0 +1,212 @@
+package sflife
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+)
+
+// DrawStore stores Set For Life draws and counts the appearances of their
+// balls. SQLiteStore stores draws in a database and MemStore in memory.
+type DrawStore interface {
+	// PersistDraw stores the draw, or returns drawops.ErrStored if a draw
+	// with its draw number is stored
+	PersistDraw(ctx context.Context, d Draw) error
+	// ListDraws returns the stored draws selected and ordered by the filter
+	ListDraws(ctx context.Context, filter drawops.Filter) ([]Draw, error)
+	// GetDraw returns the stored draw with the draw number, or
+	// drawops.ErrNoDraw if none is stored
+	GetDraw(ctx context.Context, drawNo uint64) (Draw, error)
+	// UpdateDraw replaces the stored draw with the draw number of d, or
+	// returns drawops.ErrNoDraw if none is stored
+	UpdateDraw(ctx context.Context, d Draw) error
+	// DeleteDraw removes the stored draw with the draw number, or returns
+	// drawops.ErrNoDraw if none is stored
+	DeleteDraw(ctx context.Context, drawNo uint64) error
+	// DeleteAllDraws removes every stored draw and returns the number of
+	// draws removed
+	DeleteAllDraws(ctx context.Context) (int64, error)
+	// CountDrawsByEra returns the number of stored draws in each of Eras
+	CountDrawsByEra(ctx context.Context) ([]int, error)
+	// CountBalls returns the number of stored draws containing each main
+	// ball from 1 to MaxBall
+	CountBalls(ctx context.Context) ([]uint, error)
+	// CountLBalls returns the number of stored draws containing each life ball
+	// from 1 to MaxLBall
+	CountLBalls(ctx context.Context) ([]uint, error)
+}
+
+// SQLiteStore stores draws in the table of Schema in a SQLite database
+type SQLiteStore struct {
+	db sqlops.DB
+}
+
+// NewSQLiteStore returns a store of the draws in the database. With a
+// *sqlops.StmtCache, statements are prepared once and writes are queued.
+func NewSQLiteStore(db sqlops.DB) SQLiteStore {
+	return SQLiteStore{db: db}
+}
+
+func (s SQLiteStore) PersistDraw(ctx context.Context, d Draw) error {
+	return PersistsDraw(ctx, s.db, d)
+}
+
+func (s SQLiteStore) ListDraws(ctx context.Context, filter drawops.Filter) ([]Draw, error) {
+	return ListDraws(ctx, s.db, filter)
+}
+
+func (s SQLiteStore) GetDraw(ctx context.Context, drawNo uint64) (Draw, error) {
+	return GetDraw(ctx, s.db, drawNo)
+}
+
+func (s SQLiteStore) UpdateDraw(ctx context.Context, d Draw) error {
+	return UpdateDraw(ctx, s.db, d)
+}
+
+func (s SQLiteStore) DeleteDraw(ctx context.Context, drawNo uint64) error {
+	return DeleteDraw(ctx, s.db, drawNo)
+}
+
+func (s SQLiteStore) DeleteAllDraws(ctx context.Context) (int64, error) {
+	return DeleteAllDraws(ctx, s.db)
+}
+
+func (s SQLiteStore) CountDrawsByEra(ctx context.Context) ([]int, error) {
+	return countDrawsByEra(ctx, s.db)
+}
+
+func (s SQLiteStore) CountBalls(ctx context.Context) ([]uint, error) {
+	return countBalls(ctx, s.db, countBallSQL, MaxBall())
+}
+
+func (s SQLiteStore) CountLBalls(ctx context.Context) ([]uint, error) {
+	return countBalls(ctx, s.db, countLBallSQL, MaxLBall())
+}
+
+// MemStore stores draws in memory. Like the table of SQLiteStore, it refuses
+// draws with balls outside the largest pools of Eras or repeated balls.
+type MemStore struct {
+	mu    sync.RWMutex
+	draws map[uint64]Draw
+}
+
+// NewMemStore returns an empty store of draws in memory
+func NewMemStore() *MemStore {
+	return &MemStore{draws: map[uint64]Draw{}}
+}
+
+func (m *MemStore) PersistDraw(ctx context.Context, d Draw) error {
+	if err := checkBalls(d); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.draws[d.DrawNo]; ok {
+		return fmt.Errorf("%w: %d", drawops.ErrStored, d.DrawNo)
+	}
+	m.draws[d.DrawNo] = d
+	return nil
+}
+
+func (m *MemStore) ListDraws(ctx context.Context, filter drawops.Filter) ([]Draw, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return drawops.SelectDraws(m.all(), filter, drawDateOf, drawNoOf, mainBalls), nil
+}
+
+func (m *MemStore) GetDraw(ctx context.Context, drawNo uint64) (Draw, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.draws[drawNo]
+	if !ok {
+		return Draw{}, fmt.Errorf("%w: %d", drawops.ErrNoDraw, drawNo)
+	}
+	return d, nil
+}
+
+func (m *MemStore) UpdateDraw(ctx context.Context, d Draw) error {
+	if err := checkBalls(d); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.draws[d.DrawNo]; !ok {
+		return fmt.Errorf("%w: %d", drawops.ErrNoDraw, d.DrawNo)
+	}
+	m.draws[d.DrawNo] = d
+	return nil
+}
+
+func (m *MemStore) DeleteDraw(ctx context.Context, drawNo uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.draws[drawNo]; !ok {
+		return fmt.Errorf("%w: %d", drawops.ErrNoDraw, drawNo)
+	}
+	delete(m.draws, drawNo)
+	return nil
+}
+
+func (m *MemStore) DeleteAllDraws(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.draws)
+	clear(m.draws)
+	return int64(n), nil
+}
+
+func (m *MemStore) CountDrawsByEra(ctx context.Context) ([]int, error) {
+	dates := []time.Time{}
+	for _, d := range m.all() {
+		dates = append(dates, d.DrawDate)
+	}
+	return countByEra(dates), nil
+}
+
+func (m *MemStore) CountBalls(ctx context.Context) ([]uint, error) {
+	return drawops.CountBalls(m.all(), MaxBall(), mainBalls), nil
+}
+
+func (m *MemStore) CountLBalls(ctx context.Context) ([]uint, error) {
+	return drawops.CountBalls(m.all(), MaxLBall(), lBalls), nil
+}
+
+// checkBalls refuses the draw if its balls break the constraints of the
+// table of SQLiteStore
+func checkBalls(d Draw) error {
+	if err := drawops.CheckBalls(mainBalls(d), MaxBall()); err != nil {
+		return fmt.Errorf("%w: draw %d", err, d.DrawNo)
+	}
+	if err := drawops.CheckBalls(lBalls(d), MaxLBall()); err != nil {
+		return fmt.Errorf("%w: draw %d", err, d.DrawNo)
+	}
+	return nil
+}
+
+// all returns every stored draw
+func (m *MemStore) all() []Draw {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Collect(maps.Values(m.draws))
+}
+
+// LatestDraw returns the stored draw with the highest draw number
+func LatestDraw(ctx context.Context, store DrawStore) (Draw, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Last: 1})
+	if err != nil {
+		return Draw{}, err
+	}
+	if len(draws) == 0 {
+		return Draw{}, drawops.ErrNoDraw
+	}
+	return draws[0], nil
+}
@@ -0,0 +1,121 @@
+package sflife_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/sflife"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawStore(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, sflife.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name  string
+		store sflife.DrawStore
+	}{
+		{name: "sqlite", store: sflife.NewSQLiteStore(db)},
+		{name: "memory", store: sflife.NewMemStore()},
+	}
+
+	d1 := sflife.Draw{DrawDate: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Monday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, LBall: 1, DrawNo: 1}
+	d2 := sflife.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), DayOfWeek: time.Thursday, Ball1: 43, Ball2: 44, Ball3: 45, Ball4: 46, Ball5: 47, LBall: 10, DrawNo: 2}
+	refused := []sflife.Draw{
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Thursday, Ball1: 1, Ball2: 1, Ball3: 3, Ball4: 4, Ball5: 5, LBall: 1, DrawNo: 3},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Thursday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 5, LBall: 11, DrawNo: 4},
+		{DrawDate: d2.DrawDate, DayOfWeek: time.Thursday, Ball1: 1, Ball2: 2, Ball3: 3, Ball4: 4, Ball5: 48, LBall: 1, DrawNo: 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, d := range []sflife.Draw{d1, d2} {
+				if err := tc.store.PersistDraw(ctx, d); err != nil {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+				}
+			}
+			if err := tc.store.PersistDraw(ctx, d1); !errors.Is(err, drawops.ErrStored) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrStored, err)
+			}
+			for _, d := range refused {
+				if err := tc.store.PersistDraw(ctx, d); !errors.Is(err, drawops.ErrRefused) {
+					t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+				}
+			}
+
+			draws, err := tc.store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true})
+			assert.NoError(t, err)
+			assert.Equal(t, []sflife.Draw{d2, d1}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{From: d2.DrawDate})
+			assert.NoError(t, err)
+			assert.Equal(t, []sflife.Draw{d2}, draws)
+			latest, err := sflife.LatestDraw(ctx, tc.store)
+			assert.NoError(t, err)
+			assert.Equal(t, d2, latest)
+
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Contains: []uint8{d2.Ball1}})
+			assert.NoError(t, err)
+			assert.Equal(t, []sflife.Draw{d2}, draws)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{Offset: 1, Limit: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, []sflife.Draw{d2}, draws)
+			got, err := tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, d1, got)
+			if _, err := tc.store.GetDraw(ctx, 99); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			eras, err := tc.store.CountDrawsByEra(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, eras[len(sflife.Eras)-1])
+			balls, err := tc.store.CountBalls(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, balls, sflife.MaxBall())
+			assert.Equal(t, []uint{1, 1}, []uint{balls[0], balls[46]})
+			specialCounts, err := tc.store.CountLBalls(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, specialCounts, sflife.MaxLBall())
+			assert.Equal(t, []uint{1, 0, 1}, []uint{specialCounts[0], specialCounts[2], specialCounts[9]})
+
+			updated := d1
+			updated.Ball1 = 6
+			assert.NoError(t, tc.store.UpdateDraw(ctx, updated))
+			got, err = tc.store.GetDraw(ctx, d1.DrawNo)
+			assert.NoError(t, err)
+			assert.Equal(t, updated, got)
+			updated.Ball2 = updated.Ball1
+			if err := tc.store.UpdateDraw(ctx, updated); !errors.Is(err, drawops.ErrRefused) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrRefused, err)
+			}
+			missing := d1
+			missing.DrawNo = 99
+			if err := tc.store.UpdateDraw(ctx, missing); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+
+			assert.NoError(t, tc.store.DeleteDraw(ctx, d1.DrawNo))
+			if err := tc.store.DeleteDraw(ctx, d1.DrawNo); !errors.Is(err, drawops.ErrNoDraw) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", drawops.ErrNoDraw, err)
+			}
+			deleted, err := tc.store.DeleteAllDraws(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), deleted)
+			draws, err = tc.store.ListDraws(ctx, drawops.Filter{})
+			assert.NoError(t, err)
+			assert.Empty(t, draws)
+		})
+	}
+}
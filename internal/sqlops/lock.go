@@ -0,0 +1,166 @@
+package sqlops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// staleLockAge is the age after which a lock file is taken to be left by
+	// a process that exited without releasing it
+	staleLockAge = 10 * time.Minute
+	lockPoll     = 100 * time.Millisecond
+
+	// holdSuffix follows the name of the database file in the names of the
+	// files holding it, one per holder
+	holdSuffix = ".hold-"
+)
+
+// LockFile creates the lock file, holding the process id, and returns a
+// function removing it. While the file is held by another process, it waits
+// until ctx is done and then returns ErrLocked. Lock files older than ten
+// minutes are replaced.
+func LockFile(ctx context.Context, file string) (func() error, error) {
+	for {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(file)
+				return nil, fmt.Errorf("%w: %w", ErrLocked, err)
+			}
+			return func() error { return os.Remove(file) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("%w: %w", ErrLocked, err)
+		}
+
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(file)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s held by process %s", ErrLocked, file, lockHolder(file))
+		case <-time.After(lockPoll):
+		}
+	}
+}
+
+// HoldDatabase holds the database file against restores, which refuse while
+// any process holds it. Processes serving or writing the database hold it at
+// the same time. While the database is restored, it waits until ctx is done
+// and then returns ErrLocked. The hold is refreshed while it is held, so it
+// is not taken as stale however long it is held. The returned function
+// releases the hold.
+func HoldDatabase(ctx context.Context, dbFile string) (func() error, error) {
+	for {
+		f, err := os.CreateTemp(filepath.Dir(dbFile), filepath.Base(dbFile)+holdSuffix+"*")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrLocked, err)
+		}
+		file := f.Name()
+		_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(file)
+			return nil, fmt.Errorf("%w: %w", ErrLocked, err)
+		}
+		// A restore takes its lock before looking for holders, so either
+		// the restore finds this hold or the hold finds the restore
+		if !isHeld(restoreLock(dbFile)) {
+			return refreshLock(file), nil
+		}
+
+		os.Remove(file)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s restored by process %s", ErrLocked, dbFile, lockHolder(restoreLock(dbFile)))
+		case <-time.After(lockPoll):
+		}
+	}
+}
+
+// lockRestore locks the database file for a restore. It refuses with
+// ErrLocked rather than waits while another process holds the database or
+// restores it.
+func lockRestore(dbFile string) (func() error, error) {
+	noWait, cancel := context.WithCancel(context.Background())
+	cancel()
+	release, err := LockFile(noWait, restoreLock(dbFile))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(dbFile)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%w: %w", ErrLocked, err)
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), filepath.Base(dbFile)+holdSuffix) {
+			continue
+		}
+		file := filepath.Join(dir, e.Name())
+		if !isHeld(file) {
+			os.Remove(file)
+			continue
+		}
+		release()
+		return nil, fmt.Errorf("%w: %s held by process %s", ErrLocked, dbFile, lockHolder(file))
+	}
+	return release, nil
+}
+
+// restoreLock returns the lock file of restores of the database file
+func restoreLock(dbFile string) string {
+	return dbFile + ".restore.lock"
+}
+
+// isHeld reports whether the lock file exists and is not stale
+func isHeld(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && time.Since(info.ModTime()) <= staleLockAge
+}
+
+// refreshLock refreshes the lock file until the returned function removes it
+func refreshLock(file string) func() error {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(staleLockAge / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case t := <-ticker.C:
+				os.Chtimes(file, t, t)
+			}
+		}
+	}()
+	return sync.OnceValue(func() error {
+		close(done)
+		return os.Remove(file)
+	})
+}
+
+// lockHolder returns the process id written in the lock file
+func lockHolder(file string) string {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,89 @@
+package sqlops_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lottery.db.lock")
+
+	release, err := sqlops.LockFile(context.TODO(), file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := sqlops.LockFile(ctx, file); !errors.Is(err, sqlops.ErrLocked) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrLocked, err)
+	}
+
+	assert.NoError(t, release())
+	release, err = sqlops.LockFile(context.TODO(), file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.NoError(t, release())
+}
+
+func TestLockFileStale(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lottery.db.lock")
+	if err := os.WriteFile(file, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	release, err := sqlops.LockFile(ctx, file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.NoError(t, release())
+}
+
+func TestHoldDatabase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lottery.db")
+
+	// A server and an import hold the database at the same time
+	serve, err := sqlops.HoldDatabase(context.TODO(), file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	write, err := sqlops.HoldDatabase(context.TODO(), file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.NoError(t, serve())
+	assert.NoError(t, write())
+	assert.NoError(t, write())
+
+	// A restore in progress
+	restore, err := sqlops.LockFile(context.TODO(), file+".restore.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := sqlops.HoldDatabase(ctx, file); !errors.Is(err, sqlops.ErrLocked) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrLocked, err)
+	}
+	assert.NoError(t, restore())
+
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+}
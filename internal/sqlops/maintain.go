@@ -0,0 +1,127 @@
+package sqlops
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Exec executes a statement that returns no rows, on the write queue of db
+// if it has one, and returns the number of rows affected
+func Exec(ctx context.Context, db DB, rawStmt string, args ...any) (_ int64, err error) {
+	defer func(start time.Time) { observe(db, "exec", rawStmt, start, err) }(time.Now())
+	stmt, release, err := prepare(ctx, db, rawStmt)
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrExecuteWriter, err)
+	}
+	defer release()
+
+	var n int64
+	err = write(ctx, db, func() error {
+		result, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrExecuteWriter, err)
+		}
+		if n, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("%w:%w", ErrExecuteWriter, err)
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Backup writes a consistent snapshot of the database to the file with
+// VACUUM INTO, while the database remains available to other connections.
+// The file must not already exist.
+func Backup(ctx context.Context, db *sql.DB, file string) error {
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%w: %s already exists", ErrBackup, file)
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", file); err != nil {
+		return fmt.Errorf("%w: %w", ErrBackup, err)
+	}
+	return nil
+}
+
+// Restore replaces the database file with a copy of the backup, after
+// checking the integrity of the backup. The database file must not be open
+// while it is restored, so Restore refuses with ErrLocked while a process
+// holds it with HoldDatabase.
+func Restore(ctx context.Context, backup string, dbFile string) error {
+	release, err := lockRestore(dbFile)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+	defer release()
+
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+	// The backup is opened without the pragmas of NewSQLiteFile, which would
+	// switch it to write-ahead logging
+	src, err := sql.Open("sqlite", fileDSN(backup, nil))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+	defer src.Close()
+	if _, err := IntegrityCheck(ctx, src); err != nil {
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+
+	tmp := dbFile + ".restore"
+	os.Remove(tmp)
+	if _, err := src.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+	// Journals of the replaced database must not be applied to the restored one
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		if err := os.Remove(dbFile + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return fmt.Errorf("%w: %w", ErrRestore, err)
+		}
+	}
+	if err := os.Rename(tmp, dbFile); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%w: %w", ErrRestore, err)
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database to reclaim unused space
+func Vacuum(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("%w: %w", ErrVacuum, err)
+	}
+	return nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns its messages, which
+// are only "ok" for a sound database. Other messages are returned with
+// ErrIntegrity.
+func IntegrityCheck(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntegrity, err)
+	}
+	defer rows.Close()
+
+	msgs := []string{}
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrIntegrity, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntegrity, err)
+	}
+	if !slices.Equal(msgs, []string{"ok"}) {
+		return msgs, ErrIntegrity
+	}
+	return msgs, nil
+}
@@ -0,0 +1,156 @@
+package sqlops_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFileDB returns a database file in a temporary directory with a table
+// of n rows
+func newFileDB(t *testing.T, n int) (*sql.DB, string) {
+	file := filepath.Join(t.TempDir(), "lottery.db")
+	db, err := sqlops.NewSQLiteFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE TABLE draw(id INTEGER PRIMARY KEY, ball1 INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		if _, err := db.Exec("INSERT INTO draw (ball1) VALUES (?)", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, file
+}
+
+func countRows(t *testing.T, db *sql.DB) int {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM draw").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestExec(t *testing.T) {
+	db, _ := newFileDB(t, 3)
+	n, err := sqlops.Exec(context.TODO(), db, "DELETE FROM draw WHERE ball1 > ?", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	_, err = sqlops.Exec(context.TODO(), db, "DELETE FROM missing")
+	assert.ErrorIs(t, err, sqlops.ErrExecuteWriter)
+}
+
+func TestBackupRestore(t *testing.T) {
+	db, file := newFileDB(t, 3)
+	backup := filepath.Join(t.TempDir(), "backup.db")
+
+	if err := sqlops.Backup(context.TODO(), db, backup); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	if err := sqlops.Backup(context.TODO(), db, backup); !errors.Is(err, sqlops.ErrBackup) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrBackup, err)
+	}
+
+	if _, err := db.Exec("DELETE FROM draw"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if err := sqlops.Restore(context.TODO(), backup, file); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	restored, err := sqlops.NewSQLiteFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	assert.Equal(t, 3, countRows(t, restored))
+	_, err = os.Stat(file + ".restore")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRestoreLocked(t *testing.T) {
+	db, file := newFileDB(t, 3)
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	if err := sqlops.Backup(context.TODO(), db, backup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DELETE FROM draw"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A server or import holding the database
+	release, err := sqlops.HoldDatabase(context.TODO(), file)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	err = sqlops.Restore(context.TODO(), backup, file)
+	if !errors.Is(err, sqlops.ErrRestore) || !errors.Is(err, sqlops.ErrLocked) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrLocked, err)
+	}
+	assert.Equal(t, 0, countRows(t, db))
+
+	db.Close()
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlops.Restore(context.TODO(), backup, file); err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	dir := t.TempDir()
+	notDB := filepath.Join(dir, "backup.db")
+	if err := os.WriteFile(notDB, []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name   string
+		backup string
+	}{
+		{name: "missing backup", backup: filepath.Join(dir, "missing.db")},
+		{name: "not a database", backup: notDB},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sqlops.Restore(context.TODO(), tc.backup, filepath.Join(dir, "lottery.db"))
+			if !errors.Is(err, sqlops.ErrRestore) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrRestore, err)
+			}
+		})
+	}
+}
+
+func TestVacuumIntegrity(t *testing.T) {
+	db, _ := newFileDB(t, 100)
+	if _, err := db.Exec("DELETE FROM draw"); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, sqlops.Vacuum(context.TODO(), db))
+
+	msgs, err := sqlops.IntegrityCheck(context.TODO(), db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ok"}, msgs)
+}
+
+func TestIsConstraint(t *testing.T) {
+	db, _ := newFileDB(t, 1)
+	_, err := sqlops.Exec(context.TODO(), db, "INSERT INTO draw (id, ball1) VALUES (1, 0)")
+	assert.True(t, sqlops.IsConstraint(err))
+
+	_, err = sqlops.Exec(context.TODO(), db, "DELETE FROM missing")
+	assert.False(t, sqlops.IsConstraint(err))
+	assert.False(t, sqlops.IsConstraint(nil))
+}
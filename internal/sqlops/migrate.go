@@ -0,0 +1,222 @@
+package sqlops
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Latest is the version to migrate every schema to its last migration
+const Latest = -1
+
+const (
+	createMigrationsSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+        name TEXT NOT NULL, version INTEGER NOT NULL, description TEXT NOT NULL, applied_at TEXT NOT NULL,
+        PRIMARY KEY (name, version))`
+	countMigrationSQL   = `SELECT COUNT(*) FROM schema_migrations WHERE name = $1 AND version = $2`
+	insertMigrationSQL  = `INSERT INTO schema_migrations (name, version, description, applied_at) VALUES ($1, $2, $3, $4)`
+	selectMigrationsSQL = `SELECT name, version, applied_at FROM schema_migrations`
+)
+
+// Migration is a versioned change to a schema
+type Migration struct {
+	Version     int
+	Description string
+	Up          TblCreator
+}
+
+// Schema is a named set of tables, such as the tables of a game, changed by
+// its migrations in order of version. Versions start at 1 and increase by 1.
+type Schema struct {
+	Name       string
+	Migrations []Migration
+}
+
+// MigrationStatus reports a migration of a schema and when it was applied
+type MigrationStatus struct {
+	Schema      string    `json:"schema"`
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at,omitzero"`
+}
+
+// Applied reports whether the migration has been applied
+func (m MigrationStatus) Applied() bool {
+	return !m.AppliedAt.IsZero()
+}
+
+// validate checks that the versions of the migrations are 1, 2, 3 and so on
+func (s Schema) validate() error {
+	for i, m := range s.Migrations {
+		if m.Version != i+1 {
+			return fmt.Errorf("%w: %s migration %d has version %d", ErrMigrationVersion, s.Name, i+1, m.Version)
+		}
+	}
+	return nil
+}
+
+// LockMigrations locks the migrations of the database file against other
+// processes, waiting until ctx is done for a lock held by another process.
+// The returned function releases the lock.
+func LockMigrations(ctx context.Context, dbFile string) (func() error, error) {
+	return LockFile(ctx, dbFile+".migrate.lock")
+}
+
+// Migrate applies the migrations of every schema not yet applied, up to and
+// including version to, or every migration if to is Latest. Each migration
+// is applied and recorded in schema_migrations in its own transaction.
+// Migrations are up only, so schemas beyond version to are left unchanged.
+// The migrations applied are returned.
+func Migrate(ctx context.Context, db *sql.DB, to int, schemas ...Schema) ([]MigrationStatus, error) {
+	if to != Latest && to < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrMigrationVersion, to)
+	}
+	if _, err := db.ExecContext(ctx, createMigrationsSQL); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMigration, err)
+	}
+
+	applied := []MigrationStatus{}
+	for _, s := range schemas {
+		if err := s.validate(); err != nil {
+			return applied, err
+		}
+		for _, m := range s.Migrations {
+			if to != Latest && m.Version > to {
+				break
+			}
+			status, ok, err := applyMigration(ctx, db, s.Name, m)
+			if err != nil {
+				return applied, err
+			}
+			if ok {
+				applied = append(applied, status)
+			}
+		}
+	}
+	return applied, nil
+}
+
+// applyMigration applies the migration unless it has been applied, and
+// reports whether it was applied
+func applyMigration(ctx context.Context, db *sql.DB, name string, m Migration) (MigrationStatus, bool, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelDefault,
+	})
+	if err != nil {
+		return MigrationStatus{}, false, fmt.Errorf("%w: %w", ErrCreateTxn, err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	var n int
+	if err := tx.QueryRowContext(ctx, countMigrationSQL, name, m.Version).Scan(&n); err != nil {
+		return MigrationStatus{}, false, fmt.Errorf("%w: %w", ErrMigration, err)
+	}
+	if n > 0 {
+		return MigrationStatus{}, false, nil
+	}
+
+	if err := m.Up.Create(ctx, tx); err != nil {
+		return MigrationStatus{}, false, fmt.Errorf("%w: %s version %d: %w", ErrMigration, name, m.Version, err)
+	}
+	status := MigrationStatus{Schema: name, Version: m.Version, Description: m.Description, AppliedAt: time.Now().UTC().Truncate(time.Second)}
+	if _, err := tx.ExecContext(ctx, insertMigrationSQL, name, m.Version, m.Description, status.AppliedAt.Format(time.RFC3339)); err != nil {
+		return MigrationStatus{}, false, fmt.Errorf("%w: %s version %d: %w", ErrMigration, name, m.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return MigrationStatus{}, false, fmt.Errorf("%w: %s version %d: %w", ErrMigration, name, m.Version, err)
+	}
+	committed = true
+	return status, true, nil
+}
+
+// MigrationStatuses returns every migration of the schemas, with the time
+// it was applied if it has been
+func MigrationStatuses(ctx context.Context, db *sql.DB, schemas ...Schema) ([]MigrationStatus, error) {
+	if _, err := db.ExecContext(ctx, createMigrationsSQL); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMigration, err)
+	}
+	rows, err := db.QueryContext(ctx, selectMigrationsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	type key struct {
+		name    string
+		version int
+	}
+	appliedAt := map[key]time.Time{}
+	for rows.Next() {
+		var k key
+		var at string
+		if err := rows.Scan(&k.name, &k.version, &at); err != nil {
+			return nil, fmt.Errorf("%w:%w", ErrExecuteQuery, err)
+		}
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s version %d applied at %q", ErrMigration, k.name, k.version, at)
+		}
+		appliedAt[k] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrExecuteQuery, err)
+	}
+
+	statuses := []MigrationStatus{}
+	for _, s := range schemas {
+		for _, m := range s.Migrations {
+			statuses = append(statuses, MigrationStatus{
+				Schema:      s.Name,
+				Version:     m.Version,
+				Description: m.Description,
+				AppliedAt:   appliedAt[key{s.Name, m.Version}],
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// RebuildTable replaces the table tbl with a table of the same name created
+// by createSQL, a CREATE TABLE statement with a %s verb for the table name,
+// as SQLite cannot add constraints to an existing table. The rows of tbl are
+// copied into the columns of the new table from the values, expressions of
+// the columns of tbl. Rows failing the constraints of the new table are kept
+// in the table tbl_invalid, and their number is returned.
+func RebuildTable(ctx context.Context, tx *sql.Tx, tbl, createSQL, columns, values string) (int64, error) {
+	rebuilt := tbl + "_rebuild"
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(createSQL, rebuilt)); err != nil {
+		return 0, err
+	}
+	copySQL := fmt.Sprintf(`INSERT OR IGNORE INTO %s (%s) SELECT %s FROM %s`, rebuilt, columns, values, tbl)
+	if _, err := tx.ExecContext(ctx, copySQL); err != nil {
+		return 0, err
+	}
+
+	var invalid int64
+	countSQL := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE rowid NOT IN (SELECT rowid FROM %s)`, tbl, rebuilt)
+	if err := tx.QueryRowContext(ctx, countSQL).Scan(&invalid); err != nil {
+		return 0, err
+	}
+	if invalid > 0 {
+		keepSQL := fmt.Sprintf(`CREATE TABLE %[1]s_invalid AS SELECT * FROM %[1]s WHERE rowid NOT IN (SELECT rowid FROM %[2]s)`, tbl, rebuilt)
+		if _, err := tx.ExecContext(ctx, keepSQL); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, stmt := range []string{
+		fmt.Sprintf(`DROP TABLE %s`, tbl),
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, rebuilt, tbl),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return 0, err
+		}
+	}
+	return invalid, nil
+}
@@ -0,0 +1,173 @@
+package sqlops_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+// execUp returns a migration step executing the statement
+func execUp(stmt string) sqlops.TblCreator {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt)
+		return err
+	}
+}
+
+var testSchema = sqlops.Schema{
+	Name: "draw",
+	Migrations: []sqlops.Migration{
+		{Version: 1, Description: "create draw table", Up: execUp("CREATE TABLE IF NOT EXISTS draw(id INTEGER PRIMARY KEY, ball1 INTEGER)")},
+		{Version: 2, Description: "add ball2", Up: execUp("ALTER TABLE draw ADD COLUMN ball2 INTEGER")},
+		{Version: 3, Description: "index ball1", Up: execUp("CREATE INDEX draw_ball1 ON draw(ball1)")},
+	},
+}
+
+func newMigrateDB(t *testing.T) *sql.DB {
+	db, err := sqlops.NewSQLiteFile(filepath.Join(t.TempDir(), "lottery.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// appliedVersions returns the versions of the applied migrations
+func appliedVersions(t *testing.T, db *sql.DB, schemas ...sqlops.Schema) []int {
+	statuses, err := sqlops.MigrationStatuses(context.TODO(), db, schemas...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []int{}
+	for _, s := range statuses {
+		if s.Applied() {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.TODO()
+	db := newMigrateDB(t)
+
+	assert.Equal(t, []int{}, appliedVersions(t, db, testSchema))
+
+	applied, err := sqlops.Migrate(ctx, db, 2, testSchema)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []int{1, 2}, appliedVersions(t, db, testSchema))
+	if _, err := db.Exec("INSERT INTO draw (ball1, ball2) VALUES (1, 2)"); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err = sqlops.Migrate(ctx, db, sqlops.Latest, testSchema)
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	assert.Len(t, applied, 1)
+	assert.Equal(t, "index ball1", applied[0].Description)
+	assert.True(t, applied[0].Applied())
+
+	// Migrations are up only and applied once
+	applied, err = sqlops.Migrate(ctx, db, 1, testSchema)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	applied, err = sqlops.Migrate(ctx, db, sqlops.Latest, testSchema)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, []int{1, 2, 3}, appliedVersions(t, db, testSchema))
+}
+
+func TestMigrateFailure(t *testing.T) {
+	ctx := context.TODO()
+	db := newMigrateDB(t)
+
+	failing := sqlops.Schema{
+		Name: "draw",
+		Migrations: []sqlops.Migration{
+			testSchema.Migrations[0],
+			{Version: 2, Description: "create and fail", Up: func(ctx context.Context, tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, "CREATE TABLE partial(id INTEGER)"); err != nil {
+					return err
+				}
+				return errors.New("failed")
+			}},
+		},
+	}
+	_, err := sqlops.Migrate(ctx, db, sqlops.Latest, failing)
+	if !errors.Is(err, sqlops.ErrMigration) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrMigration, err)
+	}
+	// The failed migration is rolled back and not recorded
+	assert.Equal(t, []int{1}, appliedVersions(t, db, failing))
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_schema WHERE name = 'partial'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, n)
+}
+
+func TestMigrateVersion(t *testing.T) {
+	db := newMigrateDB(t)
+	testcases := []struct {
+		name   string
+		to     int
+		schema sqlops.Schema
+	}{
+		{name: "invalid target", to: 0, schema: testSchema},
+		{name: "versions out of order", to: sqlops.Latest, schema: sqlops.Schema{Name: "draw", Migrations: []sqlops.Migration{testSchema.Migrations[1], testSchema.Migrations[0]}}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sqlops.Migrate(context.TODO(), db, tc.to, tc.schema)
+			if !errors.Is(err, sqlops.ErrMigrationVersion) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrMigrationVersion, err)
+			}
+		})
+	}
+}
+
+func TestRebuildTable(t *testing.T) {
+	ctx := context.TODO()
+	db := newMigrateDB(t)
+	if _, err := db.ExecContext(ctx, `CREATE TABLE draw(id INTEGER PRIMARY KEY, draw_date TEXT, ball1 INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO draw VALUES (1, '2026-02-17 00:00:00 +0000 UTC', 5), (2, '2026-02-18 00:00:00 +0000 UTC', 60)`); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	createSQL := `CREATE TABLE %s (id INTEGER PRIMARY KEY, draw_date TEXT NOT NULL, ball1 INTEGER CHECK (ball1 BETWEEN 1 AND 59))`
+	invalid, err := sqlops.RebuildTable(ctx, tx, "draw", createSQL, "id, draw_date, ball1", "id, substr(draw_date, 1, 10), ball1")
+	if err != nil {
+		tx.Rollback()
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), invalid)
+
+	var date string
+	if err := db.QueryRowContext(ctx, `SELECT draw_date FROM draw`).Scan(&date); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2026-02-17", date)
+	var ball int
+	if err := db.QueryRowContext(ctx, `SELECT ball1 FROM draw_invalid`).Scan(&ball); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 60, ball)
+}
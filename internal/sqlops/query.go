@@ -0,0 +1,277 @@
+package sqlops
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DB is a database that statements are prepared on, either a *sql.DB, which
+// prepares statements for every call, or a *StmtCache, which prepares each
+// statement once
+type DB interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// StmtCache prepares each statement once and reuses it for every later call
+// with the same SQL. Writer and Exec run their writes on its queue, if any.
+// It is safe for concurrent use.
+type StmtCache struct {
+	db       *sql.DB
+	queue    *WriteQueue
+	observer Observer
+	mu       sync.Mutex
+	stmts    map[string]*sql.Stmt
+}
+
+// Observer is called with the operation, SQL, duration and error of every
+// query, write and exec run on a StmtCache. The operation is "query",
+// "write" or "exec".
+type Observer func(op, query string, took time.Duration, err error)
+
+// CacheOption configures a StmtCache
+type CacheOption func(*StmtCache)
+
+// WithObserver sets the observer of the statements run on the cache
+func WithObserver(o Observer) CacheOption {
+	return func(c *StmtCache) {
+		c.observer = o
+	}
+}
+
+// NewStmtCache returns an empty cache of statements prepared on db, whose
+// writes are run on the queue unless it is nil. The queue is not closed by
+// the cache.
+func NewStmtCache(db *sql.DB, queue *WriteQueue, opts ...CacheOption) *StmtCache {
+	c := &StmtCache{db: db, queue: queue, stmts: map[string]*sql.Stmt{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PrepareContext returns the statement prepared for the query, preparing it
+// if it is not cached. The statement is owned by the cache and must not be
+// closed by the caller.
+func (c *StmtCache) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// BeginTx starts a transaction on the database of the cache
+func (c *StmtCache) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.db.BeginTx(ctx, opts)
+}
+
+// Close closes every cached statement and empties the cache
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrCloseStmt, err))
+		}
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// prepare returns the statement for the query and a function to release it,
+// which closes statements not owned by a cache
+func prepare(ctx context.Context, db DB, query string) (*sql.Stmt, func(), error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w:%w", ErrPrepareStmt, err)
+	}
+	if _, ok := db.(*StmtCache); ok {
+		return stmt, func() {}, nil
+	}
+	return stmt, func() { stmt.Close() }, nil
+}
+
+// observe reports the statement started at start to the observer of db, if
+// it is a *StmtCache with one
+func observe(db DB, op, query string, start time.Time, err error) {
+	if c, ok := db.(*StmtCache); ok && c.observer != nil {
+		c.observer(op, query, time.Since(start), err)
+	}
+}
+
+// tableName matches the table a statement reads or writes
+var tableName = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// TableOf returns the first table named by the SQL, or "" if it names none
+func TableOf(query string) string {
+	if m := tableName.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// RowWriter is a function type to support callback to write a row of data
+type RowWriter[T any] func(context.Context, *sql.Stmt, T) error
+
+// Writer writes every item of dataList with the statement in one
+// transaction, run on the write queue of db if it has one. Items failing to
+// be written are skipped, and their errors are returned joined once the
+// other items are committed.
+func Writer[T any](ctx context.Context, db DB, rawStmt string, dataList []T, rowWriter RowWriter[T]) (err error) {
+	if len(dataList) == 0 {
+		return nil
+	}
+	defer func(start time.Time) { observe(db, "write", rawStmt, start, err) }(time.Now())
+
+	stmt, release, err := prepare(ctx, db, rawStmt)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return write(ctx, db, func() error {
+		tx, err := db.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelDefault,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCreateTxn, err)
+		}
+		txStmt := tx.StmtContext(ctx, stmt)
+		defer txStmt.Close()
+
+		var errs []error
+		for _, data := range dataList {
+			if err := rowWriter(ctx, txStmt, data); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("%w:%w", ErrExecuteWriter, err)
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// QueryScanner is a function type to support callback to read a row of data
+type QueryScanner[T any] func(*sql.Rows) (T, error)
+
+// QuerySeq runs the query and yields each row read by the scanner as it is
+// read. Iteration stops after the first error yielded, which is an error of
+// the query, the scanner or the context. The query is observed once
+// iteration stops.
+func QuerySeq[T any](ctx context.Context, db DB, scanner QueryScanner[T], rawQuery string, args ...any) iter.Seq2[T, error] {
+	return func(yieldRow func(T, error) bool) {
+		var zero T
+		var failed error
+		defer func(start time.Time) { observe(db, "query", rawQuery, start, failed) }(time.Now())
+		yield := func(item T, err error) bool {
+			failed = err
+			return yieldRow(item, err)
+		}
+		stmt, release, err := prepare(ctx, db, rawQuery)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer release()
+
+		rows, err := stmt.QueryContext(ctx, args...)
+		if err != nil {
+			yield(zero, fmt.Errorf("%w:%w", ErrExecuteQuery, err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(zero, fmt.Errorf("%w:%w", ErrExecuteQuery, err))
+				return
+			}
+			item, err := scanner(rows)
+			if err != nil {
+				yield(zero, fmt.Errorf("%w:%w", ErrScanRow, err))
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, fmt.Errorf("%w:%w", ErrExecuteQuery, err))
+		}
+	}
+}
+
+// Query runs the query and returns every row read by the scanner, or the
+// first error of the query or the scanner
+func Query[T any](ctx context.Context, db DB, scanner QueryScanner[T], rawQuery string, args ...any) ([]T, error) {
+	results := []T{}
+	for item, err := range QuerySeq(ctx, db, scanner, rawQuery, args...) {
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// QueryOne runs the query and returns the first row read by the scanner. It
+// returns an error wrapping sql.ErrNoRows if the query returns no rows.
+func QueryOne[T any](ctx context.Context, db DB, scanner QueryScanner[T], rawQuery string, args ...any) (T, error) {
+	for item, err := range QuerySeq(ctx, db, scanner, rawQuery, args...) {
+		return item, err
+	}
+	var zero T
+	return zero, fmt.Errorf("%w:%w", ErrExecuteQuery, sql.ErrNoRows)
+}
+
+// QueryCollect runs the query and returns the rows read by the scanner. Rows
+// the scanner fails to read are skipped, and their errors are returned
+// joined with the rows read. Other errors stop the query.
+func QueryCollect[T any](ctx context.Context, db DB, scanner QueryScanner[T], rawQuery string, args ...any) (_ []T, err error) {
+	defer func(start time.Time) { observe(db, "query", rawQuery, start, err) }(time.Now())
+	stmt, release, err := prepare(ctx, db, rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	results := []T{}
+	var errs []error
+	for row := 1; rows.Next(); row++ {
+		if err := ctx.Err(); err != nil {
+			return results, errors.Join(append(errs, fmt.Errorf("%w:%w", ErrExecuteQuery, err))...)
+		}
+		item, err := scanner(rows)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: row %d: %w", ErrScanRow, row, err))
+			continue
+		}
+		results = append(results, item)
+	}
+	if err := rows.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%w:%w", ErrExecuteQuery, err))
+	}
+	return results, errors.Join(errs...)
+}
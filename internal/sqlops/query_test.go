@@ -0,0 +1,191 @@
+package sqlops_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func scanBall(rows *sql.Rows) (int, error) {
+	var ball int
+	err := rows.Scan(&ball)
+	return ball, err
+}
+
+// scanEvenBall reads a ball, failing on odd balls
+func scanEvenBall(rows *sql.Rows) (int, error) {
+	ball, err := scanBall(rows)
+	if err == nil && ball%2 == 1 {
+		return 0, errors.New("odd ball")
+	}
+	return ball, err
+}
+
+func TestQuery(t *testing.T) {
+	db, _ := newFileDB(t, 4)
+	cache := sqlops.NewStmtCache(db, nil)
+	t.Cleanup(func() { cache.Close() })
+
+	testcases := []struct {
+		name    string
+		db      sqlops.DB
+		scanner sqlops.QueryScanner[int]
+		query   string
+		want    []int
+		wantErr error
+	}{
+		{name: "database", db: db, scanner: scanBall, query: "SELECT ball1 FROM draw ORDER BY id", want: []int{0, 1, 2, 3}},
+		{name: "cache", db: cache, scanner: scanBall, query: "SELECT ball1 FROM draw ORDER BY id", want: []int{0, 1, 2, 3}},
+		{name: "no rows", db: cache, scanner: scanBall, query: "SELECT ball1 FROM draw WHERE ball1 > 9", want: []int{}},
+		{name: "scanner error", db: cache, scanner: scanEvenBall, query: "SELECT ball1 FROM draw ORDER BY id", wantErr: sqlops.ErrScanRow},
+		{name: "invalid query", db: cache, scanner: scanBall, query: "SELECT ball1 FROM missing", wantErr: sqlops.ErrPrepareStmt},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sqlops.Query(context.TODO(), tc.db, tc.scanner, tc.query)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	db, _ := newFileDB(t, 4)
+
+	got, err := sqlops.QueryOne(context.TODO(), db, scanBall, "SELECT COUNT(*) FROM draw WHERE ball1 > $1", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got)
+
+	_, err = sqlops.QueryOne(context.TODO(), db, scanBall, "SELECT ball1 FROM draw WHERE ball1 > 9")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sql.ErrNoRows, err)
+	}
+}
+
+func TestQueryCollect(t *testing.T) {
+	db, _ := newFileDB(t, 4)
+
+	got, err := sqlops.QueryCollect(context.TODO(), db, scanEvenBall, "SELECT ball1 FROM draw ORDER BY id")
+	assert.Equal(t, []int{0, 2}, got)
+	if !errors.Is(err, sqlops.ErrScanRow) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrScanRow, err)
+	}
+	assert.ErrorContains(t, err, "row 2")
+	assert.ErrorContains(t, err, "row 4")
+}
+
+func TestQuerySeq(t *testing.T) {
+	db, _ := newFileDB(t, 4)
+
+	t.Run("stop early", func(t *testing.T) {
+		got := []int{}
+		for ball, err := range sqlops.QuerySeq(context.TODO(), db, scanBall, "SELECT ball1 FROM draw ORDER BY id") {
+			if err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			got = append(got, ball)
+			if len(got) == 2 {
+				break
+			}
+		}
+		assert.Equal(t, []int{0, 1}, got)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		var gotErr error
+		for _, err := range sqlops.QuerySeq(ctx, db, scanBall, "SELECT ball1 FROM draw ORDER BY id") {
+			cancel()
+			gotErr = err
+		}
+		if !errors.Is(gotErr, context.Canceled) {
+			t.Fatalf("Unmatch error. Want: %v Got: %v", context.Canceled, gotErr)
+		}
+	})
+}
+
+func TestStmtCache(t *testing.T) {
+	db, _ := newFileDB(t, 0)
+	cache := sqlops.NewStmtCache(db, nil)
+
+	stmt1, err := cache.PrepareContext(context.TODO(), "SELECT ball1 FROM draw")
+	assert.NoError(t, err)
+	stmt2, err := cache.PrepareContext(context.TODO(), "SELECT ball1 FROM draw")
+	assert.NoError(t, err)
+	assert.Same(t, stmt1, stmt2)
+
+	err = sqlops.Writer(context.TODO(), cache, "INSERT INTO draw (ball1) VALUES ($1)", []int{1, 2}, func(ctx context.Context, stmt *sql.Stmt, ball int) error {
+		_, err := stmt.ExecContext(ctx, ball)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, db))
+
+	assert.NoError(t, cache.Close())
+	stmt3, err := cache.PrepareContext(context.TODO(), "SELECT ball1 FROM draw")
+	assert.NoError(t, err)
+	assert.NotSame(t, stmt1, stmt3)
+}
+
+func TestStmtCacheObserver(t *testing.T) {
+	db, _ := newFileDB(t, 4)
+	type observed struct {
+		op    string
+		table string
+		err   bool
+	}
+	var got []observed
+	cache := sqlops.NewStmtCache(db, nil, sqlops.WithObserver(func(op, query string, took time.Duration, err error) {
+		got = append(got, observed{op: op, table: sqlops.TableOf(query), err: err != nil})
+	}))
+	t.Cleanup(func() { cache.Close() })
+
+	sqlops.Query(context.TODO(), cache, scanBall, "SELECT ball1 FROM draw")
+	sqlops.QueryOne(context.TODO(), cache, scanBall, "SELECT ball1 FROM draw")
+	sqlops.QueryCollect(context.TODO(), cache, scanEvenBall, "SELECT ball1 FROM draw")
+	sqlops.Writer(context.TODO(), cache, "INSERT INTO draw (ball1) VALUES ($1)", []int{1}, func(ctx context.Context, stmt *sql.Stmt, ball int) error {
+		_, err := stmt.ExecContext(ctx, ball)
+		return err
+	})
+	sqlops.Exec(context.TODO(), cache, "UPDATE draw SET ball1 = 0")
+	sqlops.Query(context.TODO(), cache, scanBall, "SELECT ball1 FROM missing")
+	sqlops.Query(context.TODO(), db, scanBall, "SELECT ball1 FROM draw")
+
+	want := []observed{
+		{op: "query", table: "draw"},
+		{op: "query", table: "draw"},
+		{op: "query", table: "draw", err: true},
+		{op: "write", table: "draw"},
+		{op: "exec", table: "draw"},
+		{op: "query", table: "missing", err: true},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestTableOf(t *testing.T) {
+	testcases := []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT * FROM tball WHERE draw_no = $1", want: "tball"},
+		{query: "insert into euro (ball1) values ($1)", want: "euro"},
+		{query: "UPDATE lotto SET ball1 = $1", want: "lotto"},
+		{query: "DELETE FROM set_for_life", want: "set_for_life"},
+		{query: "PRAGMA integrity_check", want: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			assert.Equal(t, tc.want, sqlops.TableOf(tc.query))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package sqlops
+
+import (
+	"context"
+	"sync"
+)
+
+// WriteQueue runs writes to a database one at a time on a single goroutine,
+// so that concurrent writes wait in the queue rather than compete for the
+// write lock of SQLite
+type WriteQueue struct {
+	jobs chan writeJob
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type writeJob struct {
+	ctx    context.Context
+	write  func() error
+	result chan error
+}
+
+// NewWriteQueue starts a queue holding up to size writes waiting to run
+func NewWriteQueue(size int) *WriteQueue {
+	q := &WriteQueue{
+		jobs: make(chan writeJob, size),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *WriteQueue) run() {
+	defer close(q.done)
+	for {
+		select {
+		case job := <-q.jobs:
+			if err := job.ctx.Err(); err != nil {
+				job.result <- err
+				continue
+			}
+			job.result <- job.write()
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// Do queues the write and returns its error once it has run. It returns
+// ErrQueueClosed if the queue is closed before the write runs, or the error
+// of the context if it is done before the write is queued.
+func (q *WriteQueue) Do(ctx context.Context, write func() error) error {
+	job := writeJob{ctx: ctx, write: write, result: make(chan error, 1)}
+	select {
+	case q.jobs <- job:
+	case <-q.done:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-job.result:
+		return err
+	case <-q.done:
+		select {
+		case err := <-job.result:
+			return err
+		default:
+			return ErrQueueClosed
+		}
+	}
+}
+
+// Close stops the queue once the running write, if any, has finished.
+// Writes still waiting are not run.
+func (q *WriteQueue) Close() {
+	q.once.Do(func() { close(q.quit) })
+	<-q.done
+}
+
+// write runs the write on the queue of db if it is a *StmtCache with a
+// queue, or directly otherwise
+func write(ctx context.Context, db DB, w func() error) error {
+	if c, ok := db.(*StmtCache); ok && c.queue != nil {
+		return c.queue.Do(ctx, w)
+	}
+	return w()
+}
@@ -0,0 +1,71 @@
+package sqlops_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteQueue(t *testing.T) {
+	q := sqlops.NewWriteQueue(4)
+
+	var running, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := q.Do(context.TODO(), func() error {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				defer running.Add(-1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), overlaps.Load())
+
+	wantErr := errors.New("write failed")
+	err := q.Do(context.TODO(), func() error { return wantErr })
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", wantErr, err)
+	}
+
+	q.Close()
+	err = q.Do(context.TODO(), func() error { return nil })
+	if !errors.Is(err, sqlops.ErrQueueClosed) {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", sqlops.ErrQueueClosed, err)
+	}
+}
+
+func TestWriteQueueConcurrentWriters(t *testing.T) {
+	db, _ := newFileDB(t, 0)
+	q := sqlops.NewWriteQueue(8)
+	t.Cleanup(q.Close)
+	cache := sqlops.NewStmtCache(db, q)
+	t.Cleanup(func() { cache.Close() })
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sqlops.Writer(context.TODO(), cache, "INSERT INTO draw (ball1) VALUES ($1)", []int{i}, func(ctx context.Context, stmt *sql.Stmt, ball int) error {
+				_, err := stmt.ExecContext(ctx, ball)
+				return err
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, countRows(t, db))
+}
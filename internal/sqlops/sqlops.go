@@ -5,19 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 var (
 	// Errors
-	ErrCreateTbl     = errors.New("unable to create table")
-	ErrCreateTxn     = errors.New("unable to create transaction")
-	ErrCloseStmt     = errors.New("unable to close statment")
-	ErrPrepareStmt   = errors.New("prepare statement")
-	ErrExecuteQuery  = errors.New("execute query error")
-	ErrExecuteWriter = errors.New("execute write error")
-	ErrDBConn        = errors.New("connection error")
+	ErrCreateTbl        = errors.New("unable to create table")
+	ErrCreateTxn        = errors.New("unable to create transaction")
+	ErrCloseStmt        = errors.New("unable to close statment")
+	ErrPrepareStmt      = errors.New("prepare statement")
+	ErrExecuteQuery     = errors.New("execute query error")
+	ErrScanRow          = errors.New("unable to scan row")
+	ErrExecuteWriter    = errors.New("execute write error")
+	ErrDBConn           = errors.New("connection error")
+	ErrBackup           = errors.New("unable to backup database")
+	ErrRestore          = errors.New("unable to restore database")
+	ErrVacuum           = errors.New("unable to vacuum database")
+	ErrIntegrity        = errors.New("database integrity check failed")
+	ErrMigration        = errors.New("unable to migrate")
+	ErrMigrationVersion = errors.New("invalid migration version")
+	ErrQueueClosed      = errors.New("write queue closed")
+	ErrLocked           = errors.New("locked by another process")
 )
 
 // NewSQLiteMem instantiate a connection to SQLite
@@ -29,15 +42,56 @@ func NewSQLiteMem() (*sql.DB, error) {
 	return db, nil
 }
 
-// NewSQLiteFile instantiate a file based SQLite
-func NewSQLiteFile(f string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", f)
+// DefaultBusyTimeout is how long a connection waits for a lock held by
+// another connection before failing with SQLITE_BUSY
+const DefaultBusyTimeout = 5 * time.Second
+
+// Option configures the connections of a file based SQLite
+type Option func(*options)
+
+type options struct {
+	busyTimeout time.Duration
+}
+
+// WithBusyTimeout sets how long a connection waits for a lock held by
+// another connection or process
+func WithBusyTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.busyTimeout = d
+	}
+}
+
+// NewSQLiteFile instantiate a file based SQLite. Every connection uses
+// write-ahead logging, so reads are not blocked by a write, enforces foreign
+// keys, waits for locks up to the busy timeout and begins transactions
+// immediately, so a transaction waits for the write lock on start rather
+// than failing when it first writes.
+func NewSQLiteFile(f string, opts ...Option) (*sql.DB, error) {
+	o := options{busyTimeout: DefaultBusyTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", o.busyTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", fileDSN(f, params))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBConn, err)
 	}
 	return db, nil
 }
 
+// fileDSN returns the data source name of the database file with the query
+// parameters. The file is given as a file URI, escaping characters such as
+// ? and # in its path, so the path is always taken as a file name.
+func fileDSN(f string, params url.Values) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(f), RawQuery: params.Encode()}
+	return u.String()
+}
+
 // TblCreator is a function type to help create
 // db table
 type TblCreator func(context.Context, *sql.Tx) error
@@ -79,61 +133,9 @@ func CreateTables(ctx context.Context, db *sql.DB, creators ...TblCreator) error
 	return nil
 }
 
-// RowWriter is a function type to support callback to write a row of data
-type RowWriter func(context.Context, *sql.Stmt, any) error
-
-func Writer(ctx context.Context, db *sql.DB, rawStmt string, dataList []any, rowWriter RowWriter) error {
-	if len(dataList) == 0 {
-		return nil
-	}
-
-	stmt, err := db.PrepareContext(ctx, rawStmt)
-	if err != nil {
-		return fmt.Errorf("%w:%w", ErrPrepareStmt, err)
-	}
-	defer stmt.Close()
-
-	tx, err := db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelDefault,
-	})
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrCreateTxn, err)
-	}
-	for _, data := range dataList {
-		err := rowWriter(ctx, stmt, data)
-		if err != nil {
-			fmt.Println(err)
-			continue
-		}
-	}
-
-	tx.Commit()
-	return nil
-}
-
-// QueryScanner is a function type to support callback to read a row of data
-type QueryScanner func(*sql.Rows) (any, error)
-
-func Query(ctx context.Context, db *sql.DB, scanner QueryScanner, rawQuery string, args ...any) ([]any, error) {
-	stmt, err := db.PrepareContext(ctx, rawQuery)
-	if err != nil {
-		return nil, fmt.Errorf("%w:%w", ErrPrepareStmt, err)
-	}
-
-	rows, err := stmt.QueryContext(ctx, args...)
-	if err != nil {
-		return nil, fmt.Errorf("%w:%w", ErrExecuteQuery, err)
-	}
-	defer rows.Close()
-
-	var results []any
-	for rows.Next() {
-		item, err := scanner(rows)
-		if err != nil {
-			continue
-		}
-		results = append(results, item)
-	}
-
-	return results, nil
+// IsConstraint reports whether err is a constraint of a table, such as a
+// CHECK or UNIQUE constraint, refusing a row
+func IsConstraint(err error) bool {
+	var se *sqlite.Error
+	return errors.As(err, &se) && se.Code()&0xff == sqlite3.SQLITE_CONSTRAINT
 }
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 
@@ -181,11 +184,7 @@ func Example_writeTable() {
 	data := draw{
 		Ball1: 1,
 	}
-	err = sqlops.Writer(context.TODO(), db, `INSERT INTO draw (ball1) VALUES($1)`, []any{data}, func(ctx context.Context, stmt *sql.Stmt, data any) error {
-		d, ok := data.(draw)
-		if !ok {
-			return fmt.Errorf("unable to cast to appropriate type")
-		}
+	err = sqlops.Writer(context.TODO(), db, `INSERT INTO draw (ball1) VALUES($1)`, []draw{data}, func(ctx context.Context, stmt *sql.Stmt, d draw) error {
 		_, err := stmt.ExecContext(ctx, d.Ball1)
 		if err != nil {
 			return err
@@ -232,11 +231,7 @@ func Example_queryTable() {
 	data := draw{
 		Ball1: 1,
 	}
-	err = sqlops.Writer(context.TODO(), db, `INSERT INTO draw (ball1) VALUES($1)`, []any{data}, func(ctx context.Context, stmt *sql.Stmt, data any) error {
-		d, ok := data.(draw)
-		if !ok {
-			return fmt.Errorf("unable to cast to appropriate type")
-		}
+	err = sqlops.Writer(context.TODO(), db, `INSERT INTO draw (ball1) VALUES($1)`, []draw{data}, func(ctx context.Context, stmt *sql.Stmt, d draw) error {
 		_, err := stmt.ExecContext(ctx, d.Ball1)
 		if err != nil {
 			return err
@@ -247,17 +242,78 @@ func Example_queryTable() {
 		fmt.Println(err)
 	}
 
-	result, err := sqlops.Query(context.TODO(), db, func(rows *sql.Rows) (any, error) {
+	result, err := sqlops.Query(context.TODO(), db, func(rows *sql.Rows) (draw, error) {
 		d := draw{}
 		err := rows.Scan(&d.ID, &d.Ball1)
-		if err != nil {
-			return nil, err
-		}
-		return d, nil
+		return d, err
 	}, `SELECT * FROM draw`)
+	if err != nil {
+		fmt.Println(err)
+	}
 
 	fmt.Println(result)
 
 	// Output:
 	// [{1 1}]
 }
+
+func TestNewSQLiteFile(t *testing.T) {
+	db, err := sqlops.NewSQLiteFile(filepath.Join(t.TempDir(), "lottery.db"), sqlops.WithBusyTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+	}
+	defer db.Close()
+
+	testcases := []struct {
+		pragma string
+		want   string
+	}{
+		{pragma: "journal_mode", want: "wal"},
+		{pragma: "busy_timeout", want: "2000"},
+		{pragma: "foreign_keys", want: "1"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pragma, func(t *testing.T) {
+			var got string
+			if err := db.QueryRow("PRAGMA " + tc.pragma).Scan(&got); err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			if got != tc.want {
+				t.Fatalf("Unmatch %s. Want: %v Got: %v", tc.pragma, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewSQLiteFilePath(t *testing.T) {
+	testcases := []struct {
+		name string
+		file string
+	}{
+		{name: "query", file: "lottery?mode=ro.db"},
+		{name: "fragment", file: "lottery#1.db"},
+		{name: "escape", file: "lottery%3F.db"},
+		{name: "uri", file: "file:lottery.db"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tc.file)
+			db, err := sqlops.NewSQLiteFile(file)
+			if err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			defer db.Close()
+
+			var mode string
+			if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+			if mode != "wal" {
+				t.Fatalf("Unmatch journal_mode. Want: %v Got: %v", "wal", mode)
+			}
+			if _, err := os.Stat(file); err != nil {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", nil, err)
+			}
+		})
+	}
+}
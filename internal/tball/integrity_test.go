@@ -0,0 +1,131 @@
+package tball_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDraw(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   tball.Draw
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			input:   tball.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3855},
+			wantErr: nil,
+		},
+		{
+			name:    "duplicate main ball",
+			input:   tball.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 1, TBall: 3, DrawNo: 3855},
+			wantErr: tball.ErrDuplicateBall,
+		},
+		{
+			name:    "no draw on day",
+			input:   tball.Draw{DrawDate: time.Date(2026, time.February, 19, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3855},
+			wantErr: tball.ErrDrawDay,
+		},
+		{
+			name:    "main ball outside era",
+			input:   tball.Draw{DrawDate: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 39, TBall: 3, DrawNo: 30},
+			wantErr: tball.ErrBallRange,
+		},
+		{
+			name:    "thunderball outside era",
+			input:   tball.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 15, DrawNo: 3855},
+			wantErr: tball.ErrTBallRange,
+		},
+		{
+			name:    "wednesday before wednesday draws",
+			input:   tball.Draw{DrawDate: time.Date(1999, time.June, 16, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 2},
+			wantErr: tball.ErrDrawDay,
+		},
+		{
+			name:    "before first draw",
+			input:   tball.Draw{DrawDate: time.Date(1990, time.January, 6, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3855},
+			wantErr: tball.ErrNoEra,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := tball.CheckDraw(tc.input)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("Unmatch error. Want: %v Got: %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d1 := tball.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3855}
+	d2 := tball.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 2, Ball2: 5, Ball3: 6, Ball4: 9, Ball5: 12, TBall: 4, DrawNo: 3856}
+
+	t.Run("consistent draws", func(t *testing.T) {
+		assert.Empty(t, tball.Verify([]tball.Draw{d2, d1, d1}))
+	})
+
+	t.Run("repeated draw number", func(t *testing.T) {
+		conflict := d2
+		conflict.DrawNo = d1.DrawNo
+		conflict.DrawDate = d1.DrawDate
+		got := tball.Verify([]tball.Draw{d1, conflict})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, tball.ErrDrawConflict)
+	})
+
+	t.Run("draw number out of order", func(t *testing.T) {
+		late := d1
+		late.DrawNo = d2.DrawNo + 1
+		got := tball.Verify([]tball.Draw{d1, d2, late})
+		assert.Len(t, got, 1)
+		assert.ErrorIs(t, got[0].Err, tball.ErrDrawOrder)
+		assert.Equal(t, late.DrawNo, got[0].DrawNo)
+		assert.Equal(t, d2.DrawNo, got[0].Related)
+	})
+}
+
+func TestCheckImport(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	if err := sqlops.CreateTables(ctx, db, tball.CreateTableFn); err != nil {
+		t.Fatal(err)
+	}
+
+	d1 := tball.Draw{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3855}
+	if err := tball.PersistsDraw(ctx, db, d1); err != nil {
+		t.Fatal(err)
+	}
+
+	d2 := tball.Draw{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 2, Ball2: 5, Ball3: 6, Ball4: 9, Ball5: 12, TBall: 4, DrawNo: 3856}
+	conflict := d2
+	conflict.DrawNo = d1.DrawNo
+	conflict.DrawDate = d1.DrawDate
+
+	t.Run("warn", func(t *testing.T) {
+		draws, violations, err := tball.CheckImport(ctx, tball.NewSQLiteStore(db), []tball.Draw{d2, conflict}, false)
+		assert.NoError(t, err)
+		assert.Len(t, draws, 2)
+		assert.Len(t, violations, 1)
+		assert.ErrorIs(t, violations[0].Err, tball.ErrDrawConflict)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		draws, violations, err := tball.CheckImport(ctx, tball.NewSQLiteStore(db), []tball.Draw{d2, conflict}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []tball.Draw{d2}, draws)
+		assert.Len(t, violations, 1)
+	})
+}
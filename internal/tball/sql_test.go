@@ -2,10 +2,13 @@ package tball_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/paulwizviz/lotterystat/internal/drawops"
 	"github.com/paulwizviz/lotterystat/internal/sqlops"
 	"github.com/paulwizviz/lotterystat/internal/tball"
 )
@@ -47,13 +50,13 @@ func TestCalculateBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := tball.CalculateBallFreq(ctx, db)
+	freqs, err := tball.CalculateBallFreq(ctx, tball.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if len(freqs) != 38 {
-		t.Fatalf("expected 38 frequencies, got %d", len(freqs))
+	if len(freqs) != 39 {
+		t.Fatalf("expected 39 frequencies, got %d", len(freqs))
 	}
 
 	// Check specific frequencies
@@ -84,7 +87,7 @@ func TestCalculateBallFreq(t *testing.T) {
 	// Test with canceled context
 	canceledCtx, cancel := context.WithCancel(context.Background())
 	cancel()
-	_, err = tball.CalculateBallFreq(canceledCtx, db)
+	_, err = tball.CalculateBallFreq(canceledCtx, tball.NewSQLiteStore(db))
 	if err == nil {
 		t.Error("expected error for canceled context, got nil")
 	}
@@ -131,13 +134,13 @@ func TestCalculateTBallFreq(t *testing.T) {
 		}
 	}
 
-	freqs, err := tball.CalculateTBallFreq(ctx, db)
+	freqs, err := tball.CalculateTBallFreq(ctx, tball.NewSQLiteStore(db))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if len(freqs) != 13 {
-		t.Fatalf("expected 13 frequencies, got %d", len(freqs))
+	if len(freqs) != 14 {
+		t.Fatalf("expected 14 frequencies, got %d", len(freqs))
 	}
 
 	// Check specific frequencies
@@ -161,12 +164,88 @@ func TestCalculateTBallFreq(t *testing.T) {
 	// Test with canceled context
 	canceledCtx, cancel := context.WithCancel(context.Background())
 	cancel()
-	_, err = tball.CalculateTBallFreq(canceledCtx, db)
+	_, err = tball.CalculateTBallFreq(canceledCtx, tball.NewSQLiteStore(db))
 	if err == nil {
 		t.Error("expected error for canceled context, got nil")
 	}
 }
 
+func TestListDraws(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, tball.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tball.LatestDraw(ctx, tball.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+
+	draws := []tball.Draw{
+		{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 21, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := tball.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name    string
+		input   drawops.Filter
+		wantNos []uint64
+	}{
+		{name: "all draws", input: drawops.Filter{}, wantNos: []uint64{1, 2, 3}},
+		{name: "descending", input: drawops.Filter{Sort: drawops.SortByDrawNo, Desc: true}, wantNos: []uint64{3, 2, 1}},
+		{name: "last draws", input: drawops.Filter{Last: 2}, wantNos: []uint64{2, 3}},
+		{name: "date range", input: drawops.Filter{From: draws[1].DrawDate, To: draws[1].DrawDate}, wantNos: []uint64{2}},
+		{name: "from date", input: drawops.Filter{From: draws[1].DrawDate}, wantNos: []uint64{2, 3}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tball.ListDraws(ctx, db, tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotNos := []uint64{}
+			for _, d := range got {
+				gotNos = append(gotNos, d.DrawNo)
+			}
+			if !slices.Equal(tc.wantNos, gotNos) {
+				t.Fatalf("Unmatch draws. Want: %v Got: %v", tc.wantNos, gotNos)
+			}
+		})
+	}
+
+	latest, err := tball.LatestDraw(ctx, tball.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.DrawNo != 3 {
+		t.Fatalf("expected latest draw 3, got %d", latest.DrawNo)
+	}
+
+	deleted, err := tball.DeleteAllDraws(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 draws deleted, got %d", deleted)
+	}
+	if _, err := tball.LatestDraw(ctx, tball.NewSQLiteStore(db)); !errors.Is(err, drawops.ErrNoDraw) {
+		t.Fatalf("expected %v, got %v", drawops.ErrNoDraw, err)
+	}
+}
+
 func Example_insertListDraw() {
 
 	db, err := sqlops.NewSQLiteMem()
@@ -205,8 +284,8 @@ func Example_insertListDraw() {
 		Ball1:     10,
 		Ball2:     20,
 		Ball3:     30,
-		Ball4:     40,
-		Ball5:     50,
+		Ball4:     34,
+		Ball5:     39,
 		TBall:     11,
 		BallSet:   "ball set",
 		Machine:   "machine",
@@ -226,5 +305,5 @@ func Example_insertListDraw() {
 	fmt.Println(results)
 
 	// Output:
-	// [{2024-08-28 00:00:00 +0000 UTC Wednesday 1 2 3 4 5 1 ball set machine 1} {2024-08-28 00:00:00 +0000 UTC Wednesday 10 20 30 40 50 11 ball set machine 2}]
+	// [{2024-08-28 00:00:00 +0000 UTC Wednesday 1 2 3 4 5 1 ball set machine 1} {2024-08-28 00:00:00 +0000 UTC Wednesday 10 20 30 34 39 11 ball set machine 2}]
 }
@@ -0,0 +1,140 @@
+package tball
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+)
+
+type BallFrequency struct {
+	Ball      uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateBallFreq returns the frequency of every main ball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateBallFreq(ctx context.Context, store DrawStore) ([]BallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxBall(),
+		func(e Era) int { return e.MaxBall },
+		func(e Era) int { return e.BallCount })
+
+	counts, err := store.CountBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ballFreqs := []BallFrequency{}
+	for i, count := range counts {
+		ballFreqs = append(ballFreqs, BallFrequency{
+			Ball:      uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return ballFreqs, nil
+}
+
+type TBallFrequency struct {
+	TBall     uint
+	Frequency uint
+	Expected  float64
+}
+
+// CalculateTBallFreq returns the frequency of every thunderball across all
+// eras, alongside the frequency expected from the rules of each stored draw.
+func CalculateTBallFreq(ctx context.Context, store DrawStore) ([]TBallFrequency, error) {
+	drawsPerEra, err := store.CountDrawsByEra(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := expectedFreq(drawsPerEra, MaxTBall(),
+		func(e Era) int { return e.MaxTBall },
+		func(e Era) int { return e.TBallCount })
+
+	counts, err := store.CountTBalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freqs := []TBallFrequency{}
+	for i, count := range counts {
+		freqs = append(freqs, TBallFrequency{
+			TBall:     uint(i + 1),
+			Frequency: count,
+			Expected:  expected[i],
+		})
+	}
+	return freqs, nil
+}
+
+// CalculateBallGaps returns the gaps between appearances of every main ball
+// in the stored draws. A draw only counts towards the balls in its era's pool.
+func CalculateBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxBall(), mainBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxBall, era.BallCount
+	}), nil
+}
+
+// CalculateTBallGaps returns the gaps between appearances of every thunderball
+// in the stored draws. A draw only counts towards the thunderballs in its era's pool.
+func CalculateTBallGaps(ctx context.Context, store DrawStore) ([]drawops.Gap, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{Sort: drawops.SortByDrawNo})
+	if err != nil {
+		return nil, err
+	}
+	return drawops.Gaps(draws, MaxTBall(), tBalls, func(d Draw) (int, int) {
+		era, err := EraAt(d.DrawDate)
+		if err != nil {
+			return 0, 0
+		}
+		return era.MaxTBall, era.TBallCount
+	}), nil
+}
+
+// CalculateBallTrend returns the frequency of every main ball in each period
+// of the stored draws
+func CalculateBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxBall(), period, drawDateOf, mainBalls)
+}
+
+// CalculateTBallTrend returns the frequency of every thunderball in each period
+// of the stored draws
+func CalculateTBallTrend(ctx context.Context, store DrawStore, period drawops.Period) (drawops.Trend, error) {
+	draws, err := store.ListDraws(ctx, drawops.Filter{})
+	if err != nil {
+		return drawops.Trend{}, err
+	}
+	return drawops.CalculateTrend(draws, MaxTBall(), period, drawDateOf, tBalls)
+}
+
+func mainBalls(d Draw) []uint8 {
+	return []uint8{d.Ball1, d.Ball2, d.Ball3, d.Ball4, d.Ball5}
+}
+
+func tBalls(d Draw) []uint8 {
+	return []uint8{d.TBall}
+}
+
+func drawDateOf(d Draw) time.Time {
+	return d.DrawDate
+}
+
+func drawNoOf(d Draw) uint64 {
+	return d.DrawNo
+}
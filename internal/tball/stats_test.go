@@ -0,0 +1,78 @@
+package tball_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paulwizviz/lotterystat/internal/drawops"
+	"github.com/paulwizviz/lotterystat/internal/sqlops"
+	"github.com/paulwizviz/lotterystat/internal/tball"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGapsAndTrend(t *testing.T) {
+	db, err := sqlops.NewSQLiteMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.TODO()
+	err = sqlops.CreateTables(ctx, db, tball.CreateTableFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	draws := []tball.Draw{
+		{DrawDate: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 1},
+		{DrawDate: time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), Ball1: 2, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 2},
+		{DrawDate: time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC), Ball1: 1, Ball2: 3, Ball3: 4, Ball4: 8, Ball5: 11, TBall: 3, DrawNo: 3},
+	}
+	for _, d := range draws {
+		d.DayOfWeek = d.DrawDate.Weekday()
+		if err := tball.PersistsDraw(ctx, db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gaps, err := tball.CalculateBallGaps(ctx, tball.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, gaps, 39)
+	assert.Equal(t, drawops.Gap{Ball: 1, Current: 0, Longest: 1, Average: 1, Expected: gaps[0].Expected}, gaps[0])
+	assert.Equal(t, drawops.Gap{Ball: 2, Current: 1, Longest: 1, Average: 0, Expected: gaps[0].Expected}, gaps[1])
+
+	specialGaps, err := tball.CalculateTBallGaps(ctx, tball.NewSQLiteStore(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, specialGaps, 14)
+	assert.Equal(t, uint(0), specialGaps[2].Longest)
+
+	trend, err := tball.CalculateBallTrend(ctx, tball.NewSQLiteStore(db), drawops.Month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trend, err = trend.Select(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026-01", Draws: 1, Frequency: []uint{1, 0}},
+		{Period: "2026-02", Draws: 2, Frequency: []uint{1, 1}},
+	}, trend.Periods)
+
+	specialTrend, err := tball.CalculateTBallTrend(ctx, tball.NewSQLiteStore(db), drawops.Year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specialTrend, err = specialTrend.Select(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []drawops.PeriodFrequency{
+		{Period: "2026", Draws: 3, Frequency: []uint{3}},
+	}, specialTrend.Periods)
+}
@@ -51,9 +51,9 @@ const games = [
     icon: <BoltIcon />, 
     specialLabel: 'Thunderball',
     endpoints: {
-      upload: '/tball/csv',
-      ballFreq: '/tball/draw/frequency',
-      specialFreq: '/tball/tball/frequency'
+      upload: '/api/v1/tball/csv',
+      ballFreq: '/api/v1/tball/draw/frequency',
+      specialFreq: '/api/v1/tball/tball/frequency'
     }
   },
   { 
@@ -62,9 +62,9 @@ const games = [
     icon: <EuroIcon />, 
     specialLabel: 'Lucky Star',
     endpoints: {
-      upload: '/euro/csv',
-      ballFreq: '/euro/draw/frequency',
-      specialFreq: '/euro/star/frequency'
+      upload: '/api/v1/euro/csv',
+      ballFreq: '/api/v1/euro/draw/frequency',
+      specialFreq: '/api/v1/euro/star/frequency'
     }
   },
   { 
@@ -73,9 +73,9 @@ const games = [
     icon: <FavoriteIcon />, 
     specialLabel: 'Life Ball',
     endpoints: {
-      upload: '/sflife/csv',
-      ballFreq: '/sflife/draw/frequency',
-      specialFreq: '/sflife/lball/frequency'
+      upload: '/api/v1/sflife/csv',
+      ballFreq: '/api/v1/sflife/draw/frequency',
+      specialFreq: '/api/v1/sflife/lball/frequency'
     }
   },
   { 
@@ -84,13 +84,27 @@ const games = [
     icon: <CasinoIcon />, 
     specialLabel: 'Bonus Ball',
     endpoints: {
-      upload: '/lotto/csv',
-      ballFreq: '/lotto/draw/frequency',
-      specialFreq: '/lotto/bonus/frequency'
+      upload: '/api/v1/lotto/csv',
+      ballFreq: '/api/v1/lotto/draw/frequency',
+      specialFreq: '/api/v1/lotto/bonus/frequency'
     }
   }
 ];
 
+// waitForJob resolves with the job at the location once it has finished,
+// following its progress events
+const waitForJob = (location) => new Promise((resolve, reject) => {
+  const events = new EventSource(location + '/events');
+  events.addEventListener('done', (event) => {
+    events.close();
+    resolve(JSON.parse(event.data));
+  });
+  events.onerror = () => {
+    events.close();
+    reject(new Error('lost the progress of the import'));
+  };
+});
+
 function App() {
   const theme = useTheme();
   const isMobile = useMediaQuery(theme.breakpoints.down('sm'));
@@ -116,8 +130,8 @@ function App() {
         throw new Error('Failed to fetch data from server');
       }
 
-      const balls = await ballRes.json();
-      const specials = await specialRes.json();
+      const { data: balls } = await ballRes.json();
+      const { data: specials } = await specialRes.json();
       
       console.log(`Fetched ${balls.length} balls and ${specials.length} specials for ${selectedGame.name}`);
 
@@ -180,10 +194,17 @@ function App() {
       });
 
       if (response.ok) {
+        const job = await waitForJob(response.headers.get('Location'));
+        if (job.state !== 'succeeded') {
+          console.error('Upload failed:', job.error);
+        }
         handleUploadClose();
         fetchFrequencies();
+      } else if (response.status === 401) {
+        window.location.assign('/login?next=' + encodeURIComponent(window.location.pathname));
       } else {
-        console.error('Upload failed');
+        const problem = await response.json();
+        console.error('Upload failed:', problem.detail);
       }
     } catch (err) {
       console.error('Error uploading file:', err);